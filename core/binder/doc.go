@@ -1,13 +1,16 @@
 // Package binder provides comprehensive HTTP request data binding utilities for Go web applications.
-// It supports binding JSON, form data, query parameters, and path parameters to Go structs
+// It supports binding JSON, XML, NDJSON, form data, query parameters, and path parameters to Go structs
 // with built-in validation, sanitization, and security features.
 //
 // # Features
 //
 //   - JSON binding with strict parsing and size limits
+//   - XML binding with the same hardening as JSON
+//   - Streaming NDJSON binding with per-line validation
 //   - Form data binding supporting both URL-encoded and multipart forms
 //   - Query parameter binding with multi-value support
 //   - Path parameter binding compatible with popular routers
+//   - Typed handlers combining binding, sanitization and validation
 //   - Automatic input sanitization to prevent XSS and injection attacks
 //   - Comprehensive error handling with descriptive messages
 //   - Security hardening against DoS and malformed data attacks
@@ -52,6 +55,35 @@
 //		// req is now populated from JSON body
 //	}
 //
+// # XML Binding
+//
+// XML binding accepts application/xml and text/xml bodies with the same size limit,
+// trailing data check and sanitization as JSON:
+//
+//	var inv Invoice
+//	if err := binder.XML()(r, &inv); err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+//
+// # NDJSON Binding
+//
+// NDJSON binding reads newline-delimited JSON (application/x-ndjson) one line at a
+// time. Each line is decoded strictly, sanitized and validated, and errors report
+// the line number. Lines are limited to DefaultMaxJSONSize and bodies to
+// DefaultMaxNDJSONLines lines, configurable with WithMaxLineSize and WithMaxLines:
+//
+//	var users []ImportUser
+//	if err := binder.NDJSON()(r, &users); err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+//
+//	// Process large imports without holding them in memory
+//	err := binder.StreamNDJSON(r, func(line int, u ImportUser) error {
+//		return users.Create(r.Context(), u.Email)
+//	}, binder.WithMaxLines(-1))
+//
 // # Form Binding
 //
 // Form binding handles both URL-encoded forms and multipart forms with file uploads.
@@ -196,6 +228,31 @@
 //		// req is now populated from all sources
 //	}
 //
+// # Typed Handlers
+//
+// Typed adapts a function taking a request struct into a router handler. It binds
+// the body by Content-Type, then the query and path fields, sanitizes and validates
+// the request, and encodes the result as JSON:
+//
+//	type CreateUserRequest struct {
+//		OrgID string `path:"org"`
+//		Email string `json:"email" sanitize:"trim,lower" validate:"required;email"`
+//	}
+//
+//	r.Post("/orgs/{org}/users", binder.Typed(
+//		func(ctx *router.Context, req CreateUserRequest) (User, error) {
+//			return users.Create(ctx, req.OrgID, req.Email)
+//		},
+//		binder.WithStatus(http.StatusCreated),
+//	))
+//
+// Validation failures become 422 responses listing the messages of each field:
+//
+//	{"code":"unprocessable_entity","message":"Unprocessable Entity","details":{"fields":{"email":["must be a valid email address"]}}}
+//
+// With response.ProblemErrorHandler the same failures are rendered as RFC 9457
+// problem details, listing the field paths in the "errors" extension.
+//
 // # Supported Types
 //
 // The binder package supports automatic type conversion for:
@@ -213,7 +270,8 @@
 //
 // The package includes several security hardening measures:
 //
-//   - Request size limits to prevent DoS attacks (DefaultMaxJSONSize=1MB, DefaultMaxMemory=10MB)
+//   - Request size limits to prevent DoS attacks (DefaultMaxJSONSize=1MB, DefaultMaxXMLSize=1MB,
+//     DefaultMaxNDJSONLines=10000, DefaultMaxMemory=10MB)
 //   - Input sanitization to prevent XSS and injection attacks
 //   - Filename sanitization for uploaded files to prevent path traversal
 //   - Boundary validation for multipart forms
@@ -235,6 +293,10 @@
 //			// Handle unsupported media type
 //		case errors.Is(err, binder.ErrFailedToParseJSON):
 //			// Handle JSON parsing error
+//		case errors.Is(err, binder.ErrFailedToParseXML):
+//			// Handle XML parsing error
+//		case errors.Is(err, binder.ErrFailedToParseNDJSON):
+//			// Handle NDJSON parsing error
 //		case errors.Is(err, binder.ErrFailedToParseForm):
 //			// Handle form parsing error
 //		case errors.Is(err, binder.ErrFailedToParseQuery):
@@ -254,6 +316,8 @@
 //
 // The package defines the following constants:
 //
-//   - DefaultMaxJSONSize: Maximum JSON request body size (1MB)
+//   - DefaultMaxJSONSize: Maximum JSON request body size and NDJSON line size (1MB)
+//   - DefaultMaxXMLSize: Maximum XML request body size (1MB)
+//   - DefaultMaxNDJSONLines: Maximum number of NDJSON lines (10000)
 //   - DefaultMaxMemory: Maximum memory for multipart form parsing (10MB)
 package binder
@@ -12,6 +12,14 @@ var (
 	// or doesn't match the target struct schema.
 	ErrFailedToParseJSON = errors.New("failed to parse JSON request body")
 
+	// ErrFailedToParseXML indicates the request body contains invalid XML
+	// or doesn't match the target struct schema.
+	ErrFailedToParseXML = errors.New("failed to parse XML request body")
+
+	// ErrFailedToParseNDJSON indicates a line of a newline-delimited JSON body is
+	// invalid or the body exceeds the configured line size or line count.
+	ErrFailedToParseNDJSON = errors.New("failed to parse NDJSON request body")
+
 	// ErrFailedToParseForm indicates form data parsing failed due to malformed
 	// multipart boundaries or invalid URL-encoded data.
 	ErrFailedToParseForm = errors.New("failed to parse form data")
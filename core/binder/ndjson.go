@@ -0,0 +1,216 @@
+package binder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+
+	"github.com/dmitrymomot/foundation/core/sanitizer"
+	"github.com/dmitrymomot/foundation/core/validator"
+)
+
+// DefaultMaxNDJSONLines is the default maximum number of lines of an NDJSON request body.
+const DefaultMaxNDJSONLines = 10000
+
+// NDJSONOption configures NDJSON binding.
+type NDJSONOption func(*ndjsonConfig)
+
+type ndjsonConfig struct {
+	maxLineSize int
+	maxLines    int
+}
+
+// WithMaxLineSize sets the maximum size of a single line, DefaultMaxJSONSize by default.
+func WithMaxLineSize(size int) NDJSONOption {
+	return func(c *ndjsonConfig) {
+		if size > 0 {
+			c.maxLineSize = size
+		}
+	}
+}
+
+// WithMaxLines sets the maximum number of lines, DefaultMaxNDJSONLines by default.
+// A negative value removes the limit, e.g. for streaming imports with StreamNDJSON.
+func WithMaxLines(lines int) NDJSONOption {
+	return func(c *ndjsonConfig) {
+		if lines != 0 {
+			c.maxLines = lines
+		}
+	}
+}
+
+// NDJSON creates a binder function for newline-delimited JSON (application/x-ndjson)
+// bodies, binding each line into an element of the slice v points to. Lines are read
+// one at a time and decoded with the hardening of JSON: each line is limited to
+// DefaultMaxJSONSize, unknown fields and trailing data are rejected, and string fields
+// are sanitized. Struct lines are then sanitized with sanitizer.SanitizeStruct and
+// validated with validator.ValidateStruct, so a bad line fails the request with its
+// line number. Blank lines are skipped.
+//
+// Example:
+//
+//	type ImportUser struct {
+//		Email string `json:"email" sanitize:"trim,lower" validate:"required;email"`
+//	}
+//
+//	var users []ImportUser
+//	if err := binder.NDJSON()(r, &users); err != nil {
+//		http.Error(w, err.Error(), http.StatusBadRequest)
+//		return
+//	}
+func NDJSON(opts ...NDJSONOption) func(r *http.Request, v any) error {
+	cfg := newNDJSONConfig(opts)
+
+	return func(r *http.Request, v any) error {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+			return fmt.Errorf("%w: target must be a pointer to a slice", ErrFailedToParseNDJSON)
+		}
+		slice := rv.Elem()
+		elemType := slice.Type().Elem()
+
+		items := reflect.MakeSlice(slice.Type(), 0, 0)
+		err := decodeNDJSON(r, cfg,
+			func() any { return reflect.New(elemType).Interface() },
+			func(_ int, item any) error {
+				items = reflect.Append(items, reflect.ValueOf(item).Elem())
+				return nil
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		slice.Set(items)
+		return nil
+	}
+}
+
+// StreamNDJSON decodes a newline-delimited JSON body line by line, calling fn with
+// the line number and item of each line as it is read, so large imports are processed
+// without holding the whole body in memory. Lines are decoded, sanitized and validated
+// like NDJSON. An error returned by fn stops decoding and is returned with the line number.
+//
+// Example:
+//
+//	err := binder.StreamNDJSON(r, func(line int, u ImportUser) error {
+//		return users.Create(r.Context(), u.Email)
+//	}, binder.WithMaxLines(-1))
+func StreamNDJSON[T any](r *http.Request, fn func(line int, item T) error, opts ...NDJSONOption) error {
+	return decodeNDJSON(r, newNDJSONConfig(opts),
+		func() any { return new(T) },
+		func(line int, item any) error { return fn(line, *item.(*T)) },
+	)
+}
+
+func newNDJSONConfig(opts []NDJSONOption) ndjsonConfig {
+	cfg := ndjsonConfig{
+		maxLineSize: DefaultMaxJSONSize,
+		maxLines:    DefaultMaxNDJSONLines,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// decodeNDJSON reads the body line by line, decoding each non-blank line into a value
+// created by newItem and passing it to fn.
+func decodeNDJSON(r *http.Request, cfg ndjsonConfig, newItem func() any, fn func(line int, item any) error) error {
+	contentType := r.Header.Get("Content-Type")
+	if contentType == "" {
+		return fmt.Errorf("%w: missing content-type header, expected application/x-ndjson", ErrMissingContentType)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || (mediaType != "application/x-ndjson" && mediaType != "application/ndjson") {
+		return fmt.Errorf("%w: got %s, expected application/x-ndjson", ErrUnsupportedMediaType, contentType)
+	}
+
+	if r.Body == nil {
+		return fmt.Errorf("%w: empty body", ErrFailedToParseNDJSON)
+	}
+
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, min(cfg.maxLineSize, 64<<10)), cfg.maxLineSize)
+
+	line, items := 0, 0
+	for scanner.Scan() {
+		line++
+
+		// Stop reading once the client is gone or the request timed out
+		if err := r.Context().Err(); err != nil {
+			return fmt.Errorf("%w: %v", ErrFailedToParseNDJSON, err)
+		}
+
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		items++
+		if cfg.maxLines > 0 && items > cfg.maxLines {
+			return fmt.Errorf("%w: too many lines (max %d)", ErrFailedToParseNDJSON, cfg.maxLines)
+		}
+
+		item := newItem()
+		if err := decodeNDJSONLine(data, item); err != nil {
+			return fmt.Errorf("%w: line %d: %w", ErrFailedToParseNDJSON, line, err)
+		}
+		if err := validateNDJSONLine(item); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(line, item); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("%w: line %d too large (max %d bytes)", ErrFailedToParseNDJSON, line+1, cfg.maxLineSize)
+		}
+		return fmt.Errorf("%w: failed to read request body: %v", ErrFailedToParseNDJSON, err)
+	}
+	if items == 0 {
+		return fmt.Errorf("%w: empty body", ErrFailedToParseNDJSON)
+	}
+
+	return nil
+}
+
+// decodeNDJSONLine decodes a single line with the strictness of the JSON binder.
+func decodeNDJSONLine(data []byte, item any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(item); err != nil {
+		return err
+	}
+
+	// Verify no trailing data exists after the JSON value
+	var extra json.RawMessage
+	if err := decoder.Decode(&extra); err != io.EOF {
+		return errors.New("unexpected data after JSON value")
+	}
+
+	if err := sanitizeJSONStruct(item); err != nil {
+		return fmt.Errorf("failed to sanitize input: %v", err)
+	}
+	return nil
+}
+
+// validateNDJSONLine applies sanitize and validate tags to struct lines.
+func validateNDJSONLine(item any) error {
+	if reflect.TypeOf(item).Elem().Kind() != reflect.Struct {
+		return nil
+	}
+	if err := sanitizer.SanitizeStruct(item); err != nil {
+		return fmt.Errorf("%w: %v", ErrFailedToParseNDJSON, err)
+	}
+	return validator.ValidateStruct(item)
+}
@@ -0,0 +1,156 @@
+package binder_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/binder"
+	"github.com/dmitrymomot/foundation/core/validator"
+)
+
+type importUser struct {
+	Email string `json:"email" sanitize:"trim,lower" validate:"required;email"`
+	Name  string `json:"name"`
+}
+
+func newNDJSONRequest(body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/users/import", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	return req
+}
+
+func TestNDJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("binds lines into a slice", func(t *testing.T) {
+		t.Parallel()
+
+		var users []importUser
+		err := binder.NDJSON()(newNDJSONRequest("{\"email\":\" Jane@Example.com \",\"name\":\"Jane\\u0000\"}\r\n\n{\"email\":\"bob@example.com\"}\n"), &users)
+
+		require.NoError(t, err)
+		assert.Equal(t, []importUser{
+			{Email: "jane@example.com", Name: "Jane"},
+			{Email: "bob@example.com"},
+		}, users)
+	})
+
+	t.Run("binds non-struct lines", func(t *testing.T) {
+		t.Parallel()
+
+		var values []map[string]int
+		require.NoError(t, binder.NDJSON()(newNDJSONRequest("{\"a\":1}\n{\"b\":2}"), &values))
+		assert.Equal(t, []map[string]int{{"a": 1}, {"b": 2}}, values)
+	})
+
+	t.Run("validates each line", func(t *testing.T) {
+		t.Parallel()
+
+		var users []importUser
+		err := binder.NDJSON()(newNDJSONRequest("{\"email\":\"jane@example.com\"}\n{\"email\":\"not-an-email\"}\n"), &users)
+
+		var validationErrs validator.ValidationErrors
+		require.ErrorAs(t, err, &validationErrs)
+		assert.True(t, validationErrs.Has("Email"))
+		assert.Contains(t, err.Error(), "line 2")
+		assert.Nil(t, users)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name string
+			req  *http.Request
+			opts []binder.NDJSONOption
+			err  error
+		}{
+			{"unsupported content type", httptest.NewRequest(http.MethodPost, "/", strings.NewReader("{}")), nil, binder.ErrMissingContentType},
+			{"empty body", newNDJSONRequest("\n\n"), nil, binder.ErrFailedToParseNDJSON},
+			{"malformed line", newNDJSONRequest("{\"email\":\"a@example.com\"}\n{\"email\":"), nil, binder.ErrFailedToParseNDJSON},
+			{"unknown field", newNDJSONRequest("{\"email\":\"a@example.com\",\"admin\":true}"), nil, binder.ErrFailedToParseNDJSON},
+			{"several values on a line", newNDJSONRequest("{\"email\":\"a@example.com\"} {\"email\":\"b@example.com\"}"), nil, binder.ErrFailedToParseNDJSON},
+			{"line too large", newNDJSONRequest("{\"email\":\"" + strings.Repeat("a", 100) + "@example.com\"}"), []binder.NDJSONOption{binder.WithMaxLineSize(64)}, binder.ErrFailedToParseNDJSON},
+			{"too many lines", newNDJSONRequest(strings.Repeat("{\"email\":\"a@example.com\"}\n", 3)), []binder.NDJSONOption{binder.WithMaxLines(2)}, binder.ErrFailedToParseNDJSON},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				var users []importUser
+				assert.ErrorIs(t, binder.NDJSON(tt.opts...)(tt.req, &users), tt.err)
+			})
+		}
+	})
+
+	t.Run("requires a slice target", func(t *testing.T) {
+		t.Parallel()
+
+		var user importUser
+		assert.ErrorIs(t, binder.NDJSON()(newNDJSONRequest("{\"email\":\"a@example.com\"}"), &user), binder.ErrFailedToParseNDJSON)
+	})
+}
+
+func TestStreamNDJSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("calls fn for each line", func(t *testing.T) {
+		t.Parallel()
+
+		var lines []int
+		var emails []string
+		err := binder.StreamNDJSON(newNDJSONRequest("{\"email\":\"A@example.com\"}\n\n{\"email\":\"b@example.com\"}\n"),
+			func(line int, u importUser) error {
+				lines = append(lines, line)
+				emails = append(emails, u.Email)
+				return nil
+			},
+			binder.WithMaxLines(-1),
+		)
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 3}, lines)
+		assert.Equal(t, []string{"a@example.com", "b@example.com"}, emails)
+	})
+
+	t.Run("stops at the first error", func(t *testing.T) {
+		t.Parallel()
+
+		errDuplicate := errors.New("duplicate email")
+		calls := 0
+		err := binder.StreamNDJSON(newNDJSONRequest("{\"email\":\"a@example.com\"}\n{\"email\":\"a@example.com\"}\n{\"email\":\"c@example.com\"}\n"),
+			func(line int, u importUser) error {
+				calls++
+				if line == 2 {
+					return errDuplicate
+				}
+				return nil
+			},
+		)
+
+		assert.ErrorIs(t, err, errDuplicate)
+		assert.Contains(t, err.Error(), "line 2")
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("stops when the request is canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req := newNDJSONRequest("{\"email\":\"a@example.com\"}\n{\"email\":\"b@example.com\"}\n").WithContext(ctx)
+
+		err := binder.StreamNDJSON(req, func(line int, u importUser) error {
+			cancel()
+			return nil
+		})
+		assert.ErrorIs(t, err, binder.ErrFailedToParseNDJSON)
+	})
+}
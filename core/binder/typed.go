@@ -0,0 +1,270 @@
+package binder
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/response"
+	"github.com/dmitrymomot/foundation/core/sanitizer"
+	"github.com/dmitrymomot/foundation/core/validator"
+)
+
+// TypedOption configures a handler created with Typed.
+type TypedOption func(*typedConfig)
+
+type typedConfig struct {
+	status int
+}
+
+// WithStatus sets the status code of successful responses, http.StatusOK by default.
+func WithStatus(status int) TypedOption {
+	return func(cfg *typedConfig) {
+		if status > 0 {
+			cfg.status = status
+		}
+	}
+}
+
+// Typed adapts a function taking a request struct and returning a result into a handler.
+//
+// The request is bound from the body according to its Content-Type (JSON, XML, NDJSON
+// or form), then from the query string and path parameters into the fields explicitly tagged
+// with query or path. Path parameters are read with ctx.Param and bound last, so
+// they cannot be overridden by the body. The request is then sanitized with
+// sanitizer.SanitizeStruct and validated with validator.ValidateStruct.
+//
+// Binding failures are returned as response.ErrBadRequest, unsupported content types
+// as response.ErrUnsupportedMediaType, and validation failures as
+// response.ErrUnprocessableEntity with the messages of each field in the "fields"
+// detail, keyed by the field's json, form, query or path name; response.ProblemErrorHandler
+// renders them as the "errors" extension. Errors returned by fn are passed to the error
+// handler unchanged.
+//
+// The result is encoded as JSON; a result that is itself a handler.Response is
+// rendered as is.
+//
+// Example:
+//
+//	type CreateUserRequest struct {
+//		OrgID string `path:"org"`
+//		Email string `json:"email" sanitize:"trim,lower" validate:"required;email"`
+//	}
+//
+//	r.Post("/orgs/{org}/users", binder.Typed(
+//		func(ctx *router.Context, req CreateUserRequest) (User, error) {
+//			return users.Create(ctx, req.OrgID, req.Email)
+//		},
+//		binder.WithStatus(http.StatusCreated),
+//	))
+func Typed[C handler.Context, Req, Resp any](fn func(ctx C, req Req) (Resp, error), opts ...TypedOption) handler.HandlerFunc[C] {
+	cfg := typedConfig{status: http.StatusOK}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	src := newRequestSources(reflect.TypeFor[Req]())
+
+	return func(ctx C) handler.Response {
+		var req Req
+		if err := bindRequest(ctx, &req, src); err != nil {
+			return response.Error(err)
+		}
+
+		if src.isStruct {
+			if err := sanitizer.SanitizeStruct(&req); err != nil {
+				return response.Error(err)
+			}
+			if err := validator.ValidateStruct(&req); err != nil {
+				return response.Error(src.validationError(err))
+			}
+		}
+
+		result, err := fn(ctx, req)
+		if err != nil {
+			return response.Error(err)
+		}
+
+		if resp, ok := any(result).(handler.Response); ok {
+			return resp
+		}
+		return response.JSONWithStatus(result, cfg.status)
+	}
+}
+
+// requestSources describes where the fields of a request type are bound from.
+type requestSources struct {
+	isStruct bool
+
+	// query and path hold the names of explicitly tagged fields; untagged
+	// fields default to their lowercase name in the query and path binders,
+	// which must not let the URL override body fields
+	query map[string]bool
+	path  map[string]bool
+
+	// fieldNames maps validator field paths to the names clients use
+	fieldNames map[string]string
+}
+
+func newRequestSources(t reflect.Type) requestSources {
+	src := requestSources{
+		query:      make(map[string]bool),
+		path:       make(map[string]bool),
+		fieldNames: make(map[string]string),
+	}
+	if t.Kind() != reflect.Struct {
+		return src
+	}
+
+	src.isStruct = true
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		if name, ok := explicitTag(f, "query"); ok && name != "" {
+			src.query[name] = true
+		}
+		if name, ok := explicitTag(f, "path"); ok && name != "" {
+			src.path[name] = true
+		}
+	}
+	collectFieldNames(t, "", "", src.fieldNames)
+
+	return src
+}
+
+// collectFieldNames maps the field paths reported by the validator to tag names,
+// following nested structs the way the validator does.
+func collectFieldNames(t reflect.Type, goPrefix, namePrefix string, names map[string]string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+		for _, tagName := range []string{"json", "form", "file", "query", "path"} {
+			if tag, ok := explicitTag(f, tagName); ok && tag != "" {
+				name = tag
+				break
+			}
+		}
+
+		goPath, namePath := f.Name, name
+		if goPrefix != "" {
+			goPath = goPrefix + "." + goPath
+			namePath = namePrefix + "." + namePath
+		}
+		names[goPath] = namePath
+
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() == reflect.Struct {
+			collectFieldNames(ft, goPath, namePath, names)
+		}
+	}
+}
+
+// explicitTag returns the name of a binder tag present on the field.
+// Fields tagged with "-" are reported as not tagged.
+func explicitTag(f reflect.StructField, tagName string) (string, bool) {
+	tag, ok := f.Tag.Lookup(tagName)
+	if !ok || tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
+
+// validationError converts validation errors to a 422 error listing the messages of each field.
+func (src requestSources) validationError(err error) error {
+	errs := validator.ExtractValidationErrors(err)
+	if errs == nil {
+		return err
+	}
+
+	fields := make(map[string][]string)
+	for _, e := range errs {
+		name, ok := src.fieldNames[e.Field]
+		if !ok {
+			name = e.Field
+		}
+		fields[name] = append(fields[name], e.Message)
+	}
+
+	return response.ErrUnprocessableEntity.WithDetails(map[string]any{"fields": fields})
+}
+
+// bindRequest binds the body by content type, then the query string and path parameters.
+func bindRequest[C handler.Context](ctx C, v any, src requestSources) error {
+	r := ctx.Request()
+
+	if r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0 {
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+		var bind func(r *http.Request, v any) error
+		switch mediaType {
+		case "application/json":
+			bind = JSON()
+		case "application/xml", "text/xml":
+			bind = XML()
+		case "application/x-ndjson", "application/ndjson":
+			bind = NDJSON()
+		case "application/x-www-form-urlencoded", "multipart/form-data":
+			bind = Form()
+		case "":
+			return response.ErrUnsupportedMediaType.WithError(fmt.Errorf("%w: request has a body", ErrMissingContentType))
+		default:
+			return response.ErrUnsupportedMediaType.WithError(fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType))
+		}
+
+		if err := bind(r, v); err != nil {
+			return bindError(err)
+		}
+	}
+
+	if len(src.query) > 0 {
+		values := make(map[string][]string, len(src.query))
+		for name, vals := range r.URL.Query() {
+			if src.query[name] {
+				values[name] = vals
+			}
+		}
+		if err := bindToStruct(v, "query", values, ErrFailedToParseQuery); err != nil {
+			return bindError(err)
+		}
+	}
+
+	if len(src.path) > 0 {
+		params := func(_ *http.Request, name string) string {
+			if !src.path[name] {
+				return ""
+			}
+			return ctx.Param(name)
+		}
+		if err := Path(params)(r, v); err != nil {
+			return bindError(err)
+		}
+	}
+
+	return nil
+}
+
+func bindError(err error) error {
+	if errors.Is(err, ErrUnsupportedMediaType) || errors.Is(err, ErrMissingContentType) {
+		return response.ErrUnsupportedMediaType.WithError(err)
+	}
+	// NDJSON lines are validated while binding
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return response.ErrUnprocessableEntity.WithError(err)
+	}
+	return response.ErrBadRequest.WithError(err)
+}
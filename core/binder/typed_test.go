@@ -0,0 +1,225 @@
+package binder_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/binder"
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/response"
+	"github.com/dmitrymomot/foundation/core/router"
+)
+
+type typedAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type createMemberRequest struct {
+	OrgID   string        `path:"org"`
+	Notify  bool          `query:"notify"`
+	Email   string        `json:"email" form:"email" sanitize:"trim,lower" validate:"required;email"`
+	Role    string        `json:"role" form:"role" validate:"in:admin,member"`
+	Address *typedAddress `json:"address"`
+}
+
+type member struct {
+	OrgID  string `json:"org_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+	Notify bool   `json:"notify"`
+}
+
+func newTypedRouter(t *testing.T) router.Router[*router.Context] {
+	t.Helper()
+
+	r := router.New[*router.Context](router.WithErrorHandler(response.JSONErrorHandler[*router.Context]))
+	r.Post("/orgs/{org}/members", binder.Typed(
+		func(ctx *router.Context, req createMemberRequest) (member, error) {
+			if req.Email == "taken@example.com" {
+				return member{}, response.ErrConflict.WithMessage("email is taken")
+			}
+			return member{OrgID: req.OrgID, Email: req.Email, Role: req.Role, Notify: req.Notify}, nil
+		},
+		binder.WithStatus(http.StatusCreated),
+	))
+	r.Get("/members/{id}/avatar", binder.Typed(
+		func(ctx *router.Context, req struct {
+			ID string `path:"id"`
+		}) (handler.Response, error) {
+			return response.Redirect("/avatars/" + req.ID + ".png"), nil
+		},
+	))
+	r.Post("/members/import", binder.Typed(
+		func(ctx *router.Context, req []member) (int, error) {
+			if len(req) == 0 {
+				return 0, errors.New("nothing to import")
+			}
+			return len(req), nil
+		},
+	))
+	return r
+}
+
+func serveTyped(r http.Handler, req *http.Request) (*httptest.ResponseRecorder, map[string]any) {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var body map[string]any
+	_ = json.Unmarshal(w.Body.Bytes(), &body)
+	return w, body
+}
+
+func TestTyped(t *testing.T) {
+	t.Parallel()
+
+	r := newTypedRouter(t)
+
+	t.Run("binds JSON, query and path", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/orgs/acme/members?notify=true",
+			strings.NewReader(`{"email":"  Jane@Example.com ","role":"admin","address":{"city":"Berlin"}}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w, body := serveTyped(r, req)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, map[string]any{"org_id": "acme", "email": "jane@example.com", "role": "admin", "notify": true}, body)
+	})
+
+	t.Run("binds form", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/orgs/acme/members",
+			strings.NewReader(url.Values{"email": {"jane@example.com"}, "role": {"member"}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		w, body := serveTyped(r, req)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, "member", body["role"])
+	})
+
+	t.Run("path parameters cannot be overridden", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/orgs/acme/members?org=evil&OrgID=evil",
+			strings.NewReader(`{"email":"jane@example.com","role":"member","OrgID":"evil"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w, body := serveTyped(r, req)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		assert.Equal(t, "acme", body["org_id"])
+	})
+
+	t.Run("maps validation errors to 422 with field details", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/orgs/acme/members",
+			strings.NewReader(`{"email":"not-an-email","role":"owner","address":{}}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w, body := serveTyped(r, req)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, "unprocessable_entity", body["code"])
+
+		fields, ok := body["details"].(map[string]any)["fields"].(map[string]any)
+		require.True(t, ok, w.Body.String())
+		assert.ElementsMatch(t, []string{"email", "role", "address.city"}, keysOf(fields))
+	})
+
+	t.Run("renders field details as problem errors", func(t *testing.T) {
+		t.Parallel()
+
+		problems := router.New[*router.Context](router.WithErrorHandler(response.ProblemErrorHandler[*router.Context]))
+		problems.Post("/members", binder.Typed(func(ctx *router.Context, req createMemberRequest) (member, error) {
+			return member{}, nil
+		}))
+
+		req := httptest.NewRequest(http.MethodPost, "/members",
+			strings.NewReader(`{"email":"jane@example.com","role":"member","address":{}}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w, body := serveTyped(problems, req)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		assert.Equal(t, response.ProblemContentType, w.Header().Get("Content-Type"))
+		assert.Equal(t, []any{map[string]any{"field": "address.city", "detail": "field is required"}}, body["errors"])
+	})
+
+	t.Run("rejects malformed and unsupported bodies", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/orgs/acme/members", strings.NewReader(`{"email":`))
+		req.Header.Set("Content-Type", "application/json")
+		w, body := serveTyped(r, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "bad_request", body["code"])
+
+		req = httptest.NewRequest(http.MethodPost, "/orgs/acme/members", strings.NewReader(`email=jane@example.com`))
+		req.Header.Set("Content-Type", "text/plain")
+		w, _ = serveTyped(r, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+		req = httptest.NewRequest(http.MethodPost, "/orgs/acme/members", strings.NewReader(`{}`))
+		w, _ = serveTyped(r, req)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("passes handler errors to the error handler", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/orgs/acme/members", strings.NewReader(`{"email":"taken@example.com","role":"member"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w, body := serveTyped(r, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "email is taken", body["message"])
+	})
+
+	t.Run("renders handler responses as is", func(t *testing.T) {
+		t.Parallel()
+
+		w, _ := serveTyped(r, httptest.NewRequest(http.MethodGet, "/members/42/avatar", nil))
+		assert.Equal(t, http.StatusFound, w.Code)
+		assert.Equal(t, "/avatars/42.png", w.Header().Get("Location"))
+	})
+
+	t.Run("binds non-struct JSON requests", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/members/import", strings.NewReader(`[{"email":"a@example.com"},{"email":"b@example.com"}]`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "2\n", w.Body.String())
+	})
+
+	t.Run("binds NDJSON requests", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodPost, "/members/import", strings.NewReader("{\"email\":\"a@example.com\"}\n{\"email\":\"b@example.com\"}\n{\"email\":\"c@example.com\"}\n"))
+		req.Header.Set("Content-Type", "application/x-ndjson")
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3\n", w.Body.String())
+	})
+}
+
+func keysOf(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,88 @@
+package binder_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/binder"
+)
+
+type xmlInvoice struct {
+	XMLName  xml.Name `xml:"invoice"`
+	Number   string   `xml:"number,attr"`
+	Customer string   `xml:"customer"`
+	Total    float64  `xml:"total"`
+	Lines    []string `xml:"lines>line"`
+}
+
+func TestXML(t *testing.T) {
+	t.Parallel()
+
+	newRequest := func(contentType, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/invoices", strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		return req
+	}
+
+	t.Run("binds XML documents", func(t *testing.T) {
+		t.Parallel()
+
+		for _, contentType := range []string{"application/xml", "text/xml; charset=utf-8"} {
+			var inv xmlInvoice
+			err := binder.XML()(newRequest(contentType, `<?xml version="1.0"?>
+<invoice number="INV-1"><customer>Acme</customer><total>99.5</total><lines><line>a</line><line>b</line></lines></invoice>
+<!-- trailing comment -->`), &inv)
+
+			require.NoError(t, err, contentType)
+			assert.Equal(t, "INV-1", inv.Number)
+			assert.Equal(t, "Acme", inv.Customer)
+			assert.Equal(t, 99.5, inv.Total)
+			assert.Equal(t, []string{"a", "b"}, inv.Lines)
+		}
+	})
+
+	t.Run("sanitizes strings", func(t *testing.T) {
+		t.Parallel()
+
+		var inv xmlInvoice
+		require.NoError(t, binder.XML()(newRequest("application/xml", "<invoice><customer>Acme&#xD;&#xA;Inc</customer></invoice>"), &inv))
+		assert.Equal(t, "AcmeInc", inv.Customer)
+	})
+
+	t.Run("rejects invalid requests", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name        string
+			contentType string
+			body        string
+			err         error
+		}{
+			{"missing content type", "", "<invoice/>", binder.ErrMissingContentType},
+			{"unsupported content type", "application/json", "<invoice/>", binder.ErrUnsupportedMediaType},
+			{"empty body", "application/xml", "", binder.ErrFailedToParseXML},
+			{"malformed", "application/xml", "<invoice><total>1</invoice>", binder.ErrFailedToParseXML},
+			{"trailing element", "application/xml", "<invoice/><invoice/>", binder.ErrFailedToParseXML},
+			{"trailing text", "application/xml", "<invoice/>extra", binder.ErrFailedToParseXML},
+			{"undefined entity", "application/xml", `<!DOCTYPE invoice [<!ENTITY xxe SYSTEM "file:///etc/passwd">]><invoice><customer>&xxe;</customer></invoice>`, binder.ErrFailedToParseXML},
+			{"too large", "application/xml", "<invoice><customer>" + strings.Repeat("a", binder.DefaultMaxXMLSize) + "</customer></invoice>", binder.ErrFailedToParseXML},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				var inv xmlInvoice
+				assert.ErrorIs(t, binder.XML()(newRequest(tt.contentType, tt.body), &inv), tt.err)
+			})
+		}
+	})
+}
@@ -0,0 +1,66 @@
+// Package openapi generates OpenAPI 3.1 documents from the routes of a router,
+// so API documentation follows the code instead of drifting from it.
+//
+// Paths and methods come from Router.Routes, including the routes of mounted
+// subrouters. Regexp constraints of path parameters become schema patterns and
+// a catch-all becomes the {*} parameter. Operations can be described with
+// summaries, tags and request and response types:
+//
+//	type CreateUserRequest struct {
+//		OrgID string `path:"org" validate:"uuid"`
+//		Name  string `json:"name" validate:"required;min:2;max:100"`
+//		Email string `json:"email" validate:"required;email"`
+//		Role  string `json:"role" validate:"in:admin,member"`
+//	}
+//
+//	spec := openapi.New(r,
+//		openapi.WithInfo("Billing API", "1.0.0"),
+//		openapi.WithOperation(http.MethodPost, "/orgs/{org}/users", openapi.Operation{
+//			Summary:  "Create a user",
+//			Tags:     []string{"users"},
+//			Request:  CreateUserRequest{},
+//			Response: User{},
+//			Status:   http.StatusCreated,
+//		}),
+//	)
+//	r.Route("/docs", openapi.DocsRoutes[*router.Context](spec))
+//
+// Operations can also be attached to routes at registration with Describe,
+// which stores them in the route metadata:
+//
+//	r.Get("/files/*", downloadFile, openapi.Describe(openapi.Operation{
+//		Summary: "Download a file",
+//		Tags:    []string{"files"},
+//	}))
+//
+// # Hosts
+//
+// Routes of host routers are not documented by default, since several hosts may
+// serve the same path. WithHost documents the routes of one host router instead,
+// and WithHostOperation describes them:
+//
+//	spec := openapi.New(r,
+//		openapi.WithHost("api.example.com"),
+//		openapi.WithServer("https://api.example.com", "Production"),
+//		openapi.WithHostOperation("api.example.com", http.MethodGet, "/", openapi.Operation{
+//			Summary: "API status",
+//		}),
+//	)
+//
+// # Schemas
+//
+// Request structs are split the way the binder package fills them: fields tagged
+// with path and query become parameters, fields tagged with form or file become
+// a form body, and fields tagged with json or not tagged at all become a JSON body.
+// Named struct types are added to the components and referenced with $ref.
+//
+// Validate tags are mapped to schema keywords:
+//
+//   - required: the field is listed as required
+//   - min, max: minLength/maxLength for strings, minItems/maxItems for slices,
+//     minimum/maximum for numbers
+//   - email, uuid: the string format
+//   - in: the enum of allowed strings
+//
+// Other rules are not represented in the document.
+package openapi
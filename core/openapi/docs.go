@@ -0,0 +1,128 @@
+package openapi
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/response"
+	"github.com/dmitrymomot/foundation/core/router"
+)
+
+// Names of the routes registered by DocsRoutes; they are left out of the document.
+const (
+	SpecRouteName = "openapi.spec"
+	DocsRouteName = "openapi.docs"
+)
+
+// DocsRoutes returns a route group serving the document of the spec.
+//
+// Endpoints:
+//   - GET /              minimal HTML documentation page
+//   - GET /openapi.json  OpenAPI document
+//
+// Example:
+//
+//	spec := openapi.New(r, openapi.WithInfo("Billing API", "1.0.0"))
+//	r.Route("/docs", openapi.DocsRoutes[*router.Context](spec))
+func DocsRoutes[C handler.Context](spec *Spec) func(r router.Router[C]) {
+	return func(r router.Router[C]) {
+		r.Get("/", docsPage[C](spec), router.Name(DocsRouteName))
+		r.Get("/openapi.json", specDocument[C](spec), router.Name(SpecRouteName))
+	}
+}
+
+func isDocsRoute(name string) bool {
+	return name == SpecRouteName || name == DocsRouteName
+}
+
+func specDocument[C handler.Context](spec *Spec) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		return response.JSON(spec.document())
+	}
+}
+
+func docsPage[C handler.Context](spec *Spec) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		return response.Template(docsTemplate, docsData{
+			Doc:     spec.document(),
+			SpecURL: specURL(ctx.Request()),
+		})
+	}
+}
+
+// specURL returns the link to the document relative to the docs page.
+// Mounted routers see the path without their prefix, so the original
+// request URI is used to tell /docs from /docs/.
+func specURL(r *http.Request) string {
+	path, _, _ := strings.Cut(r.RequestURI, "?")
+	if path == "" || strings.HasSuffix(path, "/") {
+		return "openapi.json"
+	}
+	return path[strings.LastIndex(path, "/")+1:] + "/openapi.json"
+}
+
+// methodOrder is the order operations are listed in on the docs page.
+var methodOrder = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+type docsData struct {
+	Doc     *document
+	SpecURL string
+}
+
+var docsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"methods": func(item pathItem) []string {
+		var methods []string
+		for _, m := range methodOrder {
+			if item[m] != nil {
+				methods = append(methods, m)
+			}
+		}
+		return methods
+	},
+	"upper": strings.ToUpper,
+	"json": func(v any) (string, error) {
+		b, err := json.MarshalIndent(v, "", "  ")
+		return string(b), err
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Doc.Info.Title}}</title>
+<style>
+body{font-family:system-ui,sans-serif;max-width:960px;margin:2rem auto;padding:0 1rem;color:#1f2328}
+.op{border:1px solid #d0d7de;border-radius:6px;margin:1rem 0;padding:.75rem 1rem}
+.method{display:inline-block;min-width:4.5rem;font-weight:700}
+code,pre{font-family:ui-monospace,monospace;font-size:.85rem}
+pre{background:#f6f8fa;padding:.75rem;overflow:auto}
+table{border-collapse:collapse}td,th{text-align:left;padding:.25rem .75rem .25rem 0}
+</style>
+</head>
+<body>
+<h1>{{.Doc.Info.Title}} <small>{{.Doc.Info.Version}}</small></h1>
+{{with .Doc.Info.Description}}<p>{{.}}</p>{{end}}
+<p><a href="{{.SpecURL}}">OpenAPI document</a></p>
+{{range $path, $item := .Doc.Paths}}{{range $method := methods $item}}{{with index $item $method}}
+<section class="op">
+<h2><span class="method">{{upper $method}}</span> <code>{{$path}}</code></h2>
+{{with .Summary}}<p><strong>{{.}}</strong></p>{{end}}
+{{with .Description}}<p>{{.}}</p>{{end}}
+{{with .Parameters}}<table>
+<tr><th>Parameter</th><th>In</th><th>Required</th><th>Schema</th></tr>
+{{range .}}<tr><td><code>{{.Name}}</code></td><td>{{.In}}</td><td>{{.Required}}</td><td><code>{{json .Schema}}</code></td></tr>
+{{end}}</table>{{end}}
+{{with .RequestBody}}<h3>Request body</h3>{{range $type, $media := .Content}}<p><code>{{$type}}</code></p><pre>{{json $media.Schema}}</pre>{{end}}{{end}}
+<h3>Responses</h3>
+{{range $status, $resp := .Responses}}<p><code>{{$status}}</code> {{$resp.Description}}</p>{{range $type, $media := $resp.Content}}<pre>{{json $media.Schema}}</pre>{{end}}{{end}}
+</section>
+{{end}}{{end}}{{end}}
+{{with .Doc.Components}}<h2>Schemas</h2>
+{{range $name, $schema := .Schemas}}<h3 id="{{$name}}">{{$name}}</h3><pre>{{json $schema}}</pre>
+{{end}}{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,81 @@
+package openapi
+
+// document is the OpenAPI document, limited to the objects the generator produces.
+type document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       info                `json:"info"`
+	Servers    []server            `json:"servers,omitempty"`
+	Paths      map[string]pathItem `json:"paths"`
+	Components *components         `json:"components,omitempty"`
+}
+
+type info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+// pathItem maps lowercase HTTP methods to operations.
+type pathItem map[string]*operation
+
+type operation struct {
+	Tags        []string                      `json:"tags,omitempty"`
+	Summary     string                        `json:"summary,omitempty"`
+	Description string                        `json:"description,omitempty"`
+	OperationID string                        `json:"operationId,omitempty"`
+	Parameters  []*parameter                  `json:"parameters,omitempty"`
+	RequestBody *requestBody                  `json:"requestBody,omitempty"`
+	Responses   map[string]*operationResponse `json:"responses"`
+	Deprecated  bool                          `json:"deprecated,omitempty"`
+}
+
+type parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type operationResponse struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type components struct {
+	Schemas map[string]*schema `json:"schemas,omitempty"`
+}
+
+// schema is a JSON Schema (draft 2020-12) as used by OpenAPI 3.1.
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 any                `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
@@ -0,0 +1,317 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dmitrymomot/foundation/core/router"
+)
+
+// Version is the OpenAPI version of generated documents.
+const Version = "3.1.0"
+
+// Operation describes a route in the generated document.
+// All fields are optional; routes without an Operation are documented
+// from their pattern alone.
+type Operation struct {
+	Summary     string
+	Description string
+	Tags        []string
+
+	// OperationID defaults to the route name set with router.Name
+	OperationID string
+	Deprecated  bool
+
+	// Request is a value of the struct the handler binds. Fields tagged with
+	// path and query become parameters, fields tagged with form or file become
+	// a form body, and the remaining fields become a JSON body.
+	Request any
+
+	// Response is a value of the JSON response body type
+	Response any
+
+	// Status is the success status code, http.StatusOK by default
+	Status int
+}
+
+// Spec generates an OpenAPI document from the routes of a router.
+// The document is generated on each call, so routes registered after
+// the Spec was created are included.
+type Spec struct {
+	routes     router.Routes
+	host       string
+	info       info
+	servers    []server
+	operations map[string]Operation
+}
+
+// Option configures a Spec.
+type Option func(*Spec)
+
+// WithInfo sets the title and version of the API.
+func WithInfo(title, version string) Option {
+	return func(s *Spec) {
+		s.info.Title = title
+		s.info.Version = version
+	}
+}
+
+// WithDescription sets the description of the API. CommonMark syntax may be used.
+func WithDescription(description string) Option {
+	return func(s *Spec) {
+		s.info.Description = description
+	}
+}
+
+// WithServer adds a server the API is available at.
+func WithServer(url, description string) Option {
+	return func(s *Spec) {
+		s.servers = append(s.servers, server{URL: url, Description: description})
+	}
+}
+
+// WithHost documents the routes of the host router registered with the pattern
+// instead of the routes served for any host. Routes of host routers are skipped
+// by default, as different hosts may serve different operations on the same path.
+//
+//	spec := openapi.New(r, openapi.WithHost("api.example.com"))
+func WithHost(pattern string) Option {
+	return func(s *Spec) {
+		s.host = pattern
+	}
+}
+
+// WithOperation describes the route registered for the method and pattern.
+// The pattern is the full pattern reported by Router.Routes, including the
+// prefixes of Route and Mount.
+func WithOperation(method, pattern string, op Operation) Option {
+	return WithHostOperation("", method, pattern, op)
+}
+
+// WithHostOperation describes the route registered for the method and pattern
+// on the host router registered with the host pattern.
+func WithHostOperation(host, method, pattern string, op Operation) Option {
+	return func(s *Spec) {
+		s.operations[operationKey(host, method, pattern)] = op
+	}
+}
+
+// operationMetaKey is the route metadata key of operations attached with Describe.
+const operationMetaKey = "openapi.operation"
+
+// Describe describes a route at registration; WithOperation takes precedence
+// for the same route.
+//
+//	r.Post("/users", createUser, openapi.Describe(openapi.Operation{
+//		Summary: "Create a user",
+//		Request: CreateUserRequest{},
+//	}))
+func Describe(op Operation) router.RouteOption {
+	return router.Meta(operationMetaKey, op)
+}
+
+// New creates a Spec documenting the routes of the given router.
+func New(routes router.Routes, opts ...Option) *Spec {
+	s := &Spec{
+		routes:     routes,
+		info:       info{Title: "API", Version: "1.0.0"},
+		operations: make(map[string]Operation),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// JSON returns the OpenAPI document encoded as JSON.
+func (s *Spec) JSON() ([]byte, error) {
+	return json.Marshal(s.document())
+}
+
+// document builds the OpenAPI document from the current routes.
+func (s *Spec) document() *document {
+	routes := s.routes.Routes()
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Pattern != routes[j].Pattern {
+			return routes[i].Pattern < routes[j].Pattern
+		}
+		if routes[i].Method != routes[j].Method {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Host < routes[j].Host
+	})
+
+	doc := &document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Servers: s.servers,
+		Paths:   make(map[string]pathItem),
+	}
+	schemas := newSchemaGenerator()
+
+	for _, rt := range routes {
+		// OpenAPI has no CONNECT operations
+		if rt.Method == http.MethodConnect || isDocsRoute(rt.Name) {
+			continue
+		}
+		// Paths are not keyed by host, so only the routes of one host are documented
+		if rt.Host != s.host {
+			continue
+		}
+
+		path, params := parsePattern(rt.Pattern)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = make(pathItem)
+			doc.Paths[path] = item
+		}
+		item[strings.ToLower(rt.Method)] = s.operation(rt, params, schemas)
+	}
+
+	if len(schemas.components) > 0 {
+		doc.Components = &components{Schemas: schemas.components}
+	}
+	return doc
+}
+
+// operation builds the operation object of a route.
+func (s *Spec) operation(rt router.Route, params []patternParam, schemas *schemaGenerator) *operation {
+	meta, ok := s.operations[operationKey(rt.Host, rt.Method, rt.Pattern)]
+	if !ok {
+		meta, _ = rt.Meta[operationMetaKey].(Operation)
+	}
+
+	op := &operation{
+		Summary:     meta.Summary,
+		Description: meta.Description,
+		Tags:        meta.Tags,
+		OperationID: meta.OperationID,
+		Deprecated:  meta.Deprecated,
+		Responses:   make(map[string]*operationResponse),
+	}
+	if op.OperationID == "" {
+		op.OperationID = rt.Name
+	}
+
+	var req *requestFields
+	if meta.Request != nil {
+		req = schemas.requestFields(reflect.TypeOf(meta.Request))
+	}
+
+	// Every pattern parameter is documented, typed by the request struct when it binds it
+	for _, p := range params {
+		param := &parameter{Name: p.name, In: "path", Required: true, Schema: &schema{Type: "string"}}
+		if req != nil {
+			if bound, ok := req.path[p.name]; ok {
+				param.Schema = bound.Schema
+			}
+		}
+		if p.pattern != "" && param.Schema.Type == "string" {
+			param.Schema.Pattern = p.pattern
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+
+	if req != nil {
+		op.Parameters = append(op.Parameters, req.query...)
+		if hasBody(rt.Method) {
+			op.RequestBody = req.body()
+		}
+	}
+
+	status := meta.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	resp := &operationResponse{Description: http.StatusText(status)}
+	if meta.Response != nil {
+		resp.Content = map[string]mediaType{
+			"application/json": {Schema: schemas.schemaOf(reflect.TypeOf(meta.Response))},
+		}
+	}
+	op.Responses[statusKey(status)] = resp
+
+	return op
+}
+
+// hasBody reports whether request bodies are documented for the method.
+func hasBody(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	}
+	return false
+}
+
+func statusKey(status int) string {
+	return strconv.Itoa(status)
+}
+
+func operationKey(host, method, pattern string) string {
+	return strings.ToUpper(method) + " " + host + pattern
+}
+
+// patternParam is a parameter of a route pattern.
+type patternParam struct {
+	name    string
+	pattern string
+}
+
+// parsePattern converts a router pattern to an OpenAPI path template:
+// regexp constraints are moved to the parameters and a catch-all
+// becomes the {*} parameter.
+func parsePattern(pattern string) (string, []patternParam) {
+	var (
+		b      strings.Builder
+		params []patternParam
+	)
+
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			// Read to the matching closing brace; regexps may contain braces
+			depth, end := 0, -1
+			for j := i; j < len(pattern) && end < 0; j++ {
+				switch pattern[j] {
+				case '{':
+					depth++
+				case '}':
+					depth--
+					if depth == 0 {
+						end = j
+					}
+				}
+			}
+			if end < 0 {
+				b.WriteString(pattern[i:])
+				return b.String(), params
+			}
+
+			name, rexpat, _ := strings.Cut(pattern[i+1:end], ":")
+			if rexpat != "" {
+				if !strings.HasPrefix(rexpat, "^") {
+					rexpat = "^" + rexpat
+				}
+				if !strings.HasSuffix(rexpat, "$") {
+					rexpat += "$"
+				}
+			}
+			params = append(params, patternParam{name: name, pattern: rexpat})
+			b.WriteString("{" + name + "}")
+			i = end
+
+		case '*':
+			params = append(params, patternParam{name: "*"})
+			b.WriteString("{*}")
+
+		default:
+			b.WriteByte(pattern[i])
+		}
+	}
+
+	return b.String(), params
+}
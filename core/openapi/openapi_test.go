@@ -0,0 +1,304 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/openapi"
+	"github.com/dmitrymomot/foundation/core/router"
+)
+
+type createUserRequest struct {
+	OrgID  string `path:"org" validate:"uuid"`
+	Notify bool   `query:"notify"`
+	Name   string `json:"name" validate:"required;min:2;max:100"`
+	Email  string `json:"email" validate:"required;email"`
+	Role   string `json:"role,omitempty" validate:"in:admin,member"`
+	Age    int    `json:"age" validate:"min:18"`
+	Tags   []string
+}
+
+type listUsersRequest struct {
+	Page  int    `query:"page" validate:"min:1"`
+	Query string `query:"q" validate:"required"`
+}
+
+type uploadAvatarRequest struct {
+	ID     int64                 `path:"id"`
+	Title  string                `form:"title" validate:"max:50"`
+	Avatar *multipart.FileHeader `file:"avatar" validate:"required"`
+}
+
+type user struct {
+	ID        uuid.UUID         `json:"id"`
+	Name      string            `json:"name"`
+	CreatedAt time.Time         `json:"created_at"`
+	Manager   *user             `json:"manager,omitempty"`
+	Labels    map[string]string `json:"labels"`
+	password  string
+}
+
+func newSpec(t *testing.T) (router.Router[*router.Context], map[string]any) {
+	t.Helper()
+
+	h := func(ctx *router.Context) handler.Response { return nil }
+
+	r := router.New[*router.Context]()
+	r.Route("/orgs/{org}/users", func(r router.Router[*router.Context]) {
+		r.Get("/", h, router.Name("users.list"))
+		r.Post("/", h)
+	})
+	r.Post("/users/{id:[0-9]+}/avatar", h)
+	r.Get("/files/*", h, openapi.Describe(openapi.Operation{Summary: "Download a file", Tags: []string{"files"}}))
+	r.Connect("/tunnel", h)
+
+	spec := openapi.New(r,
+		openapi.WithInfo("Users API", "2.0.0"),
+		openapi.WithServer("https://api.example.com", "Production"),
+		openapi.WithOperation(http.MethodGet, "/orgs/{org}/users", openapi.Operation{
+			Summary:  "List users",
+			Request:  listUsersRequest{},
+			Response: []user{},
+		}),
+		openapi.WithOperation(http.MethodPost, "/orgs/{org}/users", openapi.Operation{
+			Summary:     "Create a user",
+			Tags:        []string{"users"},
+			OperationID: "createUser",
+			Request:     &createUserRequest{},
+			Response:    user{},
+			Status:      http.StatusCreated,
+		}),
+		openapi.WithOperation(http.MethodPost, "/users/{id:[0-9]+}/avatar", openapi.Operation{
+			Request: uploadAvatarRequest{},
+		}),
+	)
+	r.Route("/docs", openapi.DocsRoutes[*router.Context](spec))
+
+	data, err := spec.JSON()
+	require.NoError(t, err)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(data, &doc))
+	return r, doc
+}
+
+// lookup walks a decoded JSON document by object keys and array indexes.
+func lookup(t *testing.T, v any, path ...any) any {
+	t.Helper()
+
+	for _, key := range path {
+		switch k := key.(type) {
+		case string:
+			obj, ok := v.(map[string]any)
+			require.True(t, ok, "expected object at %v", key)
+			v, ok = obj[k]
+			require.True(t, ok, "missing key %q", k)
+		case int:
+			arr, ok := v.([]any)
+			require.True(t, ok, "expected array at %v", key)
+			require.Greater(t, len(arr), k)
+			v = arr[k]
+		}
+	}
+	return v
+}
+
+func TestSpec(t *testing.T) {
+	t.Parallel()
+
+	_, doc := newSpec(t)
+
+	t.Run("documents routes", func(t *testing.T) {
+		t.Parallel()
+
+		assert.Equal(t, "3.1.0", doc["openapi"])
+		assert.Equal(t, "Users API", lookup(t, doc, "info", "title"))
+		assert.Equal(t, "https://api.example.com", lookup(t, doc, "servers", 0, "url"))
+
+		paths := lookup(t, doc, "paths").(map[string]any)
+		assert.ElementsMatch(t, []string{
+			"/orgs/{org}/users",
+			"/users/{id}/avatar",
+			"/files/{*}",
+		}, keys(paths))
+		assert.Equal(t, "users.list", lookup(t, paths, "/orgs/{org}/users", "get", "operationId"))
+		assert.Equal(t, "createUser", lookup(t, paths, "/orgs/{org}/users", "post", "operationId"))
+		assert.Equal(t, "OK", lookup(t, paths, "/files/{*}", "get", "responses", "200", "description"))
+		assert.Equal(t, "Download a file", lookup(t, paths, "/files/{*}", "get", "summary"))
+		assert.Equal(t, []any{"files"}, lookup(t, paths, "/files/{*}", "get", "tags"))
+		assert.Equal(t, "*", lookup(t, paths, "/files/{*}", "get", "parameters", 0, "name"))
+	})
+
+	t.Run("derives parameters from path and query tags", func(t *testing.T) {
+		t.Parallel()
+
+		params := lookup(t, doc, "paths", "/orgs/{org}/users", "get", "parameters").([]any)
+		require.Len(t, params, 3)
+		assert.Equal(t, map[string]any{"name": "org", "in": "path", "required": true, "schema": map[string]any{"type": "string"}}, params[0])
+		assert.Equal(t, map[string]any{"name": "page", "in": "query", "schema": map[string]any{"type": "integer", "format": "int64", "minimum": 1.0}}, params[1])
+		assert.Equal(t, map[string]any{"name": "q", "in": "query", "required": true, "schema": map[string]any{"type": "string"}}, params[2])
+
+		org := lookup(t, doc, "paths", "/orgs/{org}/users", "post", "parameters", 0)
+		assert.Equal(t, "uuid", lookup(t, org, "schema", "format"))
+
+		id := lookup(t, doc, "paths", "/users/{id}/avatar", "post", "parameters", 0)
+		assert.Equal(t, map[string]any{"type": "integer", "format": "int64"}, lookup(t, id, "schema"))
+	})
+
+	t.Run("derives JSON body from json and validate tags", func(t *testing.T) {
+		t.Parallel()
+
+		op := lookup(t, doc, "paths", "/orgs/{org}/users", "post")
+		assert.Equal(t, true, lookup(t, op, "requestBody", "required"))
+
+		body := lookup(t, op, "requestBody", "content", "application/json", "schema")
+		assert.ElementsMatch(t, []any{"name", "email"}, lookup(t, body, "required"))
+
+		props := lookup(t, body, "properties").(map[string]any)
+		assert.ElementsMatch(t, []string{"name", "email", "role", "age", "Tags"}, keys(props))
+		assert.Equal(t, map[string]any{"type": "string", "minLength": 2.0, "maxLength": 100.0}, props["name"])
+		assert.Equal(t, map[string]any{"type": "string", "format": "email"}, props["email"])
+		assert.Equal(t, map[string]any{"type": "string", "enum": []any{"admin", "member"}}, props["role"])
+		assert.Equal(t, map[string]any{"type": "integer", "format": "int64", "minimum": 18.0}, props["age"])
+		assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, props["Tags"])
+
+		assert.Equal(t, "#/components/schemas/user", lookup(t, op, "responses", "201", "content", "application/json", "schema", "$ref"))
+	})
+
+	t.Run("derives form body from form and file tags", func(t *testing.T) {
+		t.Parallel()
+
+		body := lookup(t, doc, "paths", "/users/{id}/avatar", "post", "requestBody", "content", "multipart/form-data", "schema")
+		assert.Equal(t, []any{"avatar"}, lookup(t, body, "required"))
+		assert.Equal(t, map[string]any{"type": "string", "format": "binary"}, lookup(t, body, "properties", "avatar"))
+		assert.Equal(t, map[string]any{"type": "string", "maxLength": 50.0}, lookup(t, body, "properties", "title"))
+	})
+
+	t.Run("adds named types to components", func(t *testing.T) {
+		t.Parallel()
+
+		u := lookup(t, doc, "components", "schemas", "user")
+		props := lookup(t, u, "properties").(map[string]any)
+		assert.ElementsMatch(t, []string{"id", "name", "created_at", "manager", "labels"}, keys(props))
+		assert.Equal(t, map[string]any{"type": "string", "format": "uuid"}, props["id"])
+		assert.Equal(t, map[string]any{"type": "string", "format": "date-time"}, props["created_at"])
+		assert.Equal(t, map[string]any{"$ref": "#/components/schemas/user"}, props["manager"])
+		assert.Equal(t, map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}, props["labels"])
+
+		list := lookup(t, doc, "paths", "/orgs/{org}/users", "get", "responses", "200", "content", "application/json", "schema")
+		assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"$ref": "#/components/schemas/user"}}, list)
+	})
+
+	t.Run("keeps regexp constraints", func(t *testing.T) {
+		t.Parallel()
+
+		spec := openapi.New(routes{{Method: http.MethodGet, Pattern: "/posts/{slug:[a-z-]+}"}})
+		data, err := spec.JSON()
+		require.NoError(t, err)
+
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(data, &doc))
+		assert.Equal(t, "^[a-z-]+$", lookup(t, doc, "paths", "/posts/{slug}", "get", "parameters", 0, "schema", "pattern"))
+	})
+
+	t.Run("documents routes of one host", func(t *testing.T) {
+		t.Parallel()
+
+		h := func(ctx *router.Context) handler.Response { return nil }
+
+		r := router.New[*router.Context]()
+		r.Get("/health", h)
+		r.Host("api.example.com", func(r router.Router[*router.Context]) {
+			r.Get("/", h, openapi.Describe(openapi.Operation{Summary: "API status"}))
+		})
+		r.Host("www.example.com", func(r router.Router[*router.Context]) {
+			r.Get("/", h, openapi.Describe(openapi.Operation{Summary: "Home page"}))
+		})
+
+		document := func(opts ...openapi.Option) map[string]any {
+			data, err := openapi.New(r, opts...).JSON()
+			require.NoError(t, err)
+
+			var doc map[string]any
+			require.NoError(t, json.Unmarshal(data, &doc))
+			return doc
+		}
+
+		assert.Equal(t, []string{"/health"}, keys(lookup(t, document(), "paths").(map[string]any)))
+
+		for range 10 {
+			doc := document(openapi.WithHost("www.example.com"))
+			assert.Equal(t, []string{"/"}, keys(lookup(t, doc, "paths").(map[string]any)))
+			assert.Equal(t, "Home page", lookup(t, doc, "paths", "/", "get", "summary"))
+		}
+
+		doc := document(
+			openapi.WithHost("api.example.com"),
+			openapi.WithOperation(http.MethodGet, "/", openapi.Operation{Summary: "Hostless"}),
+			openapi.WithHostOperation("www.example.com", http.MethodGet, "/", openapi.Operation{Summary: "Other host"}),
+			openapi.WithHostOperation("api.example.com", http.MethodGet, "/", openapi.Operation{Summary: "Status", Response: user{}}),
+		)
+		assert.Equal(t, "Status", lookup(t, doc, "paths", "/", "get", "summary"))
+	})
+}
+
+func TestDocsRoutes(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newSpec(t)
+
+	t.Run("serves the document", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/docs/openapi.json", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+		assert.NotContains(t, lookup(t, doc, "paths"), "/docs/openapi.json")
+		assert.NotContains(t, lookup(t, doc, "paths"), "/docs")
+	})
+
+	t.Run("serves the docs page", func(t *testing.T) {
+		t.Parallel()
+
+		for path, link := range map[string]string{
+			"/docs":  `href="docs/openapi.json"`,
+			"/docs/": `href="openapi.json"`,
+		} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+
+			page := w.Body.String()
+			assert.Contains(t, page, link)
+			assert.Contains(t, page, "<title>Users API</title>")
+			assert.Contains(t, page, "<code>/orgs/{org}/users</code>")
+			assert.Contains(t, page, "Create a user")
+		}
+	})
+}
+
+type routes []router.Route
+
+func (r routes) Routes() []router.Route { return r }
+
+func keys(m map[string]any) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
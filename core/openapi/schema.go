@@ -0,0 +1,357 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	fileHeaderType    = reflect.TypeFor[multipart.FileHeader]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// invalidComponentChars matches characters not allowed in component names.
+var invalidComponentChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// schemaGenerator derives schemas from Go types. Named struct types become
+// components referenced with $ref, so recursive types are supported.
+type schemaGenerator struct {
+	components map[string]*schema
+	names      map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		components: make(map[string]*schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// schemaOf returns the schema of values of type t as encoded by encoding/json.
+func (g *schemaGenerator) schemaOf(t reflect.Type) *schema {
+	t = deref(t)
+
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t == fileHeaderType:
+		return &schema{Type: "string", Format: "binary"}
+	case t == rawMessageType:
+		return &schema{}
+	case t.PkgPath() == "github.com/google/uuid" && t.Name() == "UUID":
+		return &schema{Type: "string", Format: "uuid"}
+	case t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType):
+		return &schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64:
+		return &schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &schema{Type: "integer", Format: "int32"}
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &schema{Type: "integer", Format: "int64", Minimum: ptr(0.0)}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &schema{Type: "integer", Format: "int32", Minimum: ptr(0.0)}
+	case reflect.Float32:
+		return &schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t, nil)
+		}
+		return &schema{Ref: "#/components/schemas/" + g.component(t)}
+	default:
+		// Interfaces and other dynamic values accept anything
+		return &schema{}
+	}
+}
+
+// component registers a named struct type as a component and returns its name.
+func (g *schemaGenerator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	base := invalidComponentChars.ReplaceAllString(t.Name(), "_")
+	name := base
+	if _, taken := g.components[name]; taken {
+		base = invalidComponentChars.ReplaceAllString(path.Base(t.PkgPath()), "_") + "." + base
+		name = base
+		for i := 2; ; i++ {
+			if _, taken := g.components[name]; !taken {
+				break
+			}
+			name = fmt.Sprintf("%s%d", base, i)
+		}
+	}
+
+	// Register before generating properties so recursive types resolve to the same name
+	s := &schema{}
+	g.names[t] = name
+	g.components[name] = s
+	*s = *g.structSchema(t, nil)
+
+	return name
+}
+
+// structSchema returns the object schema of a struct, including only the fields
+// accepted by include when it is not nil.
+func (g *schemaGenerator) structSchema(t reflect.Type, include func(reflect.StructField) bool) *schema {
+	s := &schema{Type: "object", Properties: make(map[string]*schema)}
+	g.addJSONFields(s, t, include)
+	return s
+}
+
+// addJSONFields adds the fields of a struct as encoded by encoding/json,
+// flattening embedded structs.
+func (g *schemaGenerator) addJSONFields(s *schema, t reflect.Type, include func(reflect.StructField) bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if include != nil && !include(f) {
+			continue
+		}
+
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			if ft := deref(f.Type); ft.Kind() == reflect.Struct {
+				g.addJSONFields(s, ft, include)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs, required := g.fieldSchema(f)
+		s.Properties[name] = fs
+		if required {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// fieldSchema returns the schema of a struct field with its validate rules applied
+// and whether the field is required.
+func (g *schemaGenerator) fieldSchema(f reflect.StructField) (*schema, bool) {
+	s := g.schemaOf(f.Type)
+	return s, applyRules(s, f.Type, f.Tag.Get("validate"))
+}
+
+// requestFields is a request struct split by the binders that fill it.
+type requestFields struct {
+	path      map[string]*parameter
+	query     []*parameter
+	form      *schema
+	multipart bool
+	json      *schema
+}
+
+// requestFields splits a request struct into path and query parameters, form fields
+// and JSON fields. Fields without binder tags are part of the JSON body.
+func (g *schemaGenerator) requestFields(t reflect.Type) *requestFields {
+	t = deref(t)
+	rf := &requestFields{path: make(map[string]*parameter)}
+	if t.Kind() != reflect.Struct {
+		rf.json = g.schemaOf(t)
+		return rf
+	}
+
+	rf.form = &schema{Type: "object", Properties: make(map[string]*schema)}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		if name, ok := binderTag(f, "path"); ok {
+			s, _ := g.fieldSchema(f)
+			rf.path[name] = &parameter{Name: name, In: "path", Required: true, Schema: s}
+		}
+		if name, ok := binderTag(f, "query"); ok {
+			s, required := g.fieldSchema(f)
+			rf.query = append(rf.query, &parameter{Name: name, In: "query", Required: required, Schema: s})
+		}
+		for _, tagName := range []string{"form", "file"} {
+			if name, ok := binderTag(f, tagName); ok {
+				s, required := g.fieldSchema(f)
+				rf.form.Properties[name] = s
+				if required {
+					rf.form.Required = append(rf.form.Required, name)
+				}
+				rf.multipart = rf.multipart || tagName == "file"
+			}
+		}
+	}
+
+	rf.json = g.structSchema(t, func(f reflect.StructField) bool {
+		if _, ok := f.Tag.Lookup("json"); ok {
+			return true
+		}
+		for _, tagName := range []string{"path", "query", "form", "file"} {
+			if _, ok := f.Tag.Lookup(tagName); ok {
+				return false
+			}
+		}
+		return true
+	})
+
+	return rf
+}
+
+// body returns the request body of the form and JSON fields, if any.
+func (rf *requestFields) body() *requestBody {
+	content := make(map[string]mediaType)
+	required := false
+
+	if rf.form != nil && len(rf.form.Properties) > 0 {
+		mt := "application/x-www-form-urlencoded"
+		if rf.multipart {
+			mt = "multipart/form-data"
+		}
+		content[mt] = mediaType{Schema: rf.form}
+		required = len(rf.form.Required) > 0
+	}
+	if rf.json != nil && (rf.json.Type != "object" || len(rf.json.Properties) > 0) {
+		content["application/json"] = mediaType{Schema: rf.json}
+		required = required || len(rf.json.Required) > 0
+	}
+
+	if len(content) == 0 {
+		return nil
+	}
+	return &requestBody{Required: required, Content: content}
+}
+
+// binderTag returns the parameter name of an explicit binder tag.
+func binderTag(f reflect.StructField, tagName string) (string, bool) {
+	tag := f.Tag.Get(tagName)
+	if tag == "" || tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
+
+// applyRules maps validate tag rules to schema keywords, following the checks
+// of the validator package, and reports whether the field is required.
+func applyRules(s *schema, t reflect.Type, tag string) bool {
+	t = deref(t)
+	required := false
+
+	for _, rule := range strings.Split(tag, ";") {
+		name, paramStr, _ := strings.Cut(strings.TrimSpace(rule), ":")
+		var params []string
+		if paramStr = strings.TrimSpace(paramStr); paramStr != "" {
+			params = strings.Split(paramStr, ",")
+			for i := range params {
+				params[i] = strings.TrimSpace(params[i])
+			}
+		}
+
+		if name == "required" {
+			required = true
+			continue
+		}
+		// Constraints of referenced schemas belong to the component
+		if s.Ref != "" {
+			continue
+		}
+
+		switch name {
+		case "min", "max":
+			if len(params) > 0 {
+				applyBound(s, t, name == "min", params[0])
+			}
+		case "email":
+			if t.Kind() == reflect.String {
+				s.Format = "email"
+			}
+		case "uuid":
+			if t.Kind() == reflect.String {
+				s.Format = "uuid"
+			}
+		case "in":
+			if t.Kind() == reflect.String && len(params) > 0 {
+				s.Enum = make([]any, len(params))
+				for i, p := range params {
+					s.Enum[i] = p
+				}
+			}
+		}
+	}
+
+	return required
+}
+
+// applyBound sets the length, item count or value bound of a min or max rule.
+func applyBound(s *schema, t reflect.Type, isMin bool, param string) {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array:
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		switch {
+		case t.Kind() == reflect.String && isMin:
+			s.MinLength = &n
+		case t.Kind() == reflect.String:
+			s.MaxLength = &n
+		case isMin:
+			s.MinItems = &n
+		default:
+			s.MaxItems = &n
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		if isMin {
+			s.Minimum = &n
+		} else {
+			s.Maximum = &n
+		}
+	}
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
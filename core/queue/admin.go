@@ -0,0 +1,219 @@
+package queue
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/response"
+	"github.com/dmitrymomot/foundation/core/router"
+)
+
+// TaskList is the response body of the admin task listing endpoint.
+type TaskList struct {
+	Tasks []*Task `json:"tasks"`
+	Total int64   `json:"total"`
+}
+
+// DLQList is the response body of the admin dead letter queue listing endpoint.
+type DLQList struct {
+	Entries []*TasksDlq `json:"entries"`
+}
+
+// AdminRoutes returns a route group exposing the inspector as a JSON admin API.
+// Mount it under a protected prefix; the handlers perform no authorization.
+//
+// Endpoints:
+//   - GET    /tasks                 list tasks (query: queue, status, limit, offset)
+//   - GET    /tasks/{id}            get a task
+//   - POST   /tasks/{id}/cancel     cancel a pending task
+//   - GET    /dlq                   list dead letter queue entries (query: queue, limit, offset)
+//   - POST   /dlq/{id}/requeue      requeue a dead letter queue entry
+//   - DELETE /dlq                   purge dead letter queue entries (query: queue)
+//   - GET    /queues/paused         list paused queues
+//   - POST   /queues/{queue}/pause  pause a queue
+//   - POST   /queues/{queue}/resume resume a queue
+//
+// Example:
+//
+//	r.Route("/admin/queue", queue.AdminRoutes[*router.Context](storage))
+func AdminRoutes[C handler.Context](inspector QueueInspector) func(r router.Router[C]) {
+	return func(r router.Router[C]) {
+		r.Get("/tasks", adminListTasks[C](inspector))
+		r.Get("/tasks/{id}", adminGetTask[C](inspector))
+		r.Post("/tasks/{id}/cancel", adminCancelTask[C](inspector))
+		r.Get("/dlq", adminListDLQ[C](inspector))
+		r.Post("/dlq/{id}/requeue", adminRequeueDLQ[C](inspector))
+		r.Delete("/dlq", adminPurgeDLQ[C](inspector))
+		r.Get("/queues/paused", adminPausedQueues[C](inspector))
+		r.Post("/queues/{queue}/pause", adminPauseQueue[C](inspector))
+		r.Post("/queues/{queue}/resume", adminResumeQueue[C](inspector))
+	}
+}
+
+func adminListTasks[C handler.Context](inspector QueueInspector) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		query := ctx.Request().URL.Query()
+		limit, offset, err := pageParams(query.Get("limit"), query.Get("offset"))
+		if err != nil {
+			return response.Error(response.ErrBadRequest.WithError(err))
+		}
+
+		filter := TaskFilter{
+			Queue:  query.Get("queue"),
+			Status: TaskStatus(query.Get("status")),
+			Limit:  limit,
+			Offset: offset,
+		}
+
+		tasks, err := inspector.ListTasks(ctx, filter)
+		if err != nil {
+			return adminError(err)
+		}
+		total, err := inspector.CountTasks(ctx, filter)
+		if err != nil {
+			return adminError(err)
+		}
+
+		return response.JSON(TaskList{Tasks: tasks, Total: total})
+	}
+}
+
+func adminGetTask[C handler.Context](inspector QueueInspector) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			return response.Error(response.ErrBadRequest.WithMessage("invalid task id"))
+		}
+
+		task, err := inspector.GetTask(ctx, id)
+		if err != nil {
+			return adminError(err)
+		}
+
+		return response.JSON(task)
+	}
+}
+
+func adminCancelTask[C handler.Context](inspector QueueInspector) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			return response.Error(response.ErrBadRequest.WithMessage("invalid task id"))
+		}
+
+		if err := inspector.CancelTask(ctx, id); err != nil {
+			return adminError(err)
+		}
+
+		return response.NoContent()
+	}
+}
+
+func adminListDLQ[C handler.Context](inspector QueueInspector) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		query := ctx.Request().URL.Query()
+		limit, offset, err := pageParams(query.Get("limit"), query.Get("offset"))
+		if err != nil {
+			return response.Error(response.ErrBadRequest.WithError(err))
+		}
+
+		entries, err := inspector.ListDLQ(ctx, DLQFilter{
+			Queue:  query.Get("queue"),
+			Limit:  limit,
+			Offset: offset,
+		})
+		if err != nil {
+			return adminError(err)
+		}
+
+		return response.JSON(DLQList{Entries: entries})
+	}
+}
+
+func adminRequeueDLQ[C handler.Context](inspector QueueInspector) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			return response.Error(response.ErrBadRequest.WithMessage("invalid dead letter queue entry id"))
+		}
+
+		task, err := inspector.RequeueDLQ(ctx, id)
+		if err != nil {
+			return adminError(err)
+		}
+
+		return response.JSON(task)
+	}
+}
+
+func adminPurgeDLQ[C handler.Context](inspector QueueInspector) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		purged, err := inspector.PurgeDLQ(ctx, ctx.Request().URL.Query().Get("queue"))
+		if err != nil {
+			return adminError(err)
+		}
+
+		return response.JSON(map[string]int64{"purged": purged})
+	}
+}
+
+func adminPausedQueues[C handler.Context](inspector QueueInspector) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		queues, err := inspector.PausedQueues(ctx)
+		if err != nil {
+			return adminError(err)
+		}
+
+		return response.JSON(map[string][]string{"queues": queues})
+	}
+}
+
+func adminPauseQueue[C handler.Context](inspector QueueInspector) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		if err := inspector.PauseQueue(ctx, ctx.Param("queue")); err != nil {
+			return adminError(err)
+		}
+
+		return response.NoContent()
+	}
+}
+
+func adminResumeQueue[C handler.Context](inspector QueueInspector) handler.HandlerFunc[C] {
+	return func(ctx C) handler.Response {
+		if err := inspector.ResumeQueue(ctx, ctx.Param("queue")); err != nil {
+			return adminError(err)
+		}
+
+		return response.NoContent()
+	}
+}
+
+// adminError maps inspector errors to HTTP errors.
+func adminError(err error) handler.Response {
+	switch {
+	case errors.Is(err, ErrTaskNotFound), errors.Is(err, ErrDLQEntryNotFound):
+		return response.Error(response.ErrNotFound.WithError(err))
+	case errors.Is(err, ErrTaskNotPending):
+		return response.Error(response.ErrConflict.WithError(err))
+	default:
+		return response.Error(err)
+	}
+}
+
+// pageParams parses optional limit and offset query values.
+func pageParams(limitStr, offsetStr string) (limit, offset int, err error) {
+	if limitStr != "" {
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit < 0 {
+			return 0, 0, errors.New("limit must be a non-negative integer")
+		}
+	}
+	if offsetStr != "" {
+		if offset, err = strconv.Atoi(offsetStr); err != nil || offset < 0 {
+			return 0, 0, errors.New("offset must be a non-negative integer")
+		}
+	}
+	return limit, offset, nil
+}
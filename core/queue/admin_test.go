@@ -0,0 +1,119 @@
+package queue_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+	"github.com/dmitrymomot/foundation/core/router"
+)
+
+func TestAdminRoutes(t *testing.T) {
+	t.Parallel()
+
+	storage := queue.NewMemoryStorage()
+	defer storage.Close()
+	ctx := context.Background()
+
+	r := router.New[*router.Context]()
+	r.Route("/admin/queue", queue.AdminRoutes[*router.Context](storage))
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	task := newInspectorTask("default", time.Now())
+	require.NoError(t, storage.CreateTask(ctx, task))
+
+	t.Run("lists tasks with total", func(t *testing.T) {
+		rec := do(http.MethodGet, "/admin/queue/tasks?queue=default&limit=10")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var list queue.TaskList
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		assert.EqualValues(t, 1, list.Total)
+		require.Len(t, list.Tasks, 1)
+		assert.Equal(t, task.ID, list.Tasks[0].ID)
+	})
+
+	t.Run("rejects invalid paging", func(t *testing.T) {
+		rec := do(http.MethodGet, "/admin/queue/tasks?limit=abc")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("gets task", func(t *testing.T) {
+		rec := do(http.MethodGet, "/admin/queue/tasks/"+task.ID.String())
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var got queue.Task
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+		assert.Equal(t, task.ID, got.ID)
+	})
+
+	t.Run("unknown task is not found", func(t *testing.T) {
+		rec := do(http.MethodGet, "/admin/queue/tasks/"+uuid.NewString())
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = do(http.MethodGet, "/admin/queue/tasks/not-a-uuid")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("cancels pending task once", func(t *testing.T) {
+		rec := do(http.MethodPost, "/admin/queue/tasks/"+task.ID.String()+"/cancel")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = do(http.MethodPost, "/admin/queue/tasks/"+task.ID.String()+"/cancel")
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	})
+
+	t.Run("requeues and purges DLQ entries", func(t *testing.T) {
+		dead := newInspectorTask("dlq", time.Now())
+		require.NoError(t, storage.CreateTask(ctx, dead))
+		_, err := storage.ClaimTask(ctx, uuid.New(), []string{"dlq"}, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, storage.FailTask(ctx, dead.ID, "boom", nil))
+		require.NoError(t, storage.MoveToDLQ(ctx, dead.ID))
+
+		rec := do(http.MethodGet, "/admin/queue/dlq?queue=dlq")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var list queue.DLQList
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+		require.Len(t, list.Entries, 1)
+
+		rec = do(http.MethodPost, "/admin/queue/dlq/"+list.Entries[0].ID.String()+"/requeue")
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = do(http.MethodPost, "/admin/queue/dlq/"+list.Entries[0].ID.String()+"/requeue")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = do(http.MethodDelete, "/admin/queue/dlq")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"purged":0}`, rec.Body.String())
+	})
+
+	t.Run("pauses and resumes queues", func(t *testing.T) {
+		rec := do(http.MethodPost, "/admin/queue/queues/emails/pause")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = do(http.MethodGet, "/admin/queue/queues/paused")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"queues":["emails"]}`, rec.Body.String())
+
+		rec = do(http.MethodPost, "/admin/queue/queues/emails/resume")
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+
+		rec = do(http.MethodGet, "/admin/queue/queues/paused")
+		assert.JSONEq(t, `{"queues":[]}`, rec.Body.String())
+	})
+}
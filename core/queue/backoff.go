@@ -0,0 +1,108 @@
+package queue
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff calculates how long to wait before the next attempt of a failed task.
+// Attempt starts at 1 for the first retry.
+type Backoff interface {
+	Delay(attempt int) time.Duration
+}
+
+// BackoffFunc adapts an ordinary function to the Backoff interface.
+// Custom functions cannot be stored with a task, so they can only be
+// configured per worker or per handler.
+type BackoffFunc func(attempt int) time.Duration
+
+// Delay implements Backoff.
+func (f BackoffFunc) Delay(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// BackoffStrategy identifies a built-in backoff algorithm
+type BackoffStrategy string
+
+const (
+	BackoffFixed       BackoffStrategy = "fixed"
+	BackoffLinear      BackoffStrategy = "linear"
+	BackoffExponential BackoffStrategy = "exponential"
+)
+
+// BackoffPolicy is a serializable Backoff built from one of the built-in strategies.
+// It is persisted with the task when passed to WithBackoff, so every worker
+// applies the same policy regardless of its own configuration.
+type BackoffPolicy struct {
+	Strategy    BackoffStrategy `json:"strategy"`
+	Interval    time.Duration   `json:"interval"`
+	MaxInterval time.Duration   `json:"max_interval,omitempty"` // 0 means no upper bound
+	Jitter      float64         `json:"jitter,omitempty"`       // fraction of the delay (0-1) randomly subtracted
+}
+
+// Delay implements Backoff.
+func (p BackoffPolicy) Delay(attempt int) time.Duration {
+	attempt = max(attempt, 1)
+
+	var d time.Duration
+	switch p.Strategy {
+	case BackoffLinear:
+		d = multiplyDuration(p.Interval, int64(attempt))
+	case BackoffExponential:
+		// Cap the exponent to avoid overflowing int64 nanoseconds
+		d = multiplyDuration(p.Interval, int64(1)<<min(attempt-1, 62))
+	default:
+		d = p.Interval
+	}
+
+	if p.MaxInterval > 0 && d > p.MaxInterval {
+		d = p.MaxInterval
+	}
+
+	// Subtractive jitter spreads retries of tasks that failed together
+	// without ever exceeding MaxInterval
+	if p.Jitter > 0 && d > 0 {
+		jitter := min(p.Jitter, 1)
+		d -= time.Duration(rand.Float64() * jitter * float64(d))
+	}
+
+	return max(d, 0)
+}
+
+// FixedBackoff waits the same interval before every retry.
+func FixedBackoff(interval time.Duration) BackoffPolicy {
+	return BackoffPolicy{Strategy: BackoffFixed, Interval: interval}
+}
+
+// LinearBackoff waits interval multiplied by the attempt number, capped at maxInterval (0 = no cap).
+func LinearBackoff(interval, maxInterval time.Duration) BackoffPolicy {
+	return BackoffPolicy{Strategy: BackoffLinear, Interval: interval, MaxInterval: maxInterval}
+}
+
+// ExponentialBackoff doubles the delay after every attempt starting from interval,
+// capped at maxInterval (0 = no cap). Jitter (0-1) randomly shortens each delay
+// by up to that fraction to avoid retry storms.
+func ExponentialBackoff(interval, maxInterval time.Duration, jitter float64) BackoffPolicy {
+	return BackoffPolicy{
+		Strategy:    BackoffExponential,
+		Interval:    interval,
+		MaxInterval: maxInterval,
+		Jitter:      jitter,
+	}
+}
+
+// defaultBackoff is used when neither the task, its handler nor the worker configure one.
+// Linear progression: 30s, 60s, 90s... balances quick retry with system stability.
+var defaultBackoff Backoff = LinearBackoff(30*time.Second, 0)
+
+// multiplyDuration multiplies d by n, saturating at the maximum duration on overflow.
+func multiplyDuration(d time.Duration, n int64) time.Duration {
+	if d <= 0 || n <= 0 {
+		return 0
+	}
+	if int64(d) > math.MaxInt64/n {
+		return time.Duration(math.MaxInt64)
+	}
+	return d * time.Duration(n)
+}
@@ -0,0 +1,113 @@
+package queue_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+func TestBackoffPolicy_Delay(t *testing.T) {
+	t.Parallel()
+
+	t.Run("fixed", func(t *testing.T) {
+		t.Parallel()
+
+		b := queue.FixedBackoff(10 * time.Second)
+		assert.Equal(t, 10*time.Second, b.Delay(1))
+		assert.Equal(t, 10*time.Second, b.Delay(5))
+	})
+
+	t.Run("linear", func(t *testing.T) {
+		t.Parallel()
+
+		b := queue.LinearBackoff(30*time.Second, 0)
+		assert.Equal(t, 30*time.Second, b.Delay(1))
+		assert.Equal(t, 60*time.Second, b.Delay(2))
+		assert.Equal(t, 90*time.Second, b.Delay(3))
+	})
+
+	t.Run("linear with cap", func(t *testing.T) {
+		t.Parallel()
+
+		b := queue.LinearBackoff(30*time.Second, time.Minute)
+		assert.Equal(t, time.Minute, b.Delay(5))
+	})
+
+	t.Run("exponential", func(t *testing.T) {
+		t.Parallel()
+
+		b := queue.ExponentialBackoff(time.Second, 0, 0)
+		assert.Equal(t, time.Second, b.Delay(1))
+		assert.Equal(t, 2*time.Second, b.Delay(2))
+		assert.Equal(t, 4*time.Second, b.Delay(3))
+		assert.Equal(t, 8*time.Second, b.Delay(4))
+	})
+
+	t.Run("exponential with cap does not overflow", func(t *testing.T) {
+		t.Parallel()
+
+		b := queue.ExponentialBackoff(time.Second, time.Hour, 0)
+		assert.Equal(t, time.Hour, b.Delay(100))
+	})
+
+	t.Run("exponential with jitter stays within bounds", func(t *testing.T) {
+		t.Parallel()
+
+		b := queue.ExponentialBackoff(time.Second, 0, 0.5)
+		for range 100 {
+			d := b.Delay(3)
+			assert.GreaterOrEqual(t, d, 2*time.Second)
+			assert.LessOrEqual(t, d, 4*time.Second)
+		}
+	})
+
+	t.Run("attempt below one is treated as first attempt", func(t *testing.T) {
+		t.Parallel()
+
+		b := queue.LinearBackoff(time.Second, 0)
+		assert.Equal(t, time.Second, b.Delay(0))
+	})
+}
+
+func TestBackoffFunc(t *testing.T) {
+	t.Parallel()
+
+	b := queue.BackoffFunc(func(attempt int) time.Duration {
+		return time.Duration(attempt) * time.Millisecond
+	})
+	assert.Equal(t, 3*time.Millisecond, b.Delay(3))
+}
+
+func TestRetryErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no retry matches sentinel and keeps cause", func(t *testing.T) {
+		t.Parallel()
+
+		cause := errors.New("invalid input")
+		err := queue.NoRetry(cause)
+		assert.ErrorIs(t, err, queue.ErrSkipRetry)
+		assert.ErrorIs(t, err, cause)
+		assert.Equal(t, "invalid input", err.Error())
+	})
+
+	t.Run("no retry with nil error", func(t *testing.T) {
+		t.Parallel()
+
+		assert.ErrorIs(t, queue.NoRetry(nil), queue.ErrSkipRetry)
+	})
+
+	t.Run("retry after keeps cause", func(t *testing.T) {
+		t.Parallel()
+
+		cause := errors.New("rate limited")
+		err := queue.RetryAfter(cause, time.Minute)
+		assert.ErrorIs(t, err, cause)
+		assert.NotErrorIs(t, err, queue.ErrSkipRetry)
+		assert.Equal(t, "rate limited", err.Error())
+	})
+}
@@ -0,0 +1,361 @@
+package queue
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronSearchYears bounds the search for the next matching time, so expressions
+// that can never match (e.g. "0 0 30 2 *") do not loop forever.
+const cronSearchYears = 5
+
+// cronMacros maps named schedules to their cron expressions
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var (
+	monthNames = map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}
+	weekdayNames = map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}
+)
+
+// cronField describes the valid range and names of a cron field
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	secondField  = cronField{name: "second", min: 0, max: 59}
+	minuteField  = cronField{name: "minute", min: 0, max: 59}
+	hourField    = cronField{name: "hour", min: 0, max: 23}
+	domField     = cronField{name: "day of month", min: 1, max: 31}
+	monthField   = cronField{name: "month", min: 1, max: 12, names: monthNames}
+	weekdayField = cronField{name: "day of week", min: 0, max: 7, names: weekdayNames}
+)
+
+// cronSchedule is a parsed cron expression evaluated in a fixed location.
+// Each field is a bitset of allowed values.
+type cronSchedule struct {
+	expr     string
+	second   uint64
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	anyDom   bool
+	anyDow   bool
+	location *time.Location
+}
+
+// ParseCron parses a standard cron expression into a Schedule evaluated in the local time zone.
+//
+// Supported formats:
+//   - 5 fields: minute hour day-of-month month day-of-week
+//   - 6 fields: second minute hour day-of-month month day-of-week
+//   - macros: @yearly, @annually, @monthly, @weekly, @daily, @midnight, @hourly
+//   - @every <duration>, e.g. "@every 90s"
+//
+// Fields accept *, ?, lists (1,2), ranges (1-5), steps (*/15, 8-18/2), month
+// names (JAN-DEC) and weekday names (SUN-SAT); 7 is also Sunday. When both day
+// fields are restricted, a day matching either of them matches, as in Vixie cron.
+//
+// A "CRON_TZ=Europe/Berlin " or "TZ=Europe/Berlin " prefix sets the time zone.
+func ParseCron(expr string) (Schedule, error) {
+	return ParseCronInLocation(expr, time.Local)
+}
+
+// ParseCronInLocation parses a cron expression evaluated in the given location.
+// A CRON_TZ or TZ prefix in the expression takes precedence over loc.
+//
+// Wall clock times skipped by a daylight saving transition are shifted forward by
+// the length of the gap, so 02:30 on a day clocks jump from 02:00 to 03:00 runs at
+// 03:30; times repeated when clocks go back run only once.
+func ParseCronInLocation(expr string, loc *time.Location) (Schedule, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	spec := strings.TrimSpace(expr)
+	if rest, ok := cutTimeZone(spec); ok {
+		tz, remainder, _ := strings.Cut(rest, " ")
+		l, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown time zone %q: %v", ErrInvalidSchedule, tz, err)
+		}
+		loc = l
+		spec = strings.TrimSpace(remainder)
+	}
+
+	if strings.HasPrefix(spec, "@every ") {
+		d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(spec, "@every ")))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("%w: invalid @every duration in %q", ErrInvalidSchedule, expr)
+		}
+		return EveryInterval(d), nil
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		macro, ok := cronMacros[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown macro %q", ErrInvalidSchedule, spec)
+		}
+		spec = macro
+	}
+
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w: expected 5 or 6 fields in %q, got %d", ErrInvalidSchedule, expr, len(fields))
+	}
+
+	s := &cronSchedule{expr: strings.TrimSpace(expr), location: loc}
+	var err error
+	if s.second, err = parseCronField(fields[0], secondField); err != nil {
+		return nil, err
+	}
+	if s.minute, err = parseCronField(fields[1], minuteField); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(fields[2], hourField); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(fields[3], domField); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(fields[4], monthField); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(fields[5], weekdayField); err != nil {
+		return nil, err
+	}
+
+	// Sunday may be written as 0 or 7
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.anyDom = isWildcard(fields[3])
+	s.anyDow = isWildcard(fields[5])
+
+	return s, nil
+}
+
+// MustParseCron is like ParseCron but panics on an invalid expression.
+// Intended for package level schedule definitions.
+func MustParseCron(expr string) Schedule {
+	s, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Next returns the first matching time strictly after from, in the schedule's location.
+// Returns the zero time when nothing matches within the next five years.
+func (s *cronSchedule) Next(from time.Time) time.Time {
+	from = from.In(s.location)
+
+	// Walk wall clock time in a zone without transitions, then map candidates back
+	// to the schedule location. This keeps field arithmetic simple across DST changes.
+	w := time.Date(from.Year(), from.Month(), from.Day(), from.Hour(), from.Minute(), from.Second(), 0, time.UTC).
+		Add(time.Second)
+	limit := w.AddDate(cronSearchYears, 0, 0)
+
+	for w.Before(limit) {
+		if s.month&(1<<uint(w.Month())) == 0 {
+			w = time.Date(w.Year(), w.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(w) {
+			w = time.Date(w.Year(), w.Month(), w.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(w.Hour())) == 0 {
+			w = w.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if s.minute&(1<<uint(w.Minute())) == 0 {
+			w = w.Truncate(time.Minute).Add(time.Minute)
+			continue
+		}
+		if s.second&(1<<uint(w.Second())) == 0 {
+			w = w.Add(time.Second)
+			continue
+		}
+
+		// Repeated wall times may resolve to an instant not after from and are skipped.
+		next := resolveWallTime(w, s.location)
+		if next.After(from) {
+			return next
+		}
+		w = w.Add(time.Second)
+	}
+
+	return time.Time{}
+}
+
+// resolveWallTime maps a wall clock time, held in UTC, to an instant in loc.
+// time.Date normalizes a time skipped by a daylight saving gap in either direction
+// depending on the zone, so such times are shifted forward by the gap here.
+func resolveWallTime(w time.Time, loc *time.Location) time.Time {
+	t := time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), w.Second(), 0, loc)
+	if time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC).Equal(w) {
+		return t
+	}
+
+	// Read the wall time with the offsets on both sides of the gap; the later
+	// instant uses the offset from before the gap.
+	_, offset := t.Zone()
+	a := w.Add(-time.Duration(offset) * time.Second).In(loc)
+	_, offset = a.Zone()
+	b := w.Add(-time.Duration(offset) * time.Second).In(loc)
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func (s *cronSchedule) String() string {
+	return "cron " + s.expr
+}
+
+// dayMatches applies Vixie cron semantics: when both day fields are restricted,
+// either one matching is enough.
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.anyDom || s.anyDow {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// InLocation evaluates a schedule in the given location, e.g. DailyAt(9, 0) in Europe/Berlin.
+// Schedules computing wall clock times (daily, weekly, monthly, hourly) follow the
+// location's daylight saving transitions.
+func InLocation(schedule Schedule, loc *time.Location) Schedule {
+	if loc == nil {
+		return schedule
+	}
+	return locationSchedule{schedule: schedule, location: loc}
+}
+
+// locationSchedule converts the reference time to a location before delegating
+type locationSchedule struct {
+	schedule Schedule
+	location *time.Location
+}
+
+func (s locationSchedule) Next(from time.Time) time.Time {
+	return s.schedule.Next(from.In(s.location))
+}
+
+func (s locationSchedule) String() string {
+	return fmt.Sprintf("%s in %s", s.schedule, s.location)
+}
+
+// cutTimeZone strips a CRON_TZ= or TZ= prefix
+func cutTimeZone(spec string) (string, bool) {
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if rest, ok := strings.CutPrefix(spec, prefix); ok {
+			return rest, true
+		}
+	}
+	return "", false
+}
+
+// isWildcard reports whether a day field starts with * or ?, which Vixie cron
+// treats as unrestricted when combining day of month and day of week
+func isWildcard(field string) bool {
+	return strings.HasPrefix(field, "*") || strings.HasPrefix(field, "?")
+}
+
+// parseCronField parses a comma separated cron field into a bitset
+func parseCronField(field string, f cronField) (uint64, error) {
+	var set uint64
+	for part := range strings.SplitSeq(field, ",") {
+		bitsPart, err := parseCronPart(part, f)
+		if err != nil {
+			return 0, err
+		}
+		set |= bitsPart
+	}
+	return set, nil
+}
+
+// parseCronPart parses a single value, range or step expression
+func parseCronPart(part string, f cronField) (uint64, error) {
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	lo, hi := f.min, f.max
+	switch {
+	case rangePart == "*" || rangePart == "?":
+	default:
+		start, end, isRange := strings.Cut(rangePart, "-")
+		var err error
+		if lo, err = parseCronValue(start, f); err != nil {
+			return 0, err
+		}
+		hi = lo
+		if isRange {
+			if hi, err = parseCronValue(end, f); err != nil {
+				return 0, err
+			}
+		} else if hasStep {
+			// "a/n" means from a to the field maximum
+			hi = f.max
+		}
+	}
+
+	if lo > hi {
+		return 0, fmt.Errorf("%w: %s range %q is reversed", ErrInvalidSchedule, f.name, part)
+	}
+
+	step := 1
+	if hasStep {
+		var err error
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step <= 0 {
+			return 0, fmt.Errorf("%w: invalid %s step %q", ErrInvalidSchedule, f.name, part)
+		}
+	}
+
+	var set uint64
+	for v := lo; v <= hi; v += step {
+		set |= 1 << uint(v)
+	}
+	return set, nil
+}
+
+// parseCronValue parses a number or name within the field range
+func parseCronValue(value string, f cronField) (int, error) {
+	if n, ok := f.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid %s value %q", ErrInvalidSchedule, f.name, value)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("%w: %s value %d out of range %d-%d", ErrInvalidSchedule, f.name, n, f.min, f.max)
+	}
+	return n, nil
+}
@@ -0,0 +1,224 @@
+package queue_test
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+func TestParseCron(t *testing.T) {
+	t.Parallel()
+
+	utc := func(s string) time.Time {
+		tm, err := time.Parse(time.DateTime, s)
+		require.NoError(t, err)
+		return tm
+	}
+
+	tests := []struct {
+		name string
+		expr string
+		from string
+		want string
+	}{
+		{"every minute", "* * * * *", "2025-01-01 10:00:30", "2025-01-01 10:01:00"},
+		{"every 15 minutes in business hours", "*/15 8-18 * * *", "2025-01-01 18:50:00", "2025-01-02 08:00:00"},
+		{"every 15 minutes next slot", "*/15 8-18 * * *", "2025-01-01 09:16:00", "2025-01-01 09:30:00"},
+		{"weekdays at nine", "0 9 * * MON-FRI", "2025-01-03 09:00:00", "2025-01-06 09:00:00"},
+		{"list of hours", "30 6,12,18 * * *", "2025-01-01 12:30:00", "2025-01-01 18:30:00"},
+		{"range with step", "0 0-12/6 * * *", "2025-01-01 07:00:00", "2025-01-01 12:00:00"},
+		{"start with step", "0 10/5 * * *", "2025-01-01 16:00:00", "2025-01-01 20:00:00"},
+		{"month names", "0 0 1 jan,jul *", "2025-02-01 00:00:00", "2025-07-01 00:00:00"},
+		{"sunday as seven", "0 0 * * 7", "2025-01-01 00:00:00", "2025-01-05 00:00:00"},
+		{"day of month or weekday", "0 0 13 * FRI", "2025-01-01 00:00:00", "2025-01-03 00:00:00"},
+		{"day of month with wildcard weekday", "0 0 13 * *", "2025-01-01 00:00:00", "2025-01-13 00:00:00"},
+		{"six fields with seconds", "*/10 * * * * *", "2025-01-01 00:00:05", "2025-01-01 00:00:10"},
+		{"leap day", "0 0 29 2 *", "2025-01-01 00:00:00", "2028-02-29 00:00:00"},
+		{"hourly macro", "@hourly", "2025-01-01 10:20:00", "2025-01-01 11:00:00"},
+		{"daily macro", "@daily", "2025-01-01 10:20:00", "2025-01-02 00:00:00"},
+		{"weekly macro", "@weekly", "2025-01-01 10:20:00", "2025-01-05 00:00:00"},
+		{"monthly macro", "@monthly", "2025-01-15 00:00:00", "2025-02-01 00:00:00"},
+		{"yearly macro", "@yearly", "2025-01-15 00:00:00", "2026-01-01 00:00:00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := queue.ParseCronInLocation(tt.expr, time.UTC)
+			require.NoError(t, err)
+			assert.Equal(t, utc(tt.want), s.Next(utc(tt.from)))
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+		"@fortnightly",
+		"@every nope",
+		"CRON_TZ=Mars/Olympus 0 9 * * *",
+	} {
+		_, err := queue.ParseCron(expr)
+		assert.ErrorIs(t, err, queue.ErrInvalidSchedule, "expression %q", expr)
+	}
+}
+
+func TestParseCron_Every(t *testing.T) {
+	t.Parallel()
+
+	s, err := queue.ParseCron("@every 90s")
+	require.NoError(t, err)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, from.Add(90*time.Second), s.Next(from))
+}
+
+func TestParseCron_NeverMatches(t *testing.T) {
+	t.Parallel()
+
+	s, err := queue.ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(time.Now()).IsZero())
+}
+
+func TestParseCron_Location(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	t.Run("evaluates in the given location", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := queue.ParseCronInLocation("0 9 * * MON-FRI", berlin)
+		require.NoError(t, err)
+
+		// Friday 08:30 UTC is 09:30 in Berlin, so the next run is Monday 09:00 Berlin time
+		next := s.Next(time.Date(2025, 1, 3, 8, 30, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2025, 1, 6, 9, 0, 0, 0, berlin), next)
+		assert.Equal(t, berlin, next.Location())
+	})
+
+	t.Run("time zone prefix", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := queue.ParseCron("CRON_TZ=Europe/Berlin 0 9 * * *")
+		require.NoError(t, err)
+
+		next := s.Next(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("skipped wall time is shifted by the gap", func(t *testing.T) {
+		t.Parallel()
+
+		// Clocks jump from 02:00 to 03:00 on 2025-03-30 in Berlin
+		s, err := queue.ParseCronInLocation("30 2 * * *", berlin)
+		require.NoError(t, err)
+
+		next := s.Next(time.Date(2025, 3, 30, 0, 0, 0, 0, berlin))
+		assert.Equal(t, time.Date(2025, 3, 30, 1, 30, 0, 0, time.UTC), next.UTC())
+
+		next = s.Next(next)
+		assert.Equal(t, time.Date(2025, 3, 31, 2, 30, 0, 0, berlin), next)
+	})
+
+	t.Run("skipped wall time in New York", func(t *testing.T) {
+		t.Parallel()
+
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+
+		// Clocks jump from 02:00 EST to 03:00 EDT on 2025-03-09 in New York
+		s, err := queue.ParseCronInLocation("45 2 * * *", newYork)
+		require.NoError(t, err)
+
+		next := s.Next(time.Date(2025, 3, 9, 0, 0, 0, 0, newYork))
+		assert.Equal(t, time.Date(2025, 3, 9, 3, 45, 0, 0, newYork), next)
+		assert.Equal(t, time.Date(2025, 3, 9, 7, 45, 0, 0, time.UTC), next.UTC())
+
+		next = s.Next(next)
+		assert.Equal(t, time.Date(2025, 3, 10, 2, 45, 0, 0, newYork), next)
+	})
+
+	t.Run("frequent schedule across spring forward", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := queue.ParseCronInLocation("0 * * * *", berlin)
+		require.NoError(t, err)
+
+		next := s.Next(time.Date(2025, 3, 30, 1, 0, 0, 0, berlin))
+		assert.Equal(t, time.Date(2025, 3, 30, 3, 0, 0, 0, berlin), next)
+		assert.Equal(t, time.Hour, next.Sub(time.Date(2025, 3, 30, 1, 0, 0, 0, berlin)))
+	})
+
+	t.Run("repeated wall time runs once", func(t *testing.T) {
+		t.Parallel()
+
+		// Clocks go back from 03:00 to 02:00 on 2025-10-26 in Berlin
+		s, err := queue.ParseCronInLocation("30 2 * * *", berlin)
+		require.NoError(t, err)
+
+		first := s.Next(time.Date(2025, 10, 26, 0, 0, 0, 0, berlin))
+		assert.Equal(t, 2, first.Hour())
+		assert.Equal(t, 30, first.Minute())
+
+		next := s.Next(first)
+		assert.Equal(t, time.Date(2025, 10, 27, 2, 30, 0, 0, berlin), next)
+
+		// Asking again from inside the repeated hour does not fire a second time that day
+		next = s.Next(time.Date(2025, 10, 26, 1, 45, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2025, 10, 27, 2, 30, 0, 0, berlin), next)
+	})
+
+	t.Run("hourly schedule across fall back", func(t *testing.T) {
+		t.Parallel()
+
+		s, err := queue.ParseCronInLocation("0 * * * *", berlin)
+		require.NoError(t, err)
+
+		// 02:00 CEST is 00:00 UTC; the next distinct wall hour is 03:00 CET
+		next := s.Next(time.Date(2025, 10, 26, 0, 0, 0, 0, time.UTC))
+		assert.Equal(t, time.Date(2025, 10, 26, 3, 0, 0, 0, berlin), next)
+	})
+}
+
+func TestMustParseCron(t *testing.T) {
+	t.Parallel()
+
+	assert.NotPanics(t, func() { queue.MustParseCron("@daily") })
+	assert.Panics(t, func() { queue.MustParseCron("not a cron") })
+	assert.Equal(t, "cron 0 9 * * *", queue.MustParseCron("0 9 * * *").String())
+}
+
+func TestInLocation(t *testing.T) {
+	t.Parallel()
+
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	s := queue.InLocation(queue.DailyAt(9, 0), berlin)
+	next := s.Next(time.Date(2025, 7, 1, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, time.Date(2025, 7, 2, 9, 0, 0, 0, berlin), next)
+	assert.Equal(t, "daily at 09:00 in Europe/Berlin", s.String())
+
+	assert.Equal(t, queue.Daily(), queue.InLocation(queue.Daily(), nil))
+}
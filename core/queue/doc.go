@@ -6,13 +6,18 @@
 //
 //   - Task enqueueing with priority support
 //   - Background workers with concurrent processing
+//   - Per-queue and per-handler concurrency and rate limits
+//   - Weighted and strict ordering across queues
 //   - Scheduled task execution with flexible scheduling options
-//   - Configurable retry policies with exponential backoff
+//   - Configurable retry backoff per task, handler or worker
 //   - In-memory storage for testing and development
 //   - Extensible repository interface for custom storage backends
-//   - Graceful shutdown with proper cleanup
+//   - Graceful shutdown that releases unfinished tasks after a drain timeout
 //   - Type-safe task handlers using Go generics
+//   - Handler middleware for logging, panic recovery, timeouts and more
 //   - Dead letter queue for failed tasks
+//   - Metrics hooks with a dependency-free Prometheus exporter
+//   - Payload encryption with per-workspace keys and key rotation
 //
 // # Basic Usage
 //
@@ -56,7 +61,7 @@
 //	go worker.Start(ctx)
 //
 //	// Enqueue tasks
-//	err = enqueuer.Enqueue(ctx, EmailPayload{
+//	taskID, err := enqueuer.Enqueue(ctx, EmailPayload{
 //		To:      "user@example.com",
 //		Subject: "Welcome!",
 //		Body:    "Welcome to our service!",
@@ -91,6 +96,25 @@
 //	// Register handlers
 //	worker.RegisterHandlers(emailHandler, imageHandler)
 //
+// # Handler Middleware
+//
+// HandlerMiddleware wraps task execution for cross-cutting concerns. Worker middleware
+// applies to every handler and runs outside middleware registered on a single handler:
+//
+//	worker, _ := queue.NewWorker(storage,
+//		queue.WithWorkerMiddleware(
+//			queue.LoggingMiddleware(logger),
+//			queue.RecoveryMiddleware(),
+//		),
+//	)
+//
+//	reportHandler := queue.NewTaskHandler(generateReport,
+//		queue.WithHandlerMiddleware(queue.TimeoutMiddleware(30*time.Second)),
+//	)
+//
+// Built-in middleware covers structured logging, panic-to-error conversion and per-task
+// timeouts; custom middleware receives the task, so it can add tracing or metrics.
+//
 // # Priority-Based Processing
 //
 // Use different priority levels for task processing:
@@ -139,6 +163,28 @@
 //	// Start scheduler
 //	go scheduler.Start(ctx)
 //
+// Cron expressions (5 or 6 fields, macros like @hourly and @daily) are parsed with ParseCron.
+// Schedules can be evaluated in a specific time zone and follow its daylight saving changes:
+//
+//	berlin, _ := time.LoadLocation("Europe/Berlin")
+//	weekdays, err := queue.ParseCronInLocation("0 9 * * MON-FRI", berlin)
+//	scheduler.AddTask("standup_reminder", weekdays)
+//
+//	// Every 15 minutes between 08:00 and 18:59, time zone set in the expression
+//	scheduler.AddTask("sync", queue.MustParseCron("CRON_TZ=Europe/Berlin */15 8-18 * * *"))
+//
+//	// Built-in schedules in a time zone
+//	scheduler.AddTask("daily_report", queue.InLocation(queue.DailyAt(9, 0), berlin))
+//
+// When every replica runs a scheduler, a LeaderLock makes sure only one of them creates
+// periodic tasks. The lock is renewed on every check; another replica takes over when
+// the leader stops or fails to renew it:
+//
+//	scheduler, err := queue.NewScheduler(storage,
+//		queue.WithCheckInterval(10*time.Second),
+//		queue.WithLeaderLock(pg.NewSchedulerLock(pool, "default")),
+//	)
+//
 // # Retry Mechanisms
 //
 // Configure retry policies for failed tasks:
@@ -152,12 +198,41 @@
 //	handler := queue.NewTaskHandler(func(ctx context.Context, data ProcessingPayload) error {
 //		err := performOperation(data)
 //		if err != nil {
-//			// Return error to trigger retry with exponential backoff
+//			// Return error to trigger retry with the configured backoff
 //			return fmt.Errorf("operation failed: %w", err)
 //		}
 //		return nil
 //	})
 //
+// The worker decides when a failed task runs again; storage backends only persist
+// the decision. The delay comes from the first configured source:
+//
+//	// 1. Per task, stored with the task so every worker applies it
+//	enqueuer.Enqueue(ctx, payload,
+//		queue.WithBackoff(queue.ExponentialBackoff(time.Second, time.Hour, 0.2)),
+//	)
+//
+//	// 2. Per handler, including custom functions
+//	handler := queue.NewTaskHandler(processPayload,
+//		queue.WithHandlerBackoff(queue.BackoffFunc(func(attempt int) time.Duration {
+//			return time.Duration(attempt*attempt) * time.Second
+//		})),
+//	)
+//
+//	// 3. Per worker (defaults to linear 30s, 60s, 90s...)
+//	worker, _ := queue.NewWorker(storage, queue.WithWorkerBackoff(queue.FixedBackoff(time.Minute)))
+//
+// Handlers can override the decision for a single failure:
+//
+//	handler := queue.NewTaskHandler(func(ctx context.Context, data ProcessingPayload) error {
+//		if err := callAPI(data); errors.Is(err, errRateLimited) {
+//			return queue.RetryAfter(err, time.Minute) // retry in exactly one minute
+//		} else if errors.Is(err, errInvalidInput) {
+//			return queue.NoRetry(err) // straight to the dead letter queue
+//		}
+//		return nil
+//	})
+//
 // # Multiple Queues
 //
 // Set up different queues for different task types:
@@ -190,6 +265,22 @@
 //	go imageWorker.Start(ctx)
 //	go analyticsWorker.Start(ctx)
 //
+// A worker pulling from several queues claims the highest priority task across all of
+// them, so a busy queue of high priority tasks can starve the others. Weighted queues
+// share the worker in proportion to their weights instead; strict queues are drained
+// in order. Priority still orders tasks within a queue:
+//
+//	worker, _ := queue.NewWorker(storage,
+//		queue.WithWeightedQueues(map[string]int{"critical": 6, "default": 3, "low": 1}),
+//	)
+//
+//	worker, _ := queue.NewWorker(storage,
+//		queue.WithStrictQueues("critical", "default", "low"),
+//	)
+//
+// Repositories implementing OrderedClaimRepository claim by queue order in one call;
+// others are asked one queue at a time.
+//
 // # Error Handling and Dead Letter Queue
 //
 // Failed tasks are automatically handled with retries and dead letter queue:
@@ -212,6 +303,37 @@
 //	// Tasks that exceed max retries are moved to dead letter queue
 //	// Failed tasks can be inspected via the storage interface
 //
+// # Inspection and Administration
+//
+// MemoryStorage and the persistent backends implement QueueInspector to list and count
+// tasks, cancel pending tasks, requeue or purge dead letter queue entries, and pause
+// or resume queues. Paused queues still accept new tasks but workers skip them:
+//
+//	entries, _ := storage.ListDLQ(ctx, queue.DLQFilter{Queue: "emails"})
+//	for _, entry := range entries {
+//		_, _ = storage.RequeueDLQ(ctx, entry.ID)
+//	}
+//
+// AdminRoutes exposes the inspector as a JSON API; protect it with authentication middleware:
+//
+//	r.Route("/admin/queue", queue.AdminRoutes[*router.Context](storage))
+//
+// # Metrics
+//
+// The enqueuer, worker and scheduler report task lifecycle events to a Metrics
+// implementation: enqueued, started with its queue wait time, succeeded or failed with
+// the handler duration, and moved to the dead letter queue. PrometheusMetrics collects
+// them as counters and histograms and serves them in the Prometheus text format together
+// with per-queue depth gauges, without a dependency on the Prometheus client:
+//
+//	metrics := queue.NewPrometheusMetrics(queue.WithQueueDepth(storage, "default", "emails"))
+//
+//	enqueuer, _ := queue.NewEnqueuer(storage, queue.WithEnqueuerMetrics(metrics))
+//	worker, _ := queue.NewWorker(storage, queue.WithWorkerMetrics(metrics))
+//	scheduler, _ := queue.NewScheduler(storage, queue.WithSchedulerMetrics(metrics))
+//
+//	http.Handle("/metrics", metrics)
+//
 // # Graceful Shutdown
 //
 // Implement proper shutdown procedures:
@@ -239,9 +361,68 @@
 //		return nil
 //	}
 //
+// Stop waits for running tasks indefinitely by default. With a drain timeout, handlers
+// still running when it passes get their context cancelled and their tasks go back to
+// pending through ReleaseRepository, without counting a retry, so another worker picks
+// them up immediately instead of after the lock expires:
+//
+//	worker, _ := queue.NewWorker(storage,
+//		queue.WithDrainTimeout(30*time.Second),
+//	)
+//
+// # Long-Running Tasks
+//
+// While a handler runs, the worker extends the task lock on every heartbeat, so a task
+// that takes longer than the lock timeout is not reclaimed by another worker. The handler
+// context is cancelled once the task exceeds its maximum duration, and the failure is
+// recorded with ErrTaskTimeout so timeouts stand out from handler errors:
+//
+//	worker, _ := queue.NewWorker(storage,
+//		queue.WithLockTimeout(time.Minute),       // lock deadline, renewed while running
+//		queue.WithHeartbeatInterval(20*time.Second),
+//		queue.WithTaskTimeout(10*time.Minute),    // default maximum duration
+//	)
+//
+//	exportHandler := queue.NewTaskHandler(exportData,
+//		queue.WithHandlerTimeout(2*time.Hour),
+//	)
+//
+// Handlers must honor ctx.Done() for the timeout to stop their work.
+//
+// # Concurrency and Rate Limits
+//
+// WithMaxConcurrentTasks caps the worker as a whole. Per-queue and per-handler limits
+// keep one busy queue or task type from taking every slot, and token buckets from
+// pkg/ratelimiter cap throughput, e.g. for a third-party API:
+//
+//	bucket, _ := ratelimiter.NewBucket(store, ratelimiter.Config{
+//		Capacity:       10,
+//		RefillRate:     10,
+//		RefillInterval: time.Second,
+//	})
+//
+//	worker, _ := queue.NewWorker(storage,
+//		queue.WithQueues("emails", "billing"),
+//		queue.WithMaxConcurrentTasks(10),
+//		queue.WithQueueConcurrency("emails", 4),
+//		queue.WithQueueRateLimit("emails", bucket),
+//	)
+//
+//	geocodeHandler := queue.NewTaskHandler(geocode,
+//		queue.WithHandlerConcurrency(2),
+//		queue.WithHandlerRateLimit(geocodeBucket),
+//	)
+//
+// The worker stops claiming from queues at their limit. Throttled tasks return to pending
+// without counting a retry, which requires a repository implementing ReleaseRepository.
+// Concurrency limits apply per worker; rate limits are shared by all workers using a
+// bucket with the same store.
+//
 // # Custom Storage Backend
 //
-// Implement custom storage for production use by satisfying the repository interfaces:
+// PostgreSQL and Redis implementations are available as pg.QueueStorage
+// (integration/database/pg) and redis.QueueStorage (integration/database/redis).
+// Implement custom storage for other backends by satisfying the repository interfaces:
 //
 //	type PostgreSQLStorage struct {
 //		db *sql.DB
@@ -279,6 +460,99 @@
 //		queue.WithScheduledAt(time.Date(2024, 12, 25, 9, 0, 0, 0, time.UTC)),
 //	)
 //
+// # Unique Tasks
+//
+// Deduplicate tasks by key, e.g. to ignore double submissions. While a task with the key
+// is pending or processing (and within the optional TTL), new tasks with the same key are
+// skipped by default, or replace the pending task, or fail with ErrDuplicateTask:
+//
+//	enqueuer.Enqueue(ctx, SendInvoice{OrderID: id},
+//		queue.WithUniqueKey("invoice:"+id, 10*time.Minute),
+//		queue.WithUniqueConflict(queue.UniqueConflictReplace),
+//	)
+//
+// The repository enforces uniqueness atomically and must implement UniqueTaskRepository;
+// MemoryStorage and the PostgreSQL and Redis backends do.
+//
+// # Batches and Chains
+//
+// A group enqueues tasks as a batch. Once every task has completed or been moved to the
+// dead letter queue, the OnComplete task is created if all succeeded, OnFailure otherwise:
+//
+//	group := queue.NewGroup()
+//	for _, id := range imageIDs {
+//		group.Add(ResizeImage{ID: id})
+//	}
+//	group.OnComplete(NotifyAlbumReady{BatchID: group.ID()})
+//	group.OnFailure(ReportResizeFailure{BatchID: group.ID()})
+//	err := enqueuer.EnqueueGroup(ctx, group)
+//
+//	batch, _ := enqueuer.GetBatch(ctx, group.ID())
+//	fmt.Printf("%d of %d done\n", batch.Succeeded+batch.Failed, batch.Total)
+//
+// A chain runs steps one after another, passing each step's result as the payload of the
+// next step. Steps returning a result are registered with NewTaskResultHandler:
+//
+//	chain := queue.NewChain(FetchReport{ID: id}).
+//		Then(queue.Step[RenderReport]()).
+//		Then(queue.Step[EmailReport](queue.WithQueue("emails")))
+//	_, err := enqueuer.EnqueueChain(ctx, chain)
+//
+//	worker.RegisterHandlers(
+//		queue.NewTaskResultHandler(func(ctx context.Context, p FetchReport) (RenderReport, error) {
+//			return RenderReport{Rows: rows}, nil
+//		}),
+//	)
+//
+// A failed step stops the chain. Both features require a repository implementing
+// WorkflowRepository; MemoryStorage and the PostgreSQL and Redis backends do.
+//
+// # Encrypted Payloads
+//
+// Payloads carrying personal data can be encrypted with pkg/secrets before they are
+// stored, combining an app key with a per-tenant workspace key. The worker decrypts them
+// right before the handler runs; stored tasks and dead letter queue entries keep the
+// ciphertext:
+//
+//	cipher, _ := queue.NewPayloadCipher("2025-09", appKey,
+//		queue.WithPreviousAppKey("2025-03", oldAppKey),
+//		queue.WithWorkspaceKeys(workspaceKeys),
+//	)
+//	enqueuer, _ := queue.NewEnqueuer(storage, queue.WithEnqueuerEncryption(cipher))
+//	worker, _ := queue.NewWorker(storage, queue.WithWorkerEncryption(cipher))
+//
+//	_, err := enqueuer.Enqueue(ctx, SendInvite{Email: email}, queue.WithWorkspace(workspaceID))
+//
+// Encrypted payloads record the app key ID and the workspace ID. New payloads use the
+// current app key and the first workspace key, while previous keys still decrypt tasks
+// stored before a rotation. Plaintext tasks are processed as is, so encryption can be
+// enabled on a running system. Results of encrypted tasks are encrypted by the worker for
+// the same workspace and decrypted by GetResult and WaitForResult.
+//
+// # Task Status and Results
+//
+// Enqueue returns the task ID. Use it to cancel a pending task or poll for its outcome,
+// e.g. from an HTTP status endpoint:
+//
+//	id, err := enqueuer.Enqueue(ctx, RenderReport{ID: reportID})
+//
+//	result, err := enqueuer.GetResult(ctx, id)
+//	if result.Done() { ... }
+//
+//	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+//	defer cancel()
+//	result, err = enqueuer.WaitForResult(ctx, id)
+//	var report ReportURL
+//	err = result.Decode(&report)
+//
+//	err = enqueuer.Cancel(ctx, id)
+//
+// Handlers created with NewTaskResultHandler store their return value as the result;
+// other handlers call SetResult. WaitForResult returns ErrTaskCancelled and ErrTaskFailed
+// for tasks that did not complete. Results live as long as the task: the Redis backend
+// expires completed tasks after its retention period. Tracking requires a repository
+// implementing TaskTracker and, for results, ResultRepository.
+//
 // # Storage Interfaces
 //
 // The package defines three repository interfaces for different components:
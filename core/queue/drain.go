@@ -0,0 +1,150 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// releaseTimeout bounds the repository calls releasing tasks on drain
+const releaseTimeout = 5 * time.Second
+
+// outcomeTimeout bounds the repository calls recording the outcome of a task
+const outcomeTimeout = 5 * time.Second
+
+// inflight tracks the tasks a worker is processing so a drain can hand them back.
+// Ownership moves to the drain once it takes the tasks: handlers finishing later
+// must not record an outcome for a task that may already run elsewhere.
+type inflight struct {
+	mu      sync.Mutex
+	tasks   map[uuid.UUID]*Task
+	drained bool
+}
+
+func newInflight() *inflight {
+	return &inflight{tasks: make(map[uuid.UUID]*Task)}
+}
+
+// add registers a running task. Returns false once the worker has been drained.
+func (f *inflight) add(task *Task) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.drained {
+		return false
+	}
+	f.tasks[task.ID] = task
+	return true
+}
+
+// remove unregisters a finished task and reports whether the worker still owns it
+func (f *inflight) remove(taskID uuid.UUID) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.tasks[taskID]; !ok {
+		return false
+	}
+	delete(f.tasks, taskID)
+	return true
+}
+
+// drain takes every running task and rejects new ones until reset
+func (f *inflight) drain() []*Task {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.drained = true
+	tasks := make([]*Task, 0, len(f.tasks))
+	for id, task := range f.tasks {
+		tasks = append(tasks, task)
+		delete(f.tasks, id)
+	}
+	return tasks
+}
+
+// reset accepts tasks again after a restart
+func (f *inflight) reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.drained = false
+}
+
+// wait waits for running tasks to finish. Returns false when the drain timeout
+// passes first; a zero timeout waits indefinitely.
+func (w *Worker) wait() bool {
+	if w.drainTimeout == 0 {
+		w.wg.Wait()
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(w.drainTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// abort cancels the handlers still running after the drain timeout and returns
+// their tasks to pending, so other workers pick them up without waiting for the
+// locks to expire. Outcomes of handlers returning afterwards are discarded.
+func (w *Worker) abort(cancelTasks context.CancelFunc) error {
+	tasks := w.inflight.drain()
+	cancelTasks()
+
+	w.logger.Warn("drain timeout exceeded, releasing running tasks",
+		slog.String("worker_id", w.workerID.String()),
+		slog.Int("tasks", len(tasks)))
+
+	var errs error
+	for _, task := range tasks {
+		if err := w.releaseTask(task); err != nil {
+			errs = errors.Join(errs, err)
+		}
+	}
+	return errs
+}
+
+// outcomeContext returns the context for recording the outcome of a task. It is
+// detached from the worker context: Stop cancels that context while running
+// tasks are still allowed to finish and must record their completion or failure.
+func (w *Worker) outcomeContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(w.ctx), outcomeTimeout)
+}
+
+// releaseTask returns a claimed task to pending for immediate pickup
+func (w *Worker) releaseTask(task *Task) error {
+	releaser, ok := w.repo.(ReleaseRepository)
+	if !ok {
+		return ErrReleaseNotSupported
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	if err := releaser.ReleaseTask(ctx, task.ID, time.Now()); err != nil {
+		return fmt.Errorf("failed to release task %s: %w", task.ID, err)
+	}
+
+	w.logger.Info("task released on shutdown",
+		slog.String("worker_id", w.workerID.String()),
+		slog.String("task_id", task.ID.String()),
+		slog.String("task_name", task.TaskName))
+
+	return nil
+}
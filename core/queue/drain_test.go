@@ -0,0 +1,238 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+type drainJob struct {
+	N int `json:"n"`
+}
+
+// ctxStorage is a MemoryStorage honoring cancellation when recording task outcomes,
+// like the database backends do
+type ctxStorage struct {
+	*queue.MemoryStorage
+}
+
+func (s ctxStorage) CompleteTask(ctx context.Context, taskID uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryStorage.CompleteTask(ctx, taskID)
+}
+
+func (s ctxStorage) CompleteTaskWithResult(ctx context.Context, taskID uuid.UUID, result []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryStorage.CompleteTaskWithResult(ctx, taskID, result)
+}
+
+func (s ctxStorage) FailTask(ctx context.Context, taskID uuid.UUID, errorMsg string, retryAt *time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryStorage.FailTask(ctx, taskID, errorMsg, retryAt)
+}
+
+func (s ctxStorage) MoveToDLQ(ctx context.Context, taskID uuid.UUID) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.MemoryStorage.MoveToDLQ(ctx, taskID)
+}
+
+func TestWorker_Drain(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	startDrainWorker := func(t *testing.T, storage *queue.MemoryStorage, handler queue.Handler) *queue.Worker {
+		t.Helper()
+
+		worker, err := queue.NewWorker(storage,
+			queue.WithPullInterval(5*time.Millisecond),
+			queue.WithMaxConcurrentTasks(2),
+			queue.WithDrainTimeout(50*time.Millisecond),
+		)
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(handler))
+		require.NoError(t, worker.Start(ctx))
+		return worker
+	}
+
+	enqueueDrainJob := func(t *testing.T, storage *queue.MemoryStorage) uuid.UUID {
+		t.Helper()
+
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+		return mustEnqueue(t, enqueuer, drainJob{N: 1})
+	}
+
+	t.Run("waits for tasks finishing within the timeout", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		started := make(chan struct{})
+		worker := startDrainWorker(t, storage, queue.NewTaskHandler(func(ctx context.Context, job drainJob) error {
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}))
+		id := enqueueDrainJob(t, storage)
+
+		<-started
+		require.NoError(t, worker.Stop())
+
+		task, err := storage.GetTask(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, queue.TaskStatusCompleted, task.Status)
+	})
+
+	t.Run("records outcomes of tasks finishing during the drain", func(t *testing.T) {
+		t.Parallel()
+
+		memory := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = memory.Close() })
+		storage := ctxStorage{MemoryStorage: memory}
+
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+		succeededID := mustEnqueue(t, enqueuer, drainJob{N: 1})
+		failedID := mustEnqueue(t, enqueuer, drainJob{N: 2}, queue.WithMaxRetries(0))
+
+		started := make(chan struct{}, 2)
+		worker, err := queue.NewWorker(storage,
+			queue.WithPullInterval(5*time.Millisecond),
+			queue.WithMaxConcurrentTasks(2),
+			queue.WithDrainTimeout(time.Second),
+		)
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(func(ctx context.Context, job drainJob) error {
+			started <- struct{}{}
+			time.Sleep(30 * time.Millisecond)
+			if job.N == 2 {
+				return errors.New("boom")
+			}
+			return nil
+		})))
+		require.NoError(t, worker.Start(ctx))
+
+		<-started
+		<-started
+		require.NoError(t, worker.Stop())
+
+		task, err := storage.GetTask(ctx, succeededID)
+		require.NoError(t, err)
+		assert.Equal(t, queue.TaskStatusCompleted, task.Status)
+
+		entries, err := storage.ListDLQ(ctx, queue.DLQFilter{})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, failedID, entries[0].TaskID)
+	})
+
+	t.Run("releases tasks running past the timeout", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		started := make(chan struct{})
+		cancelled := make(chan error, 1)
+		worker := startDrainWorker(t, storage, queue.NewTaskHandler(func(ctx context.Context, job drainJob) error {
+			close(started)
+			<-ctx.Done()
+			cancelled <- ctx.Err()
+			return ctx.Err()
+		}))
+		id := enqueueDrainJob(t, storage)
+
+		<-started
+		stopStart := time.Now()
+		require.NoError(t, worker.Stop())
+		assert.Less(t, time.Since(stopStart), time.Second)
+
+		select {
+		case err := <-cancelled:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("handler context was not cancelled")
+		}
+
+		// The failure returned by the cancelled handler is not recorded
+		time.Sleep(20 * time.Millisecond)
+		task, err := storage.GetTask(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, queue.TaskStatusPending, task.Status)
+		assert.Zero(t, task.RetryCount)
+		assert.Nil(t, task.Error)
+		assert.Nil(t, task.LockedBy)
+
+		// Another worker picks the task up right away
+		done := make(chan struct{})
+		next := startDrainWorker(t, storage, queue.NewTaskHandler(func(ctx context.Context, job drainJob) error {
+			close(done)
+			return nil
+		}))
+		t.Cleanup(func() { _ = next.Stop() })
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("released task was not processed by another worker")
+		}
+	})
+
+	t.Run("discards outcome of handlers ignoring cancellation", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		started := make(chan struct{})
+		finish := make(chan struct{})
+		finished := make(chan struct{})
+		worker := startDrainWorker(t, storage, queue.NewTaskHandler(func(ctx context.Context, job drainJob) error {
+			close(started)
+			<-finish
+			defer close(finished)
+			return nil
+		}))
+		id := enqueueDrainJob(t, storage)
+
+		<-started
+		require.NoError(t, worker.Stop())
+
+		close(finish)
+		<-finished
+		time.Sleep(20 * time.Millisecond)
+
+		task, err := storage.GetTask(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, queue.TaskStatusPending, task.Status)
+	})
+
+	t.Run("requires release support", func(t *testing.T) {
+		t.Parallel()
+
+		worker, err := queue.NewWorker(&MockWorkerRepository{}, queue.WithDrainTimeout(time.Second))
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(func(ctx context.Context, job drainJob) error {
+			return nil
+		})))
+
+		assert.ErrorIs(t, worker.Start(ctx), queue.ErrReleaseNotSupported)
+	})
+}
@@ -0,0 +1,200 @@
+package queue
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/dmitrymomot/foundation/pkg/secrets"
+)
+
+// payloadEnvelopeVersion marks payloads encrypted by PayloadCipher
+const payloadEnvelopeVersion = "queue/v1"
+
+// payloadEnvelopePrefix starts every encrypted payload; the envelope is always
+// marshaled with the version first
+var payloadEnvelopePrefix = []byte(`{"encrypted":"` + payloadEnvelopeVersion + `"`)
+
+// defaultWorkspaceKey is combined with the app key for tasks without a workspace,
+// so they are protected by the app key alone
+var defaultWorkspaceKey = func() []byte {
+	sum := sha256.Sum256([]byte("queue: default workspace key"))
+	return sum[:]
+}()
+
+// WorkspaceKeyFunc returns the keys of a workspace (tenant) for payload encryption.
+// The first key encrypts new payloads; the others are tried when decrypting, so
+// workspace keys can be rotated while tasks encrypted with older keys are stored.
+type WorkspaceKeyFunc func(ctx context.Context, workspaceID string) ([][]byte, error)
+
+// PayloadCipher encrypts task payloads with pkg/secrets, combining an app key with the
+// key of the task's workspace. Encrypted payloads are stored in an envelope recording the
+// app key ID and the workspace ID, so tasks already stored keep working after rotation:
+// new payloads use the current app key while previous keys still decrypt older ones.
+//
+// Example:
+//
+//	cipher, err := queue.NewPayloadCipher("2025-09", appKey,
+//		queue.WithPreviousAppKey("2025-03", oldAppKey),
+//		queue.WithWorkspaceKeys(func(ctx context.Context, workspaceID string) ([][]byte, error) {
+//			return keys.ForWorkspace(ctx, workspaceID)
+//		}),
+//	)
+//	enqueuer, _ := queue.NewEnqueuer(storage, queue.WithEnqueuerEncryption(cipher))
+//	worker, _ := queue.NewWorker(storage, queue.WithWorkerEncryption(cipher))
+//
+//	_, err = enqueuer.Enqueue(ctx, SendInvite{Email: email}, queue.WithWorkspace(workspaceID))
+type PayloadCipher struct {
+	keyID         string
+	appKeys       map[string][]byte
+	workspaceKeys WorkspaceKeyFunc
+}
+
+// PayloadCipherOption is a functional option for configuring a PayloadCipher
+type PayloadCipherOption func(*PayloadCipher)
+
+// WithPreviousAppKey adds a retired app key used only to decrypt payloads encrypted
+// with it. Keep it until all tasks and dead letter queue entries using it are gone.
+func WithPreviousAppKey(keyID string, appKey []byte) PayloadCipherOption {
+	return func(c *PayloadCipher) {
+		if _, exists := c.appKeys[keyID]; !exists {
+			c.appKeys[keyID] = appKey
+		}
+	}
+}
+
+// WithWorkspaceKeys sets how workspace keys are resolved for tasks enqueued with WithWorkspace
+func WithWorkspaceKeys(fn WorkspaceKeyFunc) PayloadCipherOption {
+	return func(c *PayloadCipher) {
+		if fn != nil {
+			c.workspaceKeys = fn
+		}
+	}
+}
+
+// NewPayloadCipher creates a cipher encrypting new payloads with the app key identified by keyID.
+// Keys must be 32 bytes long, e.g. generated with secrets.GenerateKey.
+func NewPayloadCipher(keyID string, appKey []byte, opts ...PayloadCipherOption) (*PayloadCipher, error) {
+	if keyID == "" {
+		return nil, errors.New("payload cipher key ID cannot be empty")
+	}
+
+	c := &PayloadCipher{
+		keyID:   keyID,
+		appKeys: map[string][]byte{keyID: appKey},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	for id, key := range c.appKeys {
+		if err := secrets.ValidateKeys(key, defaultWorkspaceKey); err != nil {
+			return nil, fmt.Errorf("payload cipher key %q: %w", id, err)
+		}
+	}
+
+	return c, nil
+}
+
+// encryptedPayload is the stored form of an encrypted payload
+type encryptedPayload struct {
+	Encrypted string `json:"encrypted"`
+	KeyID     string `json:"key_id"`
+	Workspace string `json:"workspace,omitempty"`
+	Data      []byte `json:"data"`
+}
+
+// Encrypt seals the payload with the current app key and the workspace's current key.
+// An empty workspace ID encrypts with the app key alone.
+func (c *PayloadCipher) Encrypt(ctx context.Context, workspaceID string, payload []byte) ([]byte, error) {
+	keys, err := c.keysFor(ctx, workspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := secrets.EncryptBytes(c.appKeys[c.keyID], keys[0], payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt payload: %w", err)
+	}
+
+	return json.Marshal(encryptedPayload{
+		Encrypted: payloadEnvelopeVersion,
+		KeyID:     c.keyID,
+		Workspace: workspaceID,
+		Data:      data,
+	})
+}
+
+// Decrypt opens a payload created by Encrypt. Payloads that are not encrypted are returned as is,
+// so encryption can be enabled while plaintext tasks are still stored.
+func (c *PayloadCipher) Decrypt(ctx context.Context, payload []byte) ([]byte, error) {
+	if !IsEncryptedPayload(payload) {
+		return payload, nil
+	}
+
+	var envelope encryptedPayload
+	if err := json.Unmarshal(payload, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", secrets.ErrInvalidCiphertext, err)
+	}
+
+	appKey, ok := c.appKeys[envelope.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPayloadKey, envelope.KeyID)
+	}
+
+	keys, err := c.keysFor(ctx, envelope.Workspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var errs error
+	for _, key := range keys {
+		plaintext, err := secrets.DecryptBytes(appKey, key, envelope.Data)
+		if err == nil {
+			return plaintext, nil
+		}
+		errs = errors.Join(errs, err)
+	}
+	return nil, fmt.Errorf("failed to decrypt payload of workspace %q: %w", envelope.Workspace, errs)
+}
+
+// encryptLike encrypts a payload derived from an encrypted source payload, such as the
+// next chain step, with the source's workspace. Plaintext sources produce plaintext.
+func (c *PayloadCipher) encryptLike(ctx context.Context, source, payload []byte) ([]byte, error) {
+	if !IsEncryptedPayload(source) {
+		return payload, nil
+	}
+
+	var envelope encryptedPayload
+	if err := json.Unmarshal(source, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", secrets.ErrInvalidCiphertext, err)
+	}
+	return c.Encrypt(ctx, envelope.Workspace, payload)
+}
+
+// keysFor returns the workspace keys to combine with the app key, current first
+func (c *PayloadCipher) keysFor(ctx context.Context, workspaceID string) ([][]byte, error) {
+	if workspaceID == "" {
+		return [][]byte{defaultWorkspaceKey}, nil
+	}
+	if c.workspaceKeys == nil {
+		return nil, fmt.Errorf("no workspace keys configured for workspace %q", workspaceID)
+	}
+
+	keys, err := c.workspaceKeys(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get keys of workspace %q: %w", workspaceID, err)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no keys found for workspace %q", workspaceID)
+	}
+	return keys, nil
+}
+
+// IsEncryptedPayload reports whether the payload was encrypted by a PayloadCipher
+func IsEncryptedPayload(payload []byte) bool {
+	return bytes.HasPrefix(payload, payloadEnvelopePrefix)
+}
@@ -0,0 +1,317 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+	"github.com/dmitrymomot/foundation/pkg/secrets"
+)
+
+type encryptedInvite struct {
+	Email string `json:"email"`
+}
+
+type encryptedWelcome struct {
+	Email string `json:"email"`
+}
+
+func newSecretKey(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := secrets.GenerateKey()
+	require.NoError(t, err)
+	return key
+}
+
+func newPayloadCipher(t *testing.T, keyID string, appKey []byte, opts ...queue.PayloadCipherOption) *queue.PayloadCipher {
+	t.Helper()
+
+	cipher, err := queue.NewPayloadCipher(keyID, appKey, opts...)
+	require.NoError(t, err)
+	return cipher
+}
+
+// runEncryptedTask processes a single task with a worker using the cipher and returns the payload seen by the handler
+func runEncryptedTask(t *testing.T, storage *queue.MemoryStorage, cipher *queue.PayloadCipher) encryptedInvite {
+	t.Helper()
+
+	received := make(chan encryptedInvite, 1)
+	worker, err := queue.NewWorker(storage,
+		queue.WithPullInterval(5*time.Millisecond),
+		queue.WithWorkerEncryption(cipher),
+	)
+	require.NoError(t, err)
+	require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(func(ctx context.Context, p encryptedInvite) error {
+		received <- p
+		return nil
+	})))
+	require.NoError(t, worker.Start(context.Background()))
+	t.Cleanup(func() { _ = worker.Stop() })
+
+	select {
+	case p := <-received:
+		return p
+	case <-time.After(5 * time.Second):
+		t.Fatal("task was not processed")
+		return encryptedInvite{}
+	}
+}
+
+func TestPayloadEncryption(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("stores ciphertext and hands plaintext to the handler", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		cipher := newPayloadCipher(t, "k1", newSecretKey(t))
+
+		enqueuer, err := queue.NewEnqueuer(storage, queue.WithEnqueuerEncryption(cipher))
+		require.NoError(t, err)
+		id := mustEnqueue(t, enqueuer, encryptedInvite{Email: "jane@example.com"})
+
+		stored, err := storage.GetTask(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, queue.IsEncryptedPayload(stored.Payload))
+		assert.NotContains(t, string(stored.Payload), "jane@example.com")
+
+		assert.Equal(t, "jane@example.com", runEncryptedTask(t, storage, cipher).Email)
+	})
+
+	t.Run("uses workspace keys", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		workspaceKeys := map[string][]byte{"acme": newSecretKey(t), "globex": newSecretKey(t)}
+		cipher := newPayloadCipher(t, "k1", newSecretKey(t), queue.WithWorkspaceKeys(
+			func(ctx context.Context, workspaceID string) ([][]byte, error) {
+				key, ok := workspaceKeys[workspaceID]
+				if !ok {
+					return nil, errors.New("unknown workspace")
+				}
+				return [][]byte{key}, nil
+			},
+		))
+
+		enqueuer, err := queue.NewEnqueuer(storage, queue.WithEnqueuerEncryption(cipher))
+		require.NoError(t, err)
+		mustEnqueue(t, enqueuer, encryptedInvite{Email: "ceo@acme.test"}, queue.WithWorkspace("acme"))
+
+		_, err = enqueuer.Enqueue(ctx, encryptedInvite{Email: "x@initech.test"}, queue.WithWorkspace("initech"))
+		assert.Error(t, err)
+
+		assert.Equal(t, "ceo@acme.test", runEncryptedTask(t, storage, cipher).Email)
+	})
+
+	t.Run("decrypts tasks stored before key rotation", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		oldAppKey, oldWorkspaceKey := newSecretKey(t), newSecretKey(t)
+		before := newPayloadCipher(t, "2025-03", oldAppKey, queue.WithWorkspaceKeys(
+			func(ctx context.Context, workspaceID string) ([][]byte, error) {
+				return [][]byte{oldWorkspaceKey}, nil
+			},
+		))
+		enqueuer, err := queue.NewEnqueuer(storage, queue.WithEnqueuerEncryption(before))
+		require.NoError(t, err)
+		mustEnqueue(t, enqueuer, encryptedInvite{Email: "old@example.com"}, queue.WithWorkspace("acme"))
+
+		// Both the app key and the workspace key were rotated since
+		newWorkspaceKey := newSecretKey(t)
+		after := newPayloadCipher(t, "2025-09", newSecretKey(t),
+			queue.WithPreviousAppKey("2025-03", oldAppKey),
+			queue.WithWorkspaceKeys(func(ctx context.Context, workspaceID string) ([][]byte, error) {
+				return [][]byte{newWorkspaceKey, oldWorkspaceKey}, nil
+			}),
+		)
+		assert.Equal(t, "old@example.com", runEncryptedTask(t, storage, after).Email)
+
+		// New payloads use the current keys only
+		payload, err := after.Encrypt(ctx, "acme", []byte(`{"email":"new@example.com"}`))
+		require.NoError(t, err)
+		assert.Contains(t, string(payload), `"key_id":"2025-09"`)
+		_, err = before.Decrypt(ctx, payload)
+		assert.ErrorIs(t, err, queue.ErrUnknownPayloadKey)
+	})
+
+	t.Run("processes plaintext tasks stored before encryption was enabled", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+		mustEnqueue(t, enqueuer, encryptedInvite{Email: "plain@example.com"})
+
+		cipher := newPayloadCipher(t, "k1", newSecretKey(t))
+		assert.Equal(t, "plain@example.com", runEncryptedTask(t, storage, cipher).Email)
+	})
+
+	t.Run("worker without cipher fails encrypted tasks", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		enqueuer, err := queue.NewEnqueuer(storage, queue.WithEnqueuerEncryption(newPayloadCipher(t, "k1", newSecretKey(t))))
+		require.NoError(t, err)
+		id := mustEnqueue(t, enqueuer, encryptedInvite{Email: "jane@example.com"}, queue.WithMaxRetries(0))
+
+		worker, err := queue.NewWorker(storage, queue.WithPullInterval(5*time.Millisecond))
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(func(ctx context.Context, p encryptedInvite) error {
+			t.Error("handler must not receive an encrypted payload")
+			return nil
+		})))
+		require.NoError(t, worker.Start(ctx))
+		t.Cleanup(func() { _ = worker.Stop() })
+
+		require.Eventually(t, func() bool {
+			entries, err := storage.ListDLQ(ctx, queue.DLQFilter{})
+			return err == nil && len(entries) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		entries, err := storage.ListDLQ(ctx, queue.DLQFilter{})
+		require.NoError(t, err)
+		assert.Equal(t, id, entries[0].TaskID)
+		assert.Contains(t, entries[0].Error, queue.ErrPayloadEncrypted.Error())
+		assert.True(t, queue.IsEncryptedPayload(entries[0].Payload))
+	})
+
+	t.Run("encrypts chain steps for the same workspace", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		workspaceKey := newSecretKey(t)
+		cipher := newPayloadCipher(t, "k1", newSecretKey(t), queue.WithWorkspaceKeys(
+			func(ctx context.Context, workspaceID string) ([][]byte, error) {
+				return [][]byte{workspaceKey}, nil
+			},
+		))
+
+		enqueuer, err := queue.NewEnqueuer(storage, queue.WithEnqueuerEncryption(cipher))
+		require.NoError(t, err)
+
+		welcomed := make(chan string, 1)
+		worker, err := queue.NewWorker(storage,
+			queue.WithPullInterval(5*time.Millisecond),
+			queue.WithWorkerEncryption(cipher),
+		)
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandlers(
+			queue.NewTaskResultHandler(func(ctx context.Context, p encryptedInvite) (encryptedWelcome, error) {
+				return encryptedWelcome{Email: strings.ToUpper(p.Email)}, nil
+			}),
+			queue.NewTaskHandler(func(ctx context.Context, p encryptedWelcome) error {
+				welcomed <- p.Email
+				return nil
+			}),
+		))
+
+		_, err = enqueuer.EnqueueChain(ctx, queue.NewChain(encryptedInvite{Email: "jane@example.com"}, queue.WithWorkspace("acme")).
+			Then(queue.Step[encryptedWelcome]()))
+		require.NoError(t, err)
+
+		require.NoError(t, worker.Start(ctx))
+		t.Cleanup(func() { _ = worker.Stop() })
+
+		select {
+		case email := <-welcomed:
+			assert.Equal(t, "JANE@EXAMPLE.COM", email)
+		case <-time.After(5 * time.Second):
+			t.Fatal("chain step was not processed")
+		}
+
+		tasks, err := storage.ListTasks(ctx, queue.TaskFilter{})
+		require.NoError(t, err)
+		var stepPayload []byte
+		for _, task := range tasks {
+			if strings.HasSuffix(task.TaskName, "encryptedWelcome") {
+				stepPayload = task.Payload
+			}
+		}
+		require.NotNil(t, stepPayload)
+		assert.True(t, queue.IsEncryptedPayload(stepPayload))
+		assert.Contains(t, string(stepPayload), `"workspace":"acme"`)
+	})
+
+	t.Run("encrypts results of encrypted tasks", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		cipher := newPayloadCipher(t, "k1", newSecretKey(t))
+
+		enqueuer, err := queue.NewEnqueuer(storage,
+			queue.WithEnqueuerEncryption(cipher),
+			queue.WithResultPollInterval(5*time.Millisecond),
+		)
+		require.NoError(t, err)
+
+		worker, err := queue.NewWorker(storage,
+			queue.WithPullInterval(5*time.Millisecond),
+			queue.WithWorkerEncryption(cipher),
+		)
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(
+			queue.NewTaskResultHandler(func(ctx context.Context, p encryptedInvite) (encryptedWelcome, error) {
+				return encryptedWelcome{Email: strings.ToUpper(p.Email)}, nil
+			}),
+		))
+		require.NoError(t, worker.Start(ctx))
+		t.Cleanup(func() { _ = worker.Stop() })
+
+		id := mustEnqueue(t, enqueuer, encryptedInvite{Email: "jane@example.com"})
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		result, err := enqueuer.WaitForResult(waitCtx, id)
+		require.NoError(t, err)
+
+		var welcome encryptedWelcome
+		require.NoError(t, result.Decode(&welcome))
+		assert.Equal(t, "JANE@EXAMPLE.COM", welcome.Email)
+
+		stored, err := storage.GetTask(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, queue.IsEncryptedPayload(stored.Result))
+		assert.NotContains(t, string(stored.Result), "JANE@EXAMPLE.COM")
+
+		plain, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+		result, err = plain.GetResult(ctx, id)
+		require.NoError(t, err)
+		assert.True(t, queue.IsEncryptedPayload(result.Result), "results stay encrypted without a cipher")
+	})
+
+	t.Run("rejects invalid keys", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := queue.NewPayloadCipher("k1", []byte("short"))
+		assert.ErrorIs(t, err, secrets.ErrInvalidAppKey)
+
+		_, err = queue.NewPayloadCipher("k1", newSecretKey(t), queue.WithPreviousAppKey("k0", []byte("short")))
+		assert.ErrorIs(t, err, secrets.ErrInvalidAppKey)
+
+		_, err = queue.NewPayloadCipher("", newSecretKey(t))
+		assert.Error(t, err)
+	})
+}
@@ -3,6 +3,7 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -16,9 +17,12 @@ type EnqueuerRepository interface {
 
 // Enqueuer handles task enqueueing with configurable defaults.
 type Enqueuer struct {
-	repo            EnqueuerRepository
-	defaultQueue    string
-	defaultPriority Priority
+	repo               EnqueuerRepository
+	defaultQueue       string
+	defaultPriority    Priority
+	resultPollInterval time.Duration
+	metrics            Metrics
+	cipher             *PayloadCipher
 }
 
 // NewEnqueuer creates a new Enqueuer with the given repository and options.
@@ -28,8 +32,10 @@ func NewEnqueuer(repo EnqueuerRepository, opts ...EnqueuerOption) (*Enqueuer, er
 	}
 
 	options := &enqueuerOptions{
-		defaultQueue:    DefaultQueueName,
-		defaultPriority: PriorityDefault,
+		defaultQueue:       DefaultQueueName,
+		defaultPriority:    PriorityDefault,
+		resultPollInterval: 500 * time.Millisecond,
+		metrics:            noopMetrics{},
 	}
 
 	for _, opt := range opts {
@@ -37,23 +43,59 @@ func NewEnqueuer(repo EnqueuerRepository, opts ...EnqueuerOption) (*Enqueuer, er
 	}
 
 	return &Enqueuer{
-		repo:            repo,
-		defaultQueue:    options.defaultQueue,
-		defaultPriority: options.defaultPriority,
+		repo:               repo,
+		defaultQueue:       options.defaultQueue,
+		defaultPriority:    options.defaultPriority,
+		resultPollInterval: options.resultPollInterval,
+		metrics:            options.metrics,
+		cipher:             options.cipher,
 	}, nil
 }
 
 // Enqueue adds a new task to the queue with the given payload and options.
-func (e *Enqueuer) Enqueue(ctx context.Context, payload any, opts ...EnqueueOption) error {
+// Returns the ID of the created task, which can be used with Cancel, GetResult and
+// WaitForResult. A task skipped because of its unique key returns the ID of the
+// task holding the key.
+func (e *Enqueuer) Enqueue(ctx context.Context, payload any, opts ...EnqueueOption) (uuid.UUID, error) {
 	if payload == nil {
-		return ErrPayloadNil
+		return uuid.Nil, ErrPayloadNil
 	}
 
+	options, err := e.enqueueOptions(opts)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	// Build task with payload and options
+	task, err := e.buildTask(payload, options)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if err := e.encryptPayload(ctx, task, options.workspace); err != nil {
+		return uuid.Nil, err
+	}
+
+	if options.uniqueKey != "" {
+		return e.createUniqueTask(ctx, task, options.onConflict)
+	}
+
+	// Store task in repository
+	if err := e.repo.CreateTask(ctx, task); err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create task %q in queue %q: %w", task.TaskName, task.Queue, err)
+	}
+	e.metrics.TaskEnqueued(task.Queue, task.TaskName)
+
+	return task.ID, nil
+}
+
+// enqueueOptions applies the enqueuer defaults and the given options.
+func (e *Enqueuer) enqueueOptions(opts []EnqueueOption) (*enqueueOptions, error) {
 	// Apply default options from enqueuer configuration
 	options := &enqueueOptions{
 		queue:      e.defaultQueue,
 		priority:   e.defaultPriority,
 		maxRetries: 3,
+		onConflict: UniqueConflictSkip,
 	}
 
 	// Apply user-provided options to override defaults
@@ -63,20 +105,51 @@ func (e *Enqueuer) Enqueue(ctx context.Context, payload any, opts ...EnqueueOpti
 
 	// Validate priority is within allowed range
 	if !options.priority.Valid() {
-		return ErrInvalidPriority
+		return nil, ErrInvalidPriority
 	}
 
-	// Build task with payload and options
-	task, err := e.buildTask(payload, options)
+	return options, nil
+}
+
+// createUniqueTask stores a task with a unique key, delegating conflict handling to the repository.
+// Returns the ID of the task holding the key.
+func (e *Enqueuer) createUniqueTask(ctx context.Context, task *Task, onConflict UniqueConflict) (uuid.UUID, error) {
+	if !onConflict.Valid() {
+		return uuid.Nil, ErrInvalidUniqueConflict
+	}
+
+	repo, ok := e.repo.(UniqueTaskRepository)
+	if !ok {
+		return uuid.Nil, ErrUniqueNotSupported
+	}
+
+	id, err := repo.CreateUniqueTask(ctx, task, onConflict)
 	if err != nil {
-		return err
+		if errors.Is(err, ErrDuplicateTask) {
+			return uuid.Nil, err
+		}
+		return uuid.Nil, fmt.Errorf("failed to create task %q in queue %q: %w", task.TaskName, task.Queue, err)
 	}
 
-	// Store task in repository
-	if err := e.repo.CreateTask(ctx, task); err != nil {
-		return fmt.Errorf("failed to create task %q in queue %q: %w", task.TaskName, task.Queue, err)
+	// A skipped task returns the ID of the task holding the key
+	if id == task.ID {
+		e.metrics.TaskEnqueued(task.Queue, task.TaskName)
 	}
 
+	return id, nil
+}
+
+// encryptPayload replaces the task payload with its encrypted form when the enqueuer has a cipher
+func (e *Enqueuer) encryptPayload(ctx context.Context, task *Task, workspaceID string) error {
+	if e.cipher == nil {
+		return nil
+	}
+
+	payload, err := e.cipher.Encrypt(ctx, workspaceID, task.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt payload of task %q: %w", task.TaskName, err)
+	}
+	task.Payload = payload
 	return nil
 }
 
@@ -103,6 +176,16 @@ func (e *Enqueuer) buildTask(payload any, options *enqueueOptions) (*Task, error
 		scheduledAt = scheduledAt.Add(options.delay)
 	}
 
+	var uniqueKey *string
+	var uniqueUntil *time.Time
+	if options.uniqueKey != "" {
+		uniqueKey = &options.uniqueKey
+		if options.uniqueTTL > 0 {
+			until := time.Now().Add(options.uniqueTTL)
+			uniqueUntil = &until
+		}
+	}
+
 	return &Task{
 		ID:          uuid.New(),
 		Queue:       options.queue,
@@ -113,6 +196,10 @@ func (e *Enqueuer) buildTask(payload any, options *enqueueOptions) (*Task, error
 		Priority:    options.priority,
 		RetryCount:  0,
 		MaxRetries:  options.maxRetries,
+		Backoff:     options.backoff,
+		UniqueKey:   uniqueKey,
+		UniqueUntil: uniqueUntil,
+		Next:        options.next,
 		ScheduledAt: scheduledAt,
 		CreatedAt:   time.Now(),
 	}, nil
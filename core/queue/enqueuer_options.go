@@ -6,8 +6,11 @@ import "time"
 type EnqueuerOption func(*enqueuerOptions)
 
 type enqueuerOptions struct {
-	defaultQueue    string
-	defaultPriority Priority
+	defaultQueue       string
+	defaultPriority    Priority
+	resultPollInterval time.Duration
+	metrics            Metrics
+	cipher             *PayloadCipher
 }
 
 // WithDefaultQueue sets the default queue name
@@ -28,6 +31,35 @@ func WithDefaultPriority(priority Priority) EnqueuerOption {
 	}
 }
 
+// WithResultPollInterval sets how often WaitForResult checks the task status
+func WithResultPollInterval(d time.Duration) EnqueuerOption {
+	return func(o *enqueuerOptions) {
+		if d > 0 {
+			o.resultPollInterval = d
+		}
+	}
+}
+
+// WithEnqueuerMetrics reports every stored task to metrics
+func WithEnqueuerMetrics(metrics Metrics) EnqueuerOption {
+	return func(o *enqueuerOptions) {
+		if metrics != nil {
+			o.metrics = metrics
+		}
+	}
+}
+
+// WithEnqueuerEncryption encrypts the payloads of all enqueued tasks with the cipher
+// and decrypts their results in GetResult and WaitForResult.
+// Workers need a cipher with the same keys, see WithWorkerEncryption.
+func WithEnqueuerEncryption(cipher *PayloadCipher) EnqueuerOption {
+	return func(o *enqueuerOptions) {
+		if cipher != nil {
+			o.cipher = cipher
+		}
+	}
+}
+
 // EnqueueOption is a functional option for the Enqueue method
 type EnqueueOption func(*enqueueOptions)
 
@@ -38,6 +70,12 @@ type enqueueOptions struct {
 	delay       time.Duration
 	scheduledAt *time.Time
 	taskName    string
+	backoff     *BackoffPolicy
+	uniqueKey   string
+	uniqueTTL   time.Duration
+	onConflict  UniqueConflict
+	next        []WorkflowStep
+	workspace   string
 }
 
 // WithQueue sets the queue for the task
@@ -49,6 +87,14 @@ func WithQueue(queue string) EnqueueOption {
 	}
 }
 
+// WithWorkspace encrypts the payload with the key of the workspace (tenant) when the
+// enqueuer has a payload cipher. The workspace ID itself is stored unencrypted.
+func WithWorkspace(workspaceID string) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.workspace = workspaceID
+	}
+}
+
 // WithPriority sets the priority for the task
 func WithPriority(priority Priority) EnqueueOption {
 	return func(o *enqueueOptions) {
@@ -90,3 +136,31 @@ func WithTaskName(name string) EnqueueOption {
 		}
 	}
 }
+
+// WithBackoff sets the retry backoff policy stored with the task.
+// Takes precedence over handler and worker backoff configuration.
+func WithBackoff(policy BackoffPolicy) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.backoff = &policy
+	}
+}
+
+// WithUniqueKey deduplicates the task by key: while another task with the same key is
+// pending or processing, the new task is handled according to WithUniqueConflict (skip by default).
+// A positive ttl limits how long the key is held; zero holds it until the task finishes.
+// Requires a repository implementing UniqueTaskRepository.
+func WithUniqueKey(key string, ttl time.Duration) EnqueueOption {
+	return func(o *enqueueOptions) {
+		if key != "" {
+			o.uniqueKey = key
+			o.uniqueTTL = max(ttl, 0)
+		}
+	}
+}
+
+// WithUniqueConflict sets what happens when the unique key is already held by another task
+func WithUniqueConflict(onConflict UniqueConflict) EnqueueOption {
+	return func(o *enqueueOptions) {
+		o.onConflict = onConflict
+	}
+}
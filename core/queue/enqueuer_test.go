@@ -29,6 +29,15 @@ func (m *mockEnqueuerRepo) CreateTask(ctx context.Context, task *queue.Task) err
 }
 
 // Test payload types
+// mustEnqueue enqueues the payload and returns the task ID, failing the test on error
+func mustEnqueue(t *testing.T, enqueuer *queue.Enqueuer, payload any, opts ...queue.EnqueueOption) uuid.UUID {
+	t.Helper()
+
+	id, err := enqueuer.Enqueue(context.Background(), payload, opts...)
+	require.NoError(t, err)
+	return id
+}
+
 type enqueueTestPayload struct {
 	Message string `json:"message"`
 	Value   int    `json:"value"`
@@ -82,13 +91,14 @@ func TestEnqueuer_Enqueue(t *testing.T) {
 		require.NoError(t, err)
 
 		payload := enqueueTestPayload{Message: "test", Value: 42}
-		err = enqueuer.Enqueue(context.Background(), payload)
+		id, err := enqueuer.Enqueue(context.Background(), payload)
 		require.NoError(t, err)
 
 		// Verify task was created
 		require.Len(t, repo.tasks, 1)
 		task := repo.tasks[0]
 		assert.NotEqual(t, uuid.Nil, task.ID)
+		assert.Equal(t, task.ID, id)
 		assert.Equal(t, queue.DefaultQueueName, task.Queue)
 		assert.Equal(t, queue.TaskTypeOneTime, task.TaskType)
 		assert.Equal(t, "queue_test.enqueueTestPayload", task.TaskName)
@@ -110,7 +120,7 @@ func TestEnqueuer_Enqueue(t *testing.T) {
 		payload := enqueueTestPayload{Message: "custom", Value: 100}
 		scheduledTime := time.Now().Add(time.Hour)
 
-		err = enqueuer.Enqueue(context.Background(), payload,
+		_, err = enqueuer.Enqueue(context.Background(), payload,
 			queue.WithQueue("priority-queue"),
 			queue.WithPriority(queue.PriorityMax),
 			queue.WithMaxRetries(5),
@@ -138,7 +148,7 @@ func TestEnqueuer_Enqueue(t *testing.T) {
 		payload := enqueueTestPayload{Message: "delayed", Value: 1}
 		beforeEnqueue := time.Now()
 
-		err = enqueuer.Enqueue(context.Background(), payload,
+		_, err = enqueuer.Enqueue(context.Background(), payload,
 			queue.WithDelay(30*time.Second),
 		)
 		require.NoError(t, err)
@@ -159,7 +169,7 @@ func TestEnqueuer_Enqueue(t *testing.T) {
 		payload := enqueueTestPayload{Message: "scheduled", Value: 1}
 		scheduledTime := time.Now().Add(2 * time.Hour)
 
-		err = enqueuer.Enqueue(context.Background(), payload,
+		_, err = enqueuer.Enqueue(context.Background(), payload,
 			queue.WithDelay(30*time.Second),      // This should be ignored
 			queue.WithScheduledAt(scheduledTime), // This takes precedence
 		)
@@ -177,7 +187,7 @@ func TestEnqueuer_Enqueue(t *testing.T) {
 		enqueuer, err := queue.NewEnqueuer(repo)
 		require.NoError(t, err)
 
-		err = enqueuer.Enqueue(context.Background(), nil)
+		_, err = enqueuer.Enqueue(context.Background(), nil)
 		assert.ErrorIs(t, err, queue.ErrPayloadNil)
 		assert.Empty(t, repo.tasks)
 	})
@@ -189,7 +199,7 @@ func TestEnqueuer_Enqueue(t *testing.T) {
 		require.NoError(t, err)
 
 		payload := enqueueTestPayload{Message: "invalid", Value: 1}
-		err = enqueuer.Enqueue(context.Background(), payload,
+		_, err = enqueuer.Enqueue(context.Background(), payload,
 			queue.WithPriority(queue.Priority(101)), // Invalid priority
 		)
 		assert.ErrorIs(t, err, queue.ErrInvalidPriority)
@@ -204,7 +214,7 @@ func TestEnqueuer_Enqueue(t *testing.T) {
 
 		// Channel cannot be marshaled to JSON
 		payload := unmarshalablePayload{Ch: make(chan int)}
-		err = enqueuer.Enqueue(context.Background(), payload)
+		_, err = enqueuer.Enqueue(context.Background(), payload)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to marshal payload")
 		assert.Empty(t, repo.tasks)
@@ -222,7 +232,7 @@ func TestEnqueuer_Enqueue(t *testing.T) {
 		require.NoError(t, err)
 
 		payload := enqueueTestPayload{Message: "fail", Value: 1}
-		err = enqueuer.Enqueue(context.Background(), payload)
+		_, err = enqueuer.Enqueue(context.Background(), payload)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to create task")
 		assert.Contains(t, err.Error(), "database connection lost")
@@ -239,7 +249,7 @@ func TestEnqueuer_TaskNameGeneration(t *testing.T) {
 		require.NoError(t, err)
 
 		payload := enqueueTestPayload{Message: "test", Value: 1}
-		err = enqueuer.Enqueue(context.Background(), payload)
+		_, err = enqueuer.Enqueue(context.Background(), payload)
 		require.NoError(t, err)
 
 		require.Len(t, repo.tasks, 1)
@@ -253,7 +263,7 @@ func TestEnqueuer_TaskNameGeneration(t *testing.T) {
 		require.NoError(t, err)
 
 		payload := &enqueueTestPayload{Message: "test", Value: 1}
-		err = enqueuer.Enqueue(context.Background(), payload)
+		_, err = enqueuer.Enqueue(context.Background(), payload)
 		require.NoError(t, err)
 
 		require.Len(t, repo.tasks, 1)
@@ -267,7 +277,7 @@ func TestEnqueuer_TaskNameGeneration(t *testing.T) {
 		require.NoError(t, err)
 
 		payload := map[string]any{"message": "test", "value": 1}
-		err = enqueuer.Enqueue(context.Background(), payload)
+		_, err = enqueuer.Enqueue(context.Background(), payload)
 		require.NoError(t, err)
 
 		require.Len(t, repo.tasks, 1)
@@ -281,7 +291,7 @@ func TestEnqueuer_TaskNameGeneration(t *testing.T) {
 		require.NoError(t, err)
 
 		payload := enqueueTestPayload{Message: "test", Value: 1}
-		err = enqueuer.Enqueue(context.Background(), payload,
+		_, err = enqueuer.Enqueue(context.Background(), payload,
 			queue.WithTaskName("my.custom.TaskName"),
 		)
 		require.NoError(t, err)
@@ -305,7 +315,7 @@ func TestEnqueuer_DefaultConfiguration(t *testing.T) {
 		require.NoError(t, err)
 
 		payload := enqueueTestPayload{Message: "test", Value: 1}
-		err = enqueuer.Enqueue(context.Background(), payload)
+		_, err = enqueuer.Enqueue(context.Background(), payload)
 		require.NoError(t, err)
 
 		// Verify defaults were used
@@ -326,7 +336,7 @@ func TestEnqueuer_DefaultConfiguration(t *testing.T) {
 		require.NoError(t, err)
 
 		payload := enqueueTestPayload{Message: "test", Value: 1}
-		err = enqueuer.Enqueue(context.Background(), payload,
+		_, err = enqueuer.Enqueue(context.Background(), payload,
 			queue.WithQueue("override-queue"),
 			queue.WithPriority(queue.PriorityMax),
 		)
@@ -354,7 +364,7 @@ func TestEnqueuer_PayloadMarshaling(t *testing.T) {
 			Message: "test message with special chars: 👍",
 			Value:   -12345,
 		}
-		err = enqueuer.Enqueue(context.Background(), payload)
+		_, err = enqueuer.Enqueue(context.Background(), payload)
 		require.NoError(t, err)
 
 		// Verify payload was correctly marshaled
@@ -396,7 +406,7 @@ func TestEnqueuer_PayloadMarshaling(t *testing.T) {
 			},
 		}
 
-		err = enqueuer.Enqueue(context.Background(), payload)
+		_, err = enqueuer.Enqueue(context.Background(), payload)
 		require.NoError(t, err)
 
 		// Verify complex payload was marshaled correctly
@@ -417,3 +427,123 @@ func TestEnqueuer_PayloadMarshaling(t *testing.T) {
 		assert.Equal(t, payload.Nested.Value, decoded.Nested.Value)
 	})
 }
+
+func TestEnqueuer_UniqueKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	payload := enqueueTestPayload{Message: "unique", Value: 1}
+
+	newEnqueuer := func(t *testing.T) (*queue.Enqueuer, *queue.MemoryStorage) {
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+		return enqueuer, storage
+	}
+
+	countTasks := func(t *testing.T, storage *queue.MemoryStorage, status queue.TaskStatus) int64 {
+		count, err := storage.CountTasks(ctx, queue.TaskFilter{Status: status})
+		require.NoError(t, err)
+		return count
+	}
+
+	t.Run("skips duplicate by default", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, storage := newEnqueuer(t)
+
+		first := mustEnqueue(t, enqueuer, payload, queue.WithUniqueKey("order:1", time.Minute))
+		skipped := mustEnqueue(t, enqueuer, payload, queue.WithUniqueKey("order:1", time.Minute))
+		other := mustEnqueue(t, enqueuer, payload, queue.WithUniqueKey("order:2", time.Minute))
+
+		assert.Equal(t, first, skipped, "skipped duplicate returns the existing task ID")
+		assert.NotEqual(t, first, other)
+
+		assert.EqualValues(t, 2, countTasks(t, storage, queue.TaskStatusPending))
+	})
+
+	t.Run("returns error in error mode", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, _ := newEnqueuer(t)
+
+		mustEnqueue(t, enqueuer, payload, queue.WithUniqueKey("order:1", 0))
+		_, err := enqueuer.Enqueue(ctx, payload,
+			queue.WithUniqueKey("order:1", 0), queue.WithUniqueConflict(queue.UniqueConflictError))
+		assert.ErrorIs(t, err, queue.ErrDuplicateTask)
+	})
+
+	t.Run("replaces pending task", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, storage := newEnqueuer(t)
+
+		mustEnqueue(t, enqueuer, payload, queue.WithUniqueKey("order:1", time.Minute))
+		mustEnqueue(t, enqueuer, enqueueTestPayload{Message: "replacement"},
+			queue.WithUniqueKey("order:1", time.Minute), queue.WithUniqueConflict(queue.UniqueConflictReplace))
+
+		assert.EqualValues(t, 1, countTasks(t, storage, queue.TaskStatusPending))
+		assert.EqualValues(t, 1, countTasks(t, storage, queue.TaskStatusCancelled))
+
+		pending, err := storage.ListTasks(ctx, queue.TaskFilter{Status: queue.TaskStatusPending})
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Contains(t, string(pending[0].Payload), "replacement")
+	})
+
+	t.Run("replace keeps processing task running", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, storage := newEnqueuer(t)
+
+		mustEnqueue(t, enqueuer, payload, queue.WithUniqueKey("order:1", 0))
+		_, err := storage.ClaimTask(ctx, uuid.New(), []string{queue.DefaultQueueName}, time.Minute)
+		require.NoError(t, err)
+
+		mustEnqueue(t, enqueuer, payload,
+			queue.WithUniqueKey("order:1", 0), queue.WithUniqueConflict(queue.UniqueConflictReplace))
+
+		assert.EqualValues(t, 1, countTasks(t, storage, queue.TaskStatusProcessing))
+		assert.EqualValues(t, 1, countTasks(t, storage, queue.TaskStatusPending))
+	})
+
+	t.Run("key is released after ttl", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, storage := newEnqueuer(t)
+
+		mustEnqueue(t, enqueuer, payload, queue.WithUniqueKey("order:1", 10*time.Millisecond))
+		time.Sleep(20 * time.Millisecond)
+		mustEnqueue(t, enqueuer, payload, queue.WithUniqueKey("order:1", 10*time.Millisecond))
+
+		assert.EqualValues(t, 2, countTasks(t, storage, queue.TaskStatusPending))
+	})
+
+	t.Run("key is released when task completes", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, storage := newEnqueuer(t)
+
+		mustEnqueue(t, enqueuer, payload, queue.WithUniqueKey("order:1", 0))
+		task, err := storage.ClaimTask(ctx, uuid.New(), []string{queue.DefaultQueueName}, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, storage.CompleteTask(ctx, task.ID))
+
+		mustEnqueue(t, enqueuer, payload,
+			queue.WithUniqueKey("order:1", 0), queue.WithUniqueConflict(queue.UniqueConflictError))
+	})
+
+	t.Run("repository without unique support", func(t *testing.T) {
+		t.Parallel()
+
+		enqueuer, err := queue.NewEnqueuer(&mockEnqueuerRepo{})
+		require.NoError(t, err)
+
+		_, err = enqueuer.Enqueue(ctx, payload, queue.WithUniqueKey("order:1", 0))
+		assert.ErrorIs(t, err, queue.ErrUniqueNotSupported)
+	})
+
+	t.Run("invalid conflict behavior", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, _ := newEnqueuer(t)
+
+		_, err := enqueuer.Enqueue(ctx, payload,
+			queue.WithUniqueKey("order:1", 0), queue.WithUniqueConflict("overwrite"))
+		assert.ErrorIs(t, err, queue.ErrInvalidUniqueConflict)
+	})
+}
@@ -19,4 +19,21 @@ var (
 	ErrFailedToUpdateTaskStatus = errors.New("failed to update task status")
 	ErrFailedToMoveToDLQ        = errors.New("failed to move task to dead letter queue")
 	ErrNoTaskToClaim            = errors.New("no task available to claim")
+	ErrSkipRetry                = errors.New("task must not be retried")
+	ErrTaskNotFound             = errors.New("task not found")
+	ErrTaskNotPending           = errors.New("task is not pending")
+	ErrDLQEntryNotFound         = errors.New("dead letter queue entry not found")
+	ErrDuplicateTask            = errors.New("task with the same unique key already exists")
+	ErrUniqueNotSupported       = errors.New("repository does not support unique tasks")
+	ErrInvalidUniqueConflict    = errors.New("invalid unique key conflict behavior")
+	ErrWorkflowNotSupported     = errors.New("repository does not support batches and chains")
+	ErrBatchNotFound            = errors.New("batch not found")
+	ErrHandlerPanic             = errors.New("task handler panicked")
+	ErrTaskTimeout              = errors.New("task execution timed out")
+	ErrTrackingNotSupported     = errors.New("repository does not support task tracking")
+	ErrTaskCancelled            = errors.New("task was cancelled")
+	ErrTaskFailed               = errors.New("task failed")
+	ErrReleaseNotSupported      = errors.New("repository does not support releasing tasks")
+	ErrPayloadEncrypted         = errors.New("task payload is encrypted and no payload cipher is configured")
+	ErrUnknownPayloadKey        = errors.New("unknown payload encryption key")
 )
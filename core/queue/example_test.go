@@ -37,7 +37,7 @@ func Example_oneTimeTask() {
 	}
 
 	// Enqueue task
-	err = enqueuer.Enqueue(context.Background(), payload)
+	_, err = enqueuer.Enqueue(context.Background(), payload)
 	if err != nil {
 		panic(err)
 	}
@@ -105,7 +105,7 @@ func Example_scheduledTask() {
 	}
 
 	// Schedule task for 50ms from now
-	err = enqueuer.Enqueue(context.Background(), payload,
+	_, err = enqueuer.Enqueue(context.Background(), payload,
 		queue.WithScheduledAt(time.Now().Add(50*time.Millisecond)))
 	if err != nil {
 		panic(err)
@@ -0,0 +1,59 @@
+package queue
+
+import (
+	"math/rand/v2"
+	"slices"
+)
+
+// queueMode selects how a worker chooses between its queues
+type queueMode int
+
+const (
+	// queueModePriority claims the highest priority task across all queues
+	queueModePriority queueMode = iota
+	// queueModeStrict drains queues in the configured order
+	queueModeStrict
+	// queueModeWeighted picks the queue order at random, in proportion to queue weights
+	queueModeWeighted
+)
+
+// queueOrder decides the order in which a worker tries its queues on every claim
+type queueOrder struct {
+	mode    queueMode
+	weights []int // Parallel to the worker queues in weighted mode
+}
+
+// next returns the queues in the order to claim from. In weighted mode each claim
+// draws a new order: a queue comes first with probability weight/total, so every
+// queue with pending tasks keeps its share even while heavier queues are busy.
+func (o queueOrder) next(queues []string) []string {
+	if o.mode != queueModeWeighted || len(queues) < 2 {
+		return queues
+	}
+
+	remaining := slices.Clone(queues)
+	weights := slices.Clone(o.weights)
+	total := 0
+	for _, w := range weights {
+		total += w
+	}
+
+	ordered := make([]string, 0, len(queues))
+	for len(remaining) > 1 {
+		n := rand.IntN(total)
+		i := 0
+		for ; n >= weights[i]; i++ {
+			n -= weights[i]
+		}
+		ordered = append(ordered, remaining[i])
+		total -= weights[i]
+		remaining = slices.Delete(remaining, i, i+1)
+		weights = slices.Delete(weights, i, i+1)
+	}
+	return append(ordered, remaining[0])
+}
+
+// claimsInOrder reports whether tasks are claimed by queue order rather than by priority
+func (o queueOrder) claimsInOrder() bool {
+	return o.mode != queueModePriority
+}
@@ -0,0 +1,176 @@
+package queue_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+type fairnessJob struct {
+	Queue string `json:"queue"`
+}
+
+// priorityClaimRepo hides ClaimTaskInOrder so the worker claims one queue at a time
+type priorityClaimRepo struct {
+	queue.WorkerRepository
+}
+
+func enqueueFairnessJobs(t *testing.T, storage *queue.MemoryStorage, queueName string, priority queue.Priority, n int) {
+	t.Helper()
+
+	enqueuer, err := queue.NewEnqueuer(storage)
+	require.NoError(t, err)
+	for range n {
+		mustEnqueue(t, enqueuer, fairnessJob{Queue: queueName}, queue.WithQueue(queueName), queue.WithPriority(priority))
+	}
+}
+
+// processingOrder runs a single slot worker until want tasks are processed
+// and returns the queue of every task in processing order
+func processingOrder(t *testing.T, repo queue.WorkerRepository, want int, opts ...queue.WorkerOption) []string {
+	t.Helper()
+
+	var mu sync.Mutex
+	var order []string
+	done := make(chan struct{})
+
+	worker, err := queue.NewWorker(repo, append([]queue.WorkerOption{
+		queue.WithPullInterval(time.Millisecond),
+		queue.WithMaxConcurrentTasks(1),
+	}, opts...)...)
+	require.NoError(t, err)
+	require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(func(ctx context.Context, job fairnessJob) error {
+		mu.Lock()
+		defer mu.Unlock()
+		order = append(order, job.Queue)
+		if len(order) == want {
+			close(done)
+		}
+		return nil
+	})))
+
+	require.NoError(t, worker.Start(context.Background()))
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("tasks were not processed in time")
+	}
+	require.NoError(t, worker.Stop())
+
+	mu.Lock()
+	defer mu.Unlock()
+	return slices.Clone(order)
+}
+
+func TestWorker_WeightedQueues(t *testing.T) {
+	t.Parallel()
+
+	t.Run("busy high priority queue does not starve others", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueueFairnessJobs(t, storage, "critical", queue.PriorityMax, 150)
+		enqueueFairnessJobs(t, storage, "low", queue.PriorityMin, 10)
+
+		order := processingOrder(t, storage, 160, queue.WithWeightedQueues(map[string]int{
+			"critical": 6,
+			"low":      1,
+		}))
+
+		// Low tasks run while critical still has a backlog, at roughly 1 of 7 claims
+		low := 0
+		for _, q := range order[:80] {
+			if q == "low" {
+				low++
+			}
+		}
+		assert.Positive(t, low, "low queue starved by critical")
+		assert.Equal(t, "critical", order[len(order)-1], "critical backlog outlives low queue")
+	})
+
+	t.Run("priority mode drains higher priority first", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueueFairnessJobs(t, storage, "critical", queue.PriorityMax, 20)
+		enqueueFairnessJobs(t, storage, "low", queue.PriorityMin, 5)
+
+		order := processingOrder(t, storage, 25, queue.WithQueues("critical", "low"))
+		assert.Equal(t, 20, slices.Index(order, "low"))
+	})
+
+	t.Run("takes over the whole worker when other queues are empty", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueueFairnessJobs(t, storage, "low", queue.PriorityMin, 5)
+
+		order := processingOrder(t, priorityClaimRepo{storage}, 5, queue.WithWeightedQueues(map[string]int{
+			"critical": 6,
+			"default":  3,
+			"low":      1,
+		}))
+		assert.Len(t, order, 5)
+	})
+
+	t.Run("ignores queues without weight", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueueFairnessJobs(t, storage, "disabled", queue.PriorityMax, 1)
+		enqueueFairnessJobs(t, storage, "default", queue.PriorityMin, 2)
+
+		order := processingOrder(t, storage, 2, queue.WithWeightedQueues(map[string]int{
+			"default":  1,
+			"disabled": 0,
+		}))
+		assert.Equal(t, []string{"default", "default"}, order)
+
+		pending, err := storage.CountTasks(context.Background(), queue.TaskFilter{Queue: "disabled", Status: queue.TaskStatusPending})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, pending)
+	})
+}
+
+func TestWorker_StrictQueues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		repo func(*queue.MemoryStorage) queue.WorkerRepository
+	}{
+		{"ordered claim", func(s *queue.MemoryStorage) queue.WorkerRepository { return s }},
+		{"claim per queue", func(s *queue.MemoryStorage) queue.WorkerRepository { return priorityClaimRepo{s} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage := queue.NewMemoryStorage()
+			t.Cleanup(func() { _ = storage.Close() })
+			// Queue order wins over task priority
+			enqueueFairnessJobs(t, storage, "low", queue.PriorityMax, 3)
+			enqueueFairnessJobs(t, storage, "default", queue.PriorityMedium, 3)
+			enqueueFairnessJobs(t, storage, "critical", queue.PriorityMin, 3)
+
+			order := processingOrder(t, tt.repo(storage), 9, queue.WithStrictQueues("critical", "default", "low"))
+			assert.Equal(t, []string{
+				"critical", "critical", "critical",
+				"default", "default", "default",
+				"low", "low", "low",
+			}, order)
+		})
+	}
+}
@@ -3,6 +3,9 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"time"
+
+	"github.com/dmitrymomot/foundation/pkg/ratelimiter"
 )
 
 type (
@@ -15,24 +18,99 @@ type (
 	PeriodicTaskHandlerFunc func(ctx context.Context) error
 )
 
-func NewTaskHandler[T any](handler TaskHandlerFunc[T]) Handler {
+// HandlerOption is a functional option for configuring a task handler
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	backoff     Backoff
+	middleware  []HandlerMiddleware
+	timeout     time.Duration
+	concurrency int
+	rateLimit   *ratelimiter.Bucket
+}
+
+// WithHandlerBackoff sets the retry backoff for tasks processed by the handler.
+// Overrides the worker default; a policy set on the task with WithBackoff takes precedence.
+func WithHandlerBackoff(backoff Backoff) HandlerOption {
+	return func(o *handlerOptions) {
+		if backoff != nil {
+			o.backoff = backoff
+		}
+	}
+}
+
+// WithHandlerTimeout sets the maximum execution time of tasks processed by the handler,
+// overriding the worker default set with WithTaskTimeout.
+func WithHandlerTimeout(d time.Duration) HandlerOption {
+	return func(o *handlerOptions) {
+		if d > 0 {
+			o.timeout = d
+		}
+	}
+}
+
+// WithHandlerMiddleware adds middleware applied only to tasks processed by the handler.
+// It runs inside the worker middleware; the first one is the outermost.
+func WithHandlerMiddleware(middleware ...HandlerMiddleware) HandlerOption {
+	return func(o *handlerOptions) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
+
+// WithHandlerConcurrency limits how many tasks of the handler a worker processes at once.
+// Tasks over the limit return to pending and are claimed again after the pull interval.
+func WithHandlerConcurrency(n int) HandlerOption {
+	return func(o *handlerOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithHandlerRateLimit limits the throughput of the handler's tasks. A token keyed
+// "task:<name>" is consumed for every task; throttled tasks return to pending
+// without counting a retry and run again once the limiter allows.
+func WithHandlerRateLimit(limiter *ratelimiter.Bucket) HandlerOption {
+	return func(o *handlerOptions) {
+		if limiter != nil {
+			o.rateLimit = limiter
+		}
+	}
+}
+
+// configuredHandler is implemented by handlers created with NewTaskHandler
+// and NewPeriodicTaskHandler to expose their options to the worker.
+type configuredHandler interface {
+	handlerOptions() *handlerOptions
+}
+
+func NewTaskHandler[T any](handler TaskHandlerFunc[T], opts ...HandlerOption) Handler {
 	var payload T
-	return &oneTimeTaskHandler[T]{
+	h := &oneTimeTaskHandler[T]{
 		name:    qualifiedStructName(payload),
 		handler: handler,
 	}
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+	return h
 }
 
-func NewPeriodicTaskHandler(name string, handler PeriodicTaskHandlerFunc) Handler {
-	return &periodicTaskHandler{
+func NewPeriodicTaskHandler(name string, handler PeriodicTaskHandlerFunc, opts ...HandlerOption) Handler {
+	h := &periodicTaskHandler{
 		name:    name,
 		handler: handler,
 	}
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+	return h
 }
 
 type oneTimeTaskHandler[T any] struct {
 	name    string
 	handler TaskHandlerFunc[T]
+	opts    handlerOptions
 }
 
 func (h *oneTimeTaskHandler[T]) Name() string {
@@ -47,9 +125,14 @@ func (h *oneTimeTaskHandler[T]) Handle(ctx context.Context, payload json.RawMess
 	return h.handler(ctx, t)
 }
 
+func (h *oneTimeTaskHandler[T]) handlerOptions() *handlerOptions {
+	return &h.opts
+}
+
 type periodicTaskHandler struct {
 	name    string
 	handler PeriodicTaskHandlerFunc
+	opts    handlerOptions
 }
 
 func (h *periodicTaskHandler) Name() string {
@@ -59,3 +142,7 @@ func (h *periodicTaskHandler) Name() string {
 func (h *periodicTaskHandler) Handle(ctx context.Context, _ json.RawMessage) error {
 	return h.handler(ctx)
 }
+
+func (h *periodicTaskHandler) handlerOptions() *handlerOptions {
+	return &h.opts
+}
@@ -0,0 +1,76 @@
+package queue
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// QueueInspector defines operational access to queue contents for admin tooling.
+// Implemented by MemoryStorage and the persistent storage backends.
+type QueueInspector interface {
+	// ListTasks returns tasks matching the filter, newest first
+	ListTasks(ctx context.Context, filter TaskFilter) ([]*Task, error)
+
+	// CountTasks returns the number of tasks matching the filter (Limit and Offset are ignored)
+	CountTasks(ctx context.Context, filter TaskFilter) (int64, error)
+
+	// GetTask returns a task by ID or ErrTaskNotFound
+	GetTask(ctx context.Context, taskID uuid.UUID) (*Task, error)
+
+	// CancelTask cancels a pending task; returns ErrTaskNotPending for tasks in other states
+	CancelTask(ctx context.Context, taskID uuid.UUID) error
+
+	// ListDLQ returns dead letter queue entries matching the filter, most recently failed first
+	ListDLQ(ctx context.Context, filter DLQFilter) ([]*TasksDlq, error)
+
+	// RequeueDLQ moves a dead letter queue entry back to the queue as a new pending attempt
+	// under its original task ID, with the retry count reset. Chain steps and the backoff
+	// policy are kept; the task rejoins its batch only while the batch is unfinished
+	RequeueDLQ(ctx context.Context, dlqID uuid.UUID) (*Task, error)
+
+	// PurgeDLQ deletes dead letter queue entries of the given queue (all queues when empty)
+	// and returns the number of deleted entries
+	PurgeDLQ(ctx context.Context, queue string) (int64, error)
+
+	// PauseQueue stops workers from claiming tasks of the queue; enqueueing still works
+	PauseQueue(ctx context.Context, queue string) error
+
+	// ResumeQueue allows workers to claim tasks of a paused queue again
+	ResumeQueue(ctx context.Context, queue string) error
+
+	// PausedQueues returns the names of all paused queues
+	PausedQueues(ctx context.Context) ([]string, error)
+}
+
+// TaskFilter narrows the tasks returned by QueueInspector.
+// Empty fields match everything; a zero Limit uses DefaultInspectLimit.
+type TaskFilter struct {
+	Queue  string     `json:"queue,omitempty"`
+	Status TaskStatus `json:"status,omitempty"`
+	Limit  int        `json:"limit,omitempty"`
+	Offset int        `json:"offset,omitempty"`
+}
+
+// DLQFilter narrows the dead letter queue entries returned by QueueInspector.
+// An empty Queue matches all queues; a zero Limit uses DefaultInspectLimit.
+type DLQFilter struct {
+	Queue  string `json:"queue,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+	Offset int    `json:"offset,omitempty"`
+}
+
+// Inspection page size limits
+const (
+	DefaultInspectLimit = 50
+	MaxInspectLimit     = 500
+)
+
+// NormalizeLimit returns a page size within (0, MaxInspectLimit], using DefaultInspectLimit for zero or negative values.
+// Storage backends use it so every implementation pages identically.
+func NormalizeLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultInspectLimit
+	}
+	return min(limit, MaxInspectLimit)
+}
@@ -0,0 +1,233 @@
+package queue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+func newInspectorTask(queueName string, createdAt time.Time) *queue.Task {
+	return &queue.Task{
+		ID:          uuid.New(),
+		Queue:       queueName,
+		TaskType:    queue.TaskTypeOneTime,
+		TaskName:    "test-task",
+		Payload:     []byte(`{}`),
+		Status:      queue.TaskStatusPending,
+		Priority:    queue.PriorityMedium,
+		MaxRetries:  5,
+		ScheduledAt: createdAt,
+		CreatedAt:   createdAt,
+	}
+}
+
+func TestMemoryStorage_ListTasks(t *testing.T) {
+	t.Parallel()
+
+	storage := queue.NewMemoryStorage()
+	defer storage.Close()
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour)
+	var ids []uuid.UUID
+	for i, q := range []string{"default", "emails", "default"} {
+		task := newInspectorTask(q, base.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, storage.CreateTask(ctx, task))
+		ids = append(ids, task.ID)
+	}
+
+	t.Run("lists all tasks newest first", func(t *testing.T) {
+		tasks, err := storage.ListTasks(ctx, queue.TaskFilter{})
+		require.NoError(t, err)
+		require.Len(t, tasks, 3)
+		assert.Equal(t, ids[2], tasks[0].ID)
+		assert.Equal(t, ids[0], tasks[2].ID)
+	})
+
+	t.Run("filters by queue and pages", func(t *testing.T) {
+		tasks, err := storage.ListTasks(ctx, queue.TaskFilter{Queue: "default", Limit: 1, Offset: 1})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, ids[0], tasks[0].ID)
+
+		count, err := storage.CountTasks(ctx, queue.TaskFilter{Queue: "default", Limit: 1})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, count)
+	})
+
+	t.Run("offset past the end returns empty page", func(t *testing.T) {
+		tasks, err := storage.ListTasks(ctx, queue.TaskFilter{Offset: 10})
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("filters by status", func(t *testing.T) {
+		require.NoError(t, storage.CancelTask(ctx, ids[1]))
+
+		tasks, err := storage.ListTasks(ctx, queue.TaskFilter{Status: queue.TaskStatusCancelled})
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, ids[1], tasks[0].ID)
+	})
+}
+
+func TestMemoryStorage_CancelTask(t *testing.T) {
+	t.Parallel()
+
+	storage := queue.NewMemoryStorage()
+	defer storage.Close()
+	ctx := context.Background()
+
+	task := newInspectorTask("default", time.Now())
+	require.NoError(t, storage.CreateTask(ctx, task))
+
+	require.NoError(t, storage.CancelTask(ctx, task.ID))
+
+	got, err := storage.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.TaskStatusCancelled, got.Status)
+	assert.NotNil(t, got.ProcessedAt)
+
+	_, err = storage.ClaimTask(ctx, uuid.New(), []string{"default"}, time.Minute)
+	assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+
+	assert.ErrorIs(t, storage.CancelTask(ctx, task.ID), queue.ErrTaskNotPending)
+	assert.ErrorIs(t, storage.CancelTask(ctx, uuid.New()), queue.ErrTaskNotFound)
+
+	_, err = storage.GetTask(ctx, uuid.New())
+	assert.ErrorIs(t, err, queue.ErrTaskNotFound)
+}
+
+func TestMemoryStorage_DLQManagement(t *testing.T) {
+	t.Parallel()
+
+	storage := queue.NewMemoryStorage()
+	defer storage.Close()
+	ctx := context.Background()
+	workerID := uuid.New()
+
+	deadLetter := func(queueName string) *queue.Task {
+		task := newInspectorTask(queueName, time.Now())
+		require.NoError(t, storage.CreateTask(ctx, task))
+		claimed, err := storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, storage.FailTask(ctx, claimed.ID, "boom", nil))
+		require.NoError(t, storage.MoveToDLQ(ctx, claimed.ID))
+		return task
+	}
+
+	t.Run("requeues entry under the original task ID", func(t *testing.T) {
+		task := deadLetter("requeue")
+
+		entries, err := storage.ListDLQ(ctx, queue.DLQFilter{Queue: "requeue"})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, task.ID, entries[0].TaskID)
+		assert.EqualValues(t, 5, entries[0].MaxRetries)
+
+		requeued, err := storage.RequeueDLQ(ctx, entries[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, task.ID, requeued.ID)
+		assert.Equal(t, queue.TaskStatusPending, requeued.Status)
+		assert.Zero(t, requeued.RetryCount)
+		assert.EqualValues(t, 5, requeued.MaxRetries)
+
+		entries, err = storage.ListDLQ(ctx, queue.DLQFilter{Queue: "requeue"})
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+
+		claimed, err := storage.ClaimTask(ctx, workerID, []string{"requeue"}, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, task.ID, claimed.ID)
+	})
+
+	t.Run("requeued batch task rejoins its open batch", func(t *testing.T) {
+		first := newInspectorTask("requeue-batch", time.Now())
+		second := newInspectorTask("requeue-batch", time.Now())
+		batch := &queue.Batch{ID: uuid.New(), Total: 2, CreatedAt: time.Now()}
+		first.BatchID, second.BatchID = &batch.ID, &batch.ID
+		require.NoError(t, storage.CreateBatch(ctx, batch, []*queue.Task{first, second}))
+
+		claimed, err := storage.ClaimTask(ctx, workerID, []string{"requeue-batch"}, time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, storage.FailTask(ctx, claimed.ID, "boom", nil))
+		require.NoError(t, storage.MoveToDLQ(ctx, claimed.ID))
+		_, err = storage.FinishBatchTask(ctx, batch.ID, false)
+		require.NoError(t, err)
+
+		entries, err := storage.ListDLQ(ctx, queue.DLQFilter{Queue: "requeue-batch"})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, &batch.ID, entries[0].BatchID)
+
+		requeued, err := storage.RequeueDLQ(ctx, entries[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, &batch.ID, requeued.BatchID)
+
+		current, err := storage.GetBatch(ctx, batch.ID)
+		require.NoError(t, err)
+		assert.Zero(t, current.Failed)
+		assert.Equal(t, 2, current.Pending())
+	})
+
+	t.Run("requeue of unknown entry fails", func(t *testing.T) {
+		_, err := storage.RequeueDLQ(ctx, uuid.New())
+		assert.ErrorIs(t, err, queue.ErrDLQEntryNotFound)
+	})
+
+	t.Run("purges entries of one queue", func(t *testing.T) {
+		deadLetter("purge-a")
+		deadLetter("purge-a")
+		deadLetter("purge-b")
+
+		purged, err := storage.PurgeDLQ(ctx, "purge-a")
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, purged)
+
+		entries, err := storage.ListDLQ(ctx, queue.DLQFilter{Queue: "purge-b"})
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
+
+func TestMemoryStorage_PauseQueue(t *testing.T) {
+	t.Parallel()
+
+	storage := queue.NewMemoryStorage()
+	defer storage.Close()
+	ctx := context.Background()
+	workerID := uuid.New()
+
+	require.NoError(t, storage.CreateTask(ctx, newInspectorTask("paused", time.Now())))
+	require.NoError(t, storage.PauseQueue(ctx, "paused"))
+
+	paused, err := storage.PausedQueues(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"paused"}, paused)
+
+	_, err = storage.ClaimTask(ctx, workerID, []string{"paused"}, time.Minute)
+	assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+
+	// Enqueueing into a paused queue still works
+	require.NoError(t, storage.CreateTask(ctx, newInspectorTask("paused", time.Now())))
+
+	require.NoError(t, storage.ResumeQueue(ctx, "paused"))
+
+	_, err = storage.ClaimTask(ctx, workerID, []string{"paused"}, time.Minute)
+	assert.NoError(t, err)
+}
+
+func TestNormalizeLimit(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, queue.DefaultInspectLimit, queue.NormalizeLimit(0))
+	assert.Equal(t, queue.DefaultInspectLimit, queue.NormalizeLimit(-1))
+	assert.Equal(t, 10, queue.NormalizeLimit(10))
+	assert.Equal(t, queue.MaxInspectLimit, queue.NormalizeLimit(queue.MaxInspectLimit+1))
+}
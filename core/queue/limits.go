@@ -0,0 +1,186 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dmitrymomot/foundation/pkg/ratelimiter"
+)
+
+// throttle enforces the concurrency caps and rate limits of a worker.
+// Concurrency is counted per worker instance; rate limits are shared by every
+// worker using a limiter backed by the same store.
+type throttle struct {
+	mu           sync.Mutex
+	queueCaps    map[string]int
+	queueRates   map[string]*ratelimiter.Bucket
+	running      map[string]int
+	blockedUntil map[string]time.Time
+	retryDelay   time.Duration
+}
+
+// handlerLimits holds the limits configured on a single handler
+type handlerLimits struct {
+	concurrency int
+	rate        *ratelimiter.Bucket
+}
+
+func newThrottle(caps map[string]int, rates map[string]*ratelimiter.Bucket, retryDelay time.Duration) *throttle {
+	return &throttle{
+		queueCaps:    caps,
+		queueRates:   rates,
+		running:      make(map[string]int),
+		blockedUntil: make(map[string]time.Time),
+		retryDelay:   retryDelay,
+	}
+}
+
+// enabled reports whether any queue limit is configured
+func (t *throttle) enabled() bool {
+	return len(t.queueCaps) > 0 || len(t.queueRates) > 0
+}
+
+// claimable returns the queues the worker may claim from right now. Queues at their
+// concurrency cap and queues whose rate limit is exhausted are skipped.
+func (t *throttle) claimable(queues []string, now time.Time) []string {
+	if !t.enabled() {
+		return queues
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result := make([]string, 0, len(queues))
+	for _, q := range queues {
+		key := queueLimitKey(q)
+		if n, ok := t.queueCaps[q]; ok && t.running[key] >= n {
+			continue
+		}
+		if until, ok := t.blockedUntil[key]; ok {
+			if now.Before(until) {
+				continue
+			}
+			delete(t.blockedUntil, key)
+		}
+		result = append(result, q)
+	}
+	return result
+}
+
+// acquire reserves a concurrency slot for the task's queue and handler and consumes
+// rate limit tokens. It returns the release function of the reservation, or the delay
+// after which the task should run again when a limit is reached.
+// Rate limiter errors don't block processing; they are returned with a valid reservation.
+func (t *throttle) acquire(ctx context.Context, task *Task, limits handlerLimits) (func(), time.Duration, error) {
+	queueKey, taskKey := queueLimitKey(task.Queue), taskLimitKey(task.TaskName)
+
+	t.mu.Lock()
+	if n, ok := t.queueCaps[task.Queue]; ok && t.running[queueKey] >= n {
+		t.mu.Unlock()
+		return nil, t.retryDelay, nil
+	}
+	if limits.concurrency > 0 && t.running[taskKey] >= limits.concurrency {
+		t.mu.Unlock()
+		return nil, t.retryDelay, nil
+	}
+	t.running[queueKey]++
+	t.running[taskKey]++
+	t.mu.Unlock()
+
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.decrement(queueKey)
+			t.decrement(taskKey)
+		})
+	}
+
+	var errs error
+	rates := []struct {
+		key     string
+		limiter *ratelimiter.Bucket
+	}{
+		{queueKey, t.queueRates[task.Queue]},
+		{taskKey, limits.rate},
+	}
+	for _, r := range rates {
+		if r.limiter == nil {
+			continue
+		}
+
+		delay, err := t.allow(ctx, r.key, r.limiter)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("rate limit %s: %w", r.key, err))
+			continue
+		}
+		if delay > 0 {
+			release()
+			return nil, delay, errs
+		}
+	}
+
+	return release, 0, errs
+}
+
+// allow consumes a token for the key and returns the delay until the next attempt when
+// none is available. The bucket is checked with Status first because a denied Allow still
+// consumes a token, so retrying throttled tasks would keep an empty bucket empty.
+func (t *throttle) allow(ctx context.Context, key string, limiter *ratelimiter.Bucket) (time.Duration, error) {
+	now := time.Now()
+
+	t.mu.Lock()
+	until, blocked := t.blockedUntil[key]
+	t.mu.Unlock()
+	if blocked && now.Before(until) {
+		return until.Sub(now), nil
+	}
+
+	status, err := limiter.Status(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if status.Remaining > 0 {
+		result, err := limiter.Allow(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if result.Allowed() {
+			return 0, nil
+		}
+		status = result
+	}
+
+	delay := max(time.Until(status.ResetAt), time.Millisecond)
+	t.block(key, now.Add(delay))
+	return delay, nil
+}
+
+// block excludes the key from claiming and rate limit checks until the given time
+func (t *throttle) block(key string, until time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.blockedUntil[key] = until
+}
+
+func (t *throttle) decrement(key string) {
+	if t.running[key] <= 1 {
+		delete(t.running, key)
+		return
+	}
+	t.running[key]--
+}
+
+// queueLimitKey is the concurrency counter and rate limiter key of a queue
+func queueLimitKey(queue string) string {
+	return "queue:" + queue
+}
+
+// taskLimitKey is the concurrency counter and rate limiter key of a task name
+func taskLimitKey(taskName string) string {
+	return "task:" + taskName
+}
@@ -0,0 +1,282 @@
+package queue_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+	"github.com/dmitrymomot/foundation/pkg/ratelimiter"
+)
+
+type limitEmail struct {
+	N int `json:"n"`
+}
+
+type limitInvoice struct {
+	N int `json:"n"`
+}
+
+// concurrencyTracker records the highest number of handlers running at once
+type concurrencyTracker struct {
+	running atomic.Int32
+	peak    atomic.Int32
+}
+
+func (c *concurrencyTracker) enter() {
+	n := c.running.Add(1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			return
+		}
+	}
+}
+
+func (c *concurrencyTracker) leave() {
+	c.running.Add(-1)
+}
+
+func newLimitBucket(t *testing.T, capacity int, interval time.Duration) *ratelimiter.Bucket {
+	t.Helper()
+
+	store := ratelimiter.NewMemoryStore()
+	t.Cleanup(store.Close)
+
+	bucket, err := ratelimiter.NewBucket(store, ratelimiter.Config{
+		Capacity:       capacity,
+		RefillRate:     1,
+		RefillInterval: interval,
+	})
+	require.NoError(t, err)
+	return bucket
+}
+
+func assertCompletedWithoutRetries(t *testing.T, storage *queue.MemoryStorage, want int) {
+	t.Helper()
+
+	require.Eventually(t, func() bool {
+		count, err := storage.CountTasks(context.Background(), queue.TaskFilter{Status: queue.TaskStatusCompleted})
+		return err == nil && count == int64(want)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	tasks, err := storage.ListTasks(context.Background(), queue.TaskFilter{Status: queue.TaskStatusCompleted})
+	require.NoError(t, err)
+	for _, task := range tasks {
+		assert.Zero(t, task.RetryCount, "throttled tasks must not count retries")
+		assert.Nil(t, task.Error)
+	}
+}
+
+func TestWorker_QueueConcurrency(t *testing.T) {
+	t.Parallel()
+
+	storage := queue.NewMemoryStorage()
+	t.Cleanup(func() { _ = storage.Close() })
+
+	var emails concurrencyTracker
+	release := make(chan struct{})
+	invoices := make(chan int, 2)
+
+	worker, err := queue.NewWorker(storage,
+		queue.WithQueues("emails", "billing"),
+		queue.WithPullInterval(5*time.Millisecond),
+		queue.WithMaxConcurrentTasks(4),
+		queue.WithQueueConcurrency("emails", 1),
+	)
+	require.NoError(t, err)
+	require.NoError(t, worker.RegisterHandlers(
+		queue.NewTaskHandler(func(ctx context.Context, p limitEmail) error {
+			emails.enter()
+			defer emails.leave()
+			<-release
+			return nil
+		}),
+		queue.NewTaskHandler(func(ctx context.Context, p limitInvoice) error {
+			invoices <- p.N
+			return nil
+		}),
+	))
+
+	enqueuer, err := queue.NewEnqueuer(storage)
+	require.NoError(t, err)
+	for i := range 3 {
+		mustEnqueue(t, enqueuer, limitEmail{N: i}, queue.WithQueue("emails"), queue.WithPriority(queue.PriorityHigh))
+	}
+	for i := range 2 {
+		mustEnqueue(t, enqueuer, limitInvoice{N: i}, queue.WithQueue("billing"), queue.WithPriority(queue.PriorityLow))
+	}
+
+	require.NoError(t, worker.Start(context.Background()))
+	t.Cleanup(func() { _ = worker.Stop() })
+
+	// Billing is processed while the emails queue holds its only slot
+	for range 2 {
+		select {
+		case <-invoices:
+		case <-time.After(5 * time.Second):
+			t.Fatal("billing queue was starved by emails")
+		}
+	}
+	assert.EqualValues(t, 1, emails.running.Load())
+
+	close(release)
+	assertCompletedWithoutRetries(t, storage, 5)
+	assert.EqualValues(t, 1, emails.peak.Load())
+}
+
+func TestWorker_HandlerConcurrency(t *testing.T) {
+	t.Parallel()
+
+	storage := queue.NewMemoryStorage()
+	t.Cleanup(func() { _ = storage.Close() })
+
+	var tracker concurrencyTracker
+	worker, err := queue.NewWorker(storage,
+		queue.WithPullInterval(5*time.Millisecond),
+		queue.WithMaxConcurrentTasks(6),
+	)
+	require.NoError(t, err)
+	require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(
+		func(ctx context.Context, p limitEmail) error {
+			tracker.enter()
+			defer tracker.leave()
+			time.Sleep(30 * time.Millisecond)
+			return nil
+		},
+		queue.WithHandlerConcurrency(2),
+	)))
+
+	enqueuer, err := queue.NewEnqueuer(storage)
+	require.NoError(t, err)
+	for i := range 6 {
+		mustEnqueue(t, enqueuer, limitEmail{N: i})
+	}
+
+	require.NoError(t, worker.Start(context.Background()))
+	t.Cleanup(func() { _ = worker.Stop() })
+
+	assertCompletedWithoutRetries(t, storage, 6)
+	assert.LessOrEqual(t, tracker.peak.Load(), int32(2))
+}
+
+func TestWorker_RateLimit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("handler rate limit", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		var mu sync.Mutex
+		var processedAt []time.Time
+
+		worker, err := queue.NewWorker(storage,
+			queue.WithPullInterval(5*time.Millisecond),
+			queue.WithMaxConcurrentTasks(4),
+		)
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(
+			func(ctx context.Context, p limitEmail) error {
+				mu.Lock()
+				processedAt = append(processedAt, time.Now())
+				mu.Unlock()
+				return nil
+			},
+			queue.WithHandlerRateLimit(newLimitBucket(t, 2, 100*time.Millisecond)),
+		)))
+
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+		for i := range 4 {
+			mustEnqueue(t, enqueuer, limitEmail{N: i})
+		}
+
+		start := time.Now()
+		require.NoError(t, worker.Start(context.Background()))
+		t.Cleanup(func() { _ = worker.Stop() })
+
+		assertCompletedWithoutRetries(t, storage, 4)
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, processedAt, 4)
+		// Burst of two, then one task per refill interval
+		assert.GreaterOrEqual(t, processedAt[3].Sub(start), 150*time.Millisecond)
+	})
+
+	t.Run("queue rate limit keeps throttled tasks pending", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		var processed atomic.Int32
+		worker, err := queue.NewWorker(storage,
+			queue.WithPullInterval(5*time.Millisecond),
+			queue.WithMaxConcurrentTasks(4),
+			queue.WithQueueRateLimit(queue.DefaultQueueName, newLimitBucket(t, 1, 200*time.Millisecond)),
+		)
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(
+			func(ctx context.Context, p limitEmail) error {
+				processed.Add(1)
+				return nil
+			},
+		)))
+
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+		for i := range 3 {
+			mustEnqueue(t, enqueuer, limitEmail{N: i})
+		}
+
+		require.NoError(t, worker.Start(context.Background()))
+		t.Cleanup(func() { _ = worker.Stop() })
+
+		require.Eventually(t, func() bool { return processed.Load() == 1 }, time.Second, 5*time.Millisecond)
+		time.Sleep(50 * time.Millisecond)
+		assert.EqualValues(t, 1, processed.Load())
+
+		failed, err := storage.CountTasks(context.Background(), queue.TaskFilter{Status: queue.TaskStatusFailed})
+		require.NoError(t, err)
+		assert.Zero(t, failed)
+
+		assertCompletedWithoutRetries(t, storage, 3)
+	})
+}
+
+func TestWorker_LimitsRequireRelease(t *testing.T) {
+	t.Parallel()
+
+	t.Run("queue limit", func(t *testing.T) {
+		t.Parallel()
+
+		worker, err := queue.NewWorker(&MockWorkerRepository{}, queue.WithQueueConcurrency(queue.DefaultQueueName, 1))
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(func(ctx context.Context, p limitEmail) error {
+			return nil
+		})))
+
+		assert.ErrorIs(t, worker.Start(context.Background()), queue.ErrReleaseNotSupported)
+	})
+
+	t.Run("handler limit", func(t *testing.T) {
+		t.Parallel()
+
+		worker, err := queue.NewWorker(&MockWorkerRepository{})
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(
+			func(ctx context.Context, p limitEmail) error { return nil },
+			queue.WithHandlerConcurrency(1),
+		)))
+
+		assert.ErrorIs(t, worker.Start(context.Background()), queue.ErrReleaseNotSupported)
+	})
+}
@@ -21,6 +21,14 @@ type MemoryStorage struct {
 	byQueue  map[string][]uuid.UUID
 	byStatus map[TaskStatus][]uuid.UUID
 
+	// Queues excluded from claiming
+	paused map[string]struct{}
+
+	// Unique key to the ID of the last task created with it
+	unique map[string]uuid.UUID
+
+	batches map[uuid.UUID]*Batch
+
 	// Lock management
 	lockTicker *time.Ticker
 	done       chan struct{}
@@ -33,6 +41,9 @@ func NewMemoryStorage() *MemoryStorage {
 		dlq:      make(map[uuid.UUID]*TasksDlq),
 		byQueue:  make(map[string][]uuid.UUID),
 		byStatus: make(map[TaskStatus][]uuid.UUID),
+		paused:   make(map[string]struct{}),
+		unique:   make(map[string]uuid.UUID),
+		batches:  make(map[uuid.UUID]*Batch),
 		done:     make(chan struct{}),
 	}
 
@@ -59,20 +70,44 @@ func (ms *MemoryStorage) CreateTask(ctx context.Context, task *Task) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	// Check if task already exists
-	if _, exists := ms.tasks[task.ID]; exists {
-		return fmt.Errorf("task with ID %s already exists", task.ID)
+	return ms.insertTask(task)
+}
+
+// CreateUniqueTask implements UniqueTaskRepository
+func (ms *MemoryStorage) CreateUniqueTask(ctx context.Context, task *Task, onConflict UniqueConflict) (uuid.UUID, error) {
+	if task == nil {
+		return uuid.Nil, errors.New("task cannot be nil")
+	}
+	if task.UniqueKey == nil {
+		return uuid.Nil, errors.New("task has no unique key")
 	}
 
-	// Clone task to prevent external modifications
-	taskCopy := *task
-	ms.tasks[task.ID] = &taskCopy
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
 
-	// Update indexes
-	ms.byQueue[task.Queue] = append(ms.byQueue[task.Queue], task.ID)
-	ms.byStatus[task.Status] = append(ms.byStatus[task.Status], task.ID)
+	key := *task.UniqueKey
+	if holderID, exists := ms.unique[key]; exists {
+		holder, exists := ms.tasks[holderID]
+		if exists && holder.holdsUniqueKey(time.Now()) {
+			switch onConflict {
+			case UniqueConflictSkip:
+				return holder.ID, nil
+			case UniqueConflictError:
+				return uuid.Nil, fmt.Errorf("%w: %s", ErrDuplicateTask, key)
+			case UniqueConflictReplace:
+				if holder.Status == TaskStatusPending {
+					ms.cancelPendingTask(holder)
+				}
+			}
+		}
+	}
 
-	return nil
+	if err := ms.insertTask(task); err != nil {
+		return uuid.Nil, err
+	}
+	ms.unique[key] = task.ID
+
+	return task.ID, nil
 }
 
 // ClaimTask implements WorkerRepository
@@ -81,6 +116,32 @@ func (ms *MemoryStorage) ClaimTask(ctx context.Context, workerID uuid.UUID, queu
 	defer ms.mu.Unlock()
 
 	now := time.Now()
+	task := ms.nextPendingTask(queues, now)
+	if task == nil {
+		return nil, ErrNoTaskToClaim
+	}
+
+	return ms.claimTask(task, workerID, lockDuration, now), nil
+}
+
+// ClaimTaskInOrder implements OrderedClaimRepository
+func (ms *MemoryStorage) ClaimTaskInOrder(ctx context.Context, workerID uuid.UUID, queues []string, lockDuration time.Duration) (*Task, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	now := time.Now()
+	for _, queue := range queues {
+		if task := ms.nextPendingTask([]string{queue}, now); task != nil {
+			return ms.claimTask(task, workerID, lockDuration, now), nil
+		}
+	}
+
+	return nil, ErrNoTaskToClaim
+}
+
+// nextPendingTask returns the task to claim next from the given queues, or nil if none is available.
+// Must be called with ms.mu held.
+func (ms *MemoryStorage) nextPendingTask(queues []string, now time.Time) *Task {
 	var bestTask *Task
 	var bestPriority Priority = -1
 
@@ -94,6 +155,11 @@ func (ms *MemoryStorage) ClaimTask(ctx context.Context, workerID uuid.UUID, queu
 			continue
 		}
 
+		// Skip tasks of paused queues
+		if _, paused := ms.paused[task.Queue]; paused {
+			continue
+		}
+
 		// Skip tasks scheduled for future execution (delayed tasks)
 		if task.ScheduledAt.After(now) {
 			continue
@@ -113,23 +179,24 @@ func (ms *MemoryStorage) ClaimTask(ctx context.Context, workerID uuid.UUID, queu
 		}
 	}
 
-	if bestTask == nil {
-		return nil, ErrNoTaskToClaim
-	}
+	return bestTask
+}
 
-	// Claim the task
+// claimTask locks a pending task for the worker and returns a copy of it.
+// Must be called with ms.mu held.
+func (ms *MemoryStorage) claimTask(task *Task, workerID uuid.UUID, lockDuration time.Duration, now time.Time) *Task {
 	lockUntil := now.Add(lockDuration)
-	bestTask.Status = TaskStatusProcessing
-	bestTask.LockedUntil = &lockUntil
-	bestTask.LockedBy = &workerID
+	task.Status = TaskStatusProcessing
+	task.LockedUntil = &lockUntil
+	task.LockedBy = &workerID
 
 	// Update status index
-	ms.removeFromStatusIndex(bestTask.ID, TaskStatusPending)
-	ms.byStatus[TaskStatusProcessing] = append(ms.byStatus[TaskStatusProcessing], bestTask.ID)
+	ms.removeFromStatusIndex(task.ID, TaskStatusPending)
+	ms.byStatus[TaskStatusProcessing] = append(ms.byStatus[TaskStatusProcessing], task.ID)
 
 	// Return a copy to prevent external modifications
-	taskCopy := *bestTask
-	return &taskCopy, nil
+	taskCopy := *task
+	return &taskCopy
 }
 
 // CompleteTask implements WorkerRepository
@@ -137,30 +204,19 @@ func (ms *MemoryStorage) CompleteTask(ctx context.Context, taskID uuid.UUID) err
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
-	task, exists := ms.tasks[taskID]
-	if !exists {
-		return fmt.Errorf("task %s not found", taskID)
-	}
-
-	if task.Status != TaskStatusProcessing {
-		return fmt.Errorf("task %s is not in processing state", taskID)
-	}
-
-	now := time.Now()
-	task.Status = TaskStatusCompleted
-	task.ProcessedAt = &now
-	task.LockedUntil = nil
-	task.LockedBy = nil
+	return ms.completeTask(taskID, nil)
+}
 
-	// Update status index
-	ms.removeFromStatusIndex(taskID, TaskStatusProcessing)
-	ms.byStatus[TaskStatusCompleted] = append(ms.byStatus[TaskStatusCompleted], taskID)
+// CompleteTaskWithResult implements ResultRepository
+func (ms *MemoryStorage) CompleteTaskWithResult(ctx context.Context, taskID uuid.UUID, result []byte) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
 
-	return nil
+	return ms.completeTask(taskID, result)
 }
 
 // FailTask implements WorkerRepository
-func (ms *MemoryStorage) FailTask(ctx context.Context, taskID uuid.UUID, errorMsg string) error {
+func (ms *MemoryStorage) FailTask(ctx context.Context, taskID uuid.UUID, errorMsg string, retryAt *time.Time) error {
 	ms.mu.Lock()
 	defer ms.mu.Unlock()
 
@@ -178,22 +234,42 @@ func (ms *MemoryStorage) FailTask(ctx context.Context, taskID uuid.UUID, errorMs
 	task.LockedUntil = nil
 	task.LockedBy = nil
 
-	if task.RetryCount >= task.MaxRetries {
+	if retryAt == nil {
 		task.Status = TaskStatusFailed
 		ms.removeFromStatusIndex(taskID, TaskStatusProcessing)
 		ms.byStatus[TaskStatusFailed] = append(ms.byStatus[TaskStatusFailed], taskID)
 	} else {
-		// Reset to pending for retry
+		// Reset to pending for retry at the time decided by the worker
 		task.Status = TaskStatusPending
+		task.ScheduledAt = *retryAt
 		ms.removeFromStatusIndex(taskID, TaskStatusProcessing)
 		ms.byStatus[TaskStatusPending] = append(ms.byStatus[TaskStatusPending], taskID)
+	}
+
+	return nil
+}
+
+// ReleaseTask implements ReleaseRepository
+func (ms *MemoryStorage) ReleaseTask(ctx context.Context, taskID uuid.UUID, scheduledAt time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	task, exists := ms.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("task %s not found", taskID)
+	}
 
-		// Apply exponential backoff to prevent thundering herd on persistent failures
-		// Linear progression: 30s, 60s, 90s... balances quick retry with system stability
-		backoff := time.Duration(task.RetryCount) * 30 * time.Second
-		task.ScheduledAt = time.Now().Add(backoff)
+	if task.Status != TaskStatusProcessing {
+		return fmt.Errorf("task %s is not in processing state", taskID)
 	}
 
+	task.Status = TaskStatusPending
+	task.ScheduledAt = scheduledAt
+	task.LockedUntil = nil
+	task.LockedBy = nil
+	ms.removeFromStatusIndex(taskID, TaskStatusProcessing)
+	ms.byStatus[TaskStatusPending] = append(ms.byStatus[TaskStatusPending], taskID)
+
 	return nil
 }
 
@@ -218,6 +294,10 @@ func (ms *MemoryStorage) MoveToDLQ(ctx context.Context, taskID uuid.UUID) error
 		Priority:   task.Priority,
 		Error:      "",
 		RetryCount: task.RetryCount,
+		MaxRetries: task.MaxRetries,
+		Backoff:    task.Backoff,
+		BatchID:    task.BatchID,
+		Next:       task.Next,
 		FailedAt:   time.Now(),
 		CreatedAt:  time.Now(),
 	}
@@ -275,8 +355,375 @@ func (ms *MemoryStorage) GetPendingTaskByName(ctx context.Context, taskName stri
 	return nil, nil
 }
 
+// ListTasks implements QueueInspector
+func (ms *MemoryStorage) ListTasks(ctx context.Context, filter TaskFilter) ([]*Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	matched := ms.filterTasks(filter)
+	slices.SortFunc(matched, func(a, b *Task) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return paginate(matched, filter.Offset, filter.Limit), nil
+}
+
+// CountTasks implements QueueInspector
+func (ms *MemoryStorage) CountTasks(ctx context.Context, filter TaskFilter) (int64, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	return int64(len(ms.filterTasks(filter))), nil
+}
+
+// GetTask implements QueueInspector
+func (ms *MemoryStorage) GetTask(ctx context.Context, taskID uuid.UUID) (*Task, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	task, exists := ms.tasks[taskID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+
+	taskCopy := *task
+	return &taskCopy, nil
+}
+
+// CancelTask implements QueueInspector
+func (ms *MemoryStorage) CancelTask(ctx context.Context, taskID uuid.UUID) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	task, exists := ms.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrTaskNotFound, taskID)
+	}
+
+	if task.Status != TaskStatusPending {
+		return fmt.Errorf("%w: task %s is %s", ErrTaskNotPending, taskID, task.Status)
+	}
+
+	ms.cancelPendingTask(task)
+
+	return nil
+}
+
+// ListDLQ implements QueueInspector
+func (ms *MemoryStorage) ListDLQ(ctx context.Context, filter DLQFilter) ([]*TasksDlq, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	entries := make([]*TasksDlq, 0, len(ms.dlq))
+	for _, entry := range ms.dlq {
+		if filter.Queue != "" && entry.Queue != filter.Queue {
+			continue
+		}
+		entryCopy := *entry
+		entries = append(entries, &entryCopy)
+	}
+
+	slices.SortFunc(entries, func(a, b *TasksDlq) int {
+		return b.FailedAt.Compare(a.FailedAt)
+	})
+
+	return paginate(entries, filter.Offset, filter.Limit), nil
+}
+
+// RequeueDLQ implements QueueInspector
+func (ms *MemoryStorage) RequeueDLQ(ctx context.Context, dlqID uuid.UUID) (*Task, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	entry, exists := ms.dlq[dlqID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrDLQEntryNotFound, dlqID)
+	}
+
+	if _, exists := ms.tasks[entry.TaskID]; exists {
+		return nil, fmt.Errorf("task with ID %s already exists", entry.TaskID)
+	}
+
+	now := time.Now()
+	task := &Task{
+		ID:          entry.TaskID,
+		Queue:       entry.Queue,
+		TaskType:    entry.TaskType,
+		TaskName:    entry.TaskName,
+		Payload:     entry.Payload,
+		Status:      TaskStatusPending,
+		Priority:    entry.Priority,
+		MaxRetries:  entry.MaxRetries,
+		Backoff:     entry.Backoff,
+		Next:        entry.Next,
+		ScheduledAt: now,
+		CreatedAt:   now,
+	}
+
+	// The failure was counted by the batch; an open batch counts the task as pending
+	// again, while a finished batch has already run its callback and is left alone
+	if entry.BatchID != nil {
+		if batch, exists := ms.batches[*entry.BatchID]; exists && !batch.Finished() && batch.Failed > 0 {
+			batch.Failed--
+			task.BatchID = entry.BatchID
+		}
+	}
+
+	ms.tasks[task.ID] = task
+	ms.byQueue[task.Queue] = append(ms.byQueue[task.Queue], task.ID)
+	ms.byStatus[TaskStatusPending] = append(ms.byStatus[TaskStatusPending], task.ID)
+	delete(ms.dlq, dlqID)
+
+	taskCopy := *task
+	return &taskCopy, nil
+}
+
+// PurgeDLQ implements QueueInspector
+func (ms *MemoryStorage) PurgeDLQ(ctx context.Context, queue string) (int64, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	var purged int64
+	for id, entry := range ms.dlq {
+		if queue == "" || entry.Queue == queue {
+			delete(ms.dlq, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
+
+// PauseQueue implements QueueInspector
+func (ms *MemoryStorage) PauseQueue(ctx context.Context, queue string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.paused[queue] = struct{}{}
+	return nil
+}
+
+// ResumeQueue implements QueueInspector
+func (ms *MemoryStorage) ResumeQueue(ctx context.Context, queue string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	delete(ms.paused, queue)
+	return nil
+}
+
+// PausedQueues implements QueueInspector
+func (ms *MemoryStorage) PausedQueues(ctx context.Context) ([]string, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	queues := make([]string, 0, len(ms.paused))
+	for q := range ms.paused {
+		queues = append(queues, q)
+	}
+	slices.Sort(queues)
+
+	return queues, nil
+}
+
+// CreateBatch implements WorkflowRepository
+func (ms *MemoryStorage) CreateBatch(ctx context.Context, batch *Batch, tasks []*Task) error {
+	if batch == nil {
+		return errors.New("batch cannot be nil")
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.batches[batch.ID]; exists {
+		return fmt.Errorf("batch with ID %s already exists", batch.ID)
+	}
+
+	// Validate before inserting so a failure leaves no partial batch behind
+	for _, task := range tasks {
+		if _, exists := ms.tasks[task.ID]; exists {
+			return fmt.Errorf("task with ID %s already exists", task.ID)
+		}
+	}
+
+	for _, task := range tasks {
+		if err := ms.insertTask(task); err != nil {
+			return err
+		}
+	}
+
+	batchCopy := *batch
+	ms.batches[batch.ID] = &batchCopy
+
+	return nil
+}
+
+// GetBatch implements WorkflowRepository
+func (ms *MemoryStorage) GetBatch(ctx context.Context, batchID uuid.UUID) (*Batch, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	batch, exists := ms.batches[batchID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrBatchNotFound, batchID)
+	}
+
+	batchCopy := *batch
+	return &batchCopy, nil
+}
+
+// FinishBatchTask implements WorkflowRepository
+func (ms *MemoryStorage) FinishBatchTask(ctx context.Context, batchID uuid.UUID, succeeded bool) (*Batch, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	batch, exists := ms.batches[batchID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrBatchNotFound, batchID)
+	}
+
+	if batch.Finished() {
+		return nil, fmt.Errorf("batch %s is already finished", batchID)
+	}
+
+	if succeeded {
+		batch.Succeeded++
+	} else {
+		batch.Failed++
+	}
+
+	if batch.Pending() == 0 {
+		now := time.Now()
+		batch.FinishedAt = &now
+		if callback := batch.CallbackTask(now); callback != nil {
+			if err := ms.insertTask(callback); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	batchCopy := *batch
+	return &batchCopy, nil
+}
+
+// CompleteTaskWithNext implements WorkflowRepository
+func (ms *MemoryStorage) CompleteTaskWithNext(ctx context.Context, taskID uuid.UUID, next *Task) error {
+	if next == nil {
+		return errors.New("next task cannot be nil")
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if _, exists := ms.tasks[next.ID]; exists {
+		return fmt.Errorf("task with ID %s already exists", next.ID)
+	}
+
+	if err := ms.completeTask(taskID, nil); err != nil {
+		return err
+	}
+
+	return ms.insertTask(next)
+}
+
 // Helper methods
 
+// completeTask marks a processing task as completed; must be called with the mutex held
+func (ms *MemoryStorage) completeTask(taskID uuid.UUID, result []byte) error {
+	task, exists := ms.tasks[taskID]
+	if !exists {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	if task.Status != TaskStatusProcessing {
+		return fmt.Errorf("task %s is not in processing state", taskID)
+	}
+
+	now := time.Now()
+	task.Status = TaskStatusCompleted
+	task.ProcessedAt = &now
+	task.Result = result
+	task.LockedUntil = nil
+	task.LockedBy = nil
+
+	// Update status index
+	ms.removeFromStatusIndex(taskID, TaskStatusProcessing)
+	ms.byStatus[TaskStatusCompleted] = append(ms.byStatus[TaskStatusCompleted], taskID)
+
+	return nil
+}
+
+// insertTask stores a copy of the task and indexes it; must be called with the mutex held
+func (ms *MemoryStorage) insertTask(task *Task) error {
+	// Check if task already exists
+	if _, exists := ms.tasks[task.ID]; exists {
+		return fmt.Errorf("task with ID %s already exists", task.ID)
+	}
+
+	// Clone task to prevent external modifications
+	taskCopy := *task
+	ms.tasks[task.ID] = &taskCopy
+
+	// Update indexes
+	ms.byQueue[task.Queue] = append(ms.byQueue[task.Queue], task.ID)
+	ms.byStatus[task.Status] = append(ms.byStatus[task.Status], task.ID)
+
+	return nil
+}
+
+// cancelPendingTask moves a pending task to cancelled; must be called with the mutex held
+func (ms *MemoryStorage) cancelPendingTask(task *Task) {
+	now := time.Now()
+	task.Status = TaskStatusCancelled
+	task.ProcessedAt = &now
+
+	ms.removeFromStatusIndex(task.ID, TaskStatusPending)
+	ms.byStatus[TaskStatusCancelled] = append(ms.byStatus[TaskStatusCancelled], task.ID)
+}
+
+// filterTasks returns copies of tasks matching the filter; must be called with the mutex held
+func (ms *MemoryStorage) filterTasks(filter TaskFilter) []*Task {
+	ids := make([]uuid.UUID, 0)
+	switch {
+	case filter.Status != "":
+		ids = ms.byStatus[filter.Status]
+	case filter.Queue != "":
+		ids = ms.byQueue[filter.Queue]
+	default:
+		for id := range ms.tasks {
+			ids = append(ids, id)
+		}
+	}
+
+	matched := make([]*Task, 0, len(ids))
+	for _, id := range ids {
+		task, exists := ms.tasks[id]
+		if !exists {
+			continue
+		}
+		if filter.Queue != "" && task.Queue != filter.Queue {
+			continue
+		}
+		if filter.Status != "" && task.Status != filter.Status {
+			continue
+		}
+		taskCopy := *task
+		matched = append(matched, &taskCopy)
+	}
+
+	return matched
+}
+
+// paginate applies offset and normalized limit to a sorted slice
+func paginate[T any](items []T, offset, limit int) []T {
+	offset = max(offset, 0)
+	if offset >= len(items) {
+		return []T{}
+	}
+	end := min(offset+NormalizeLimit(limit), len(items))
+	return items[offset:end]
+}
+
 func (ms *MemoryStorage) removeFromStatusIndex(taskID uuid.UUID, status TaskStatus) {
 	ms.byStatus[status] = slices.DeleteFunc(ms.byStatus[status], func(id uuid.UUID) bool {
 		return id == taskID
@@ -197,6 +197,49 @@ func TestMemoryStorage_ClaimTask(t *testing.T) {
 	})
 }
 
+func TestMemoryStorage_ClaimTaskInOrder(t *testing.T) {
+	storage := queue.NewMemoryStorage()
+	defer storage.Close()
+
+	newTask := func(queueName string, priority queue.Priority, scheduledAt time.Time) *queue.Task {
+		task := &queue.Task{
+			ID:          uuid.New(),
+			Queue:       queueName,
+			TaskType:    queue.TaskTypeOneTime,
+			TaskName:    "test-task",
+			Status:      queue.TaskStatusPending,
+			Priority:    priority,
+			ScheduledAt: scheduledAt,
+			CreatedAt:   time.Now(),
+		}
+		require.NoError(t, storage.CreateTask(context.Background(), task))
+		return task
+	}
+
+	past := time.Now().Add(-time.Minute)
+	urgent := newTask("low", queue.PriorityMax, past)
+	first := newTask("critical", queue.PriorityLow, past)
+	second := newTask("critical", queue.PriorityHigh, past)
+	newTask("critical", queue.PriorityMax, time.Now().Add(time.Hour))
+
+	queues := []string{"critical", "low"}
+	var claimed []*queue.Task
+	for range 3 {
+		task, err := storage.ClaimTaskInOrder(context.Background(), uuid.New(), queues, time.Minute)
+		require.NoError(t, err)
+		claimed = append(claimed, task)
+	}
+
+	// Queue order first, then priority within the queue; delayed tasks are skipped
+	assert.Equal(t, second.ID, claimed[0].ID)
+	assert.Equal(t, first.ID, claimed[1].ID)
+	assert.Equal(t, urgent.ID, claimed[2].ID)
+	assert.Equal(t, queue.TaskStatusProcessing, claimed[0].Status)
+
+	_, err := storage.ClaimTaskInOrder(context.Background(), uuid.New(), queues, time.Minute)
+	assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+}
+
 func TestMemoryStorage_CompleteTask(t *testing.T) {
 	storage := queue.NewMemoryStorage()
 	defer storage.Close()
@@ -254,6 +297,60 @@ func TestMemoryStorage_CompleteTask(t *testing.T) {
 	})
 }
 
+func TestMemoryStorage_ReleaseTask(t *testing.T) {
+	storage := queue.NewMemoryStorage()
+	defer storage.Close()
+
+	t.Run("returns task to pending without counting a retry", func(t *testing.T) {
+		task := &queue.Task{
+			ID:          uuid.New(),
+			Queue:       "release",
+			TaskType:    queue.TaskTypeOneTime,
+			TaskName:    "test-task",
+			Status:      queue.TaskStatusPending,
+			Priority:    queue.PriorityMedium,
+			ScheduledAt: time.Now().Add(-time.Minute),
+			CreatedAt:   time.Now(),
+		}
+		require.NoError(t, storage.CreateTask(context.Background(), task))
+
+		claimed, err := storage.ClaimTask(context.Background(), uuid.New(), []string{"release"}, 5*time.Minute)
+		require.NoError(t, err)
+
+		require.NoError(t, storage.ReleaseTask(context.Background(), claimed.ID, time.Now().Add(time.Hour)))
+
+		released, err := storage.GetTask(context.Background(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, queue.TaskStatusPending, released.Status)
+		assert.Zero(t, released.RetryCount)
+		assert.Nil(t, released.LockedBy)
+		assert.Nil(t, released.LockedUntil)
+		assert.Nil(t, released.Error)
+
+		// Not claimable before the new schedule time
+		_, err = storage.ClaimTask(context.Background(), uuid.New(), []string{"release"}, 5*time.Minute)
+		assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+	})
+
+	t.Run("fails on non-processing task", func(t *testing.T) {
+		task := &queue.Task{
+			ID:          uuid.New(),
+			Queue:       queue.DefaultQueueName,
+			TaskType:    queue.TaskTypeOneTime,
+			TaskName:    "test-task",
+			Status:      queue.TaskStatusPending,
+			Priority:    queue.PriorityMedium,
+			ScheduledAt: time.Now(),
+			CreatedAt:   time.Now(),
+		}
+		require.NoError(t, storage.CreateTask(context.Background(), task))
+
+		err := storage.ReleaseTask(context.Background(), task.ID, time.Now())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not in processing state")
+	})
+}
+
 func TestMemoryStorage_FailTask(t *testing.T) {
 	storage := queue.NewMemoryStorage()
 	defer storage.Close()
@@ -278,7 +375,8 @@ func TestMemoryStorage_FailTask(t *testing.T) {
 		claimed, err := storage.ClaimTask(context.Background(), workerID, []string{queue.DefaultQueueName}, 5*time.Minute)
 		require.NoError(t, err)
 
-		err = storage.FailTask(context.Background(), claimed.ID, "test error")
+		retryAt := time.Now().Add(30 * time.Second)
+		err = storage.FailTask(context.Background(), claimed.ID, "test error", &retryAt)
 		require.NoError(t, err)
 
 		// Task should be claimable again but with backoff
@@ -288,7 +386,7 @@ func TestMemoryStorage_FailTask(t *testing.T) {
 		assert.Nil(t, claimed2)
 	})
 
-	t.Run("fails task permanently after max retries", func(t *testing.T) {
+	t.Run("fails task permanently without retry time", func(t *testing.T) {
 		task := &queue.Task{
 			ID:          uuid.New(),
 			Queue:       queue.DefaultQueueName,
@@ -308,7 +406,7 @@ func TestMemoryStorage_FailTask(t *testing.T) {
 		claimed, err := storage.ClaimTask(context.Background(), workerID, []string{queue.DefaultQueueName}, 5*time.Minute)
 		require.NoError(t, err)
 
-		err = storage.FailTask(context.Background(), claimed.ID, "final error")
+		err = storage.FailTask(context.Background(), claimed.ID, "final error", nil)
 		require.NoError(t, err)
 
 		// Task should not be claimable (failed permanently)
@@ -0,0 +1,36 @@
+package queue
+
+import "time"
+
+// Metrics receives task lifecycle events from the enqueuer, worker and scheduler.
+// Implementations must be safe for concurrent use and return quickly: they are called
+// on the enqueue and processing paths. PrometheusMetrics is a ready to use implementation.
+type Metrics interface {
+	// TaskEnqueued is called after a task is stored, including periodic tasks created
+	// by the scheduler and chain steps created by the worker
+	TaskEnqueued(queue, taskName string)
+
+	// TaskStarted is called before the handler runs; wait is the time the task spent
+	// due in the queue, from its scheduled time until it was claimed
+	TaskStarted(queue, taskName string, wait time.Duration)
+
+	// TaskSucceeded is called after a task completed, with the handler duration
+	TaskSucceeded(queue, taskName string, duration time.Duration)
+
+	// TaskFailed is called for every failed attempt, with the handler duration,
+	// whether or not the task is retried
+	TaskFailed(queue, taskName string, duration time.Duration)
+
+	// TaskMovedToDLQ is called after a task that will not be retried is moved
+	// to the dead letter queue
+	TaskMovedToDLQ(queue, taskName string)
+}
+
+// noopMetrics is the default Metrics discarding every event
+type noopMetrics struct{}
+
+func (noopMetrics) TaskEnqueued(string, string)                 {}
+func (noopMetrics) TaskStarted(string, string, time.Duration)   {}
+func (noopMetrics) TaskSucceeded(string, string, time.Duration) {}
+func (noopMetrics) TaskFailed(string, string, time.Duration)    {}
+func (noopMetrics) TaskMovedToDLQ(string, string)               {}
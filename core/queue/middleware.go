@@ -0,0 +1,136 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/dmitrymomot/foundation/core/logger"
+)
+
+// HandleFunc processes a claimed task. It is the unit wrapped by HandlerMiddleware;
+// the innermost HandleFunc calls Handler.Handle with the task payload.
+// Middleware must not modify the task.
+type HandleFunc func(ctx context.Context, task *Task) error
+
+// HandlerMiddleware wraps task handlers for cross-cutting concerns such as logging,
+// panic recovery, tracing and metrics, like handler.Middleware does for HTTP handlers.
+//
+// Example:
+//
+//	func Tracing(tracer Tracer) queue.HandlerMiddleware {
+//		return func(next queue.HandleFunc) queue.HandleFunc {
+//			return func(ctx context.Context, task *queue.Task) error {
+//				ctx, span := tracer.Start(ctx, task.TaskName)
+//				defer span.End()
+//				return next(ctx, task)
+//			}
+//		}
+//	}
+//
+// Register middleware for all handlers with WithWorkerMiddleware and for a single
+// handler with WithHandlerMiddleware.
+type HandlerMiddleware func(next HandleFunc) HandleFunc
+
+// chainMiddleware wraps h so the first middleware is the outermost one
+func chainMiddleware(h HandleFunc, middleware ...HandlerMiddleware) HandleFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// LoggingMiddleware logs every task execution with its outcome and duration.
+// Successful tasks are logged at info level, failed ones at error level.
+// A nil logger uses slog.Default().
+func LoggingMiddleware(log *slog.Logger) HandlerMiddleware {
+	if log == nil {
+		log = slog.Default()
+	}
+
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, task *Task) error {
+			start := time.Now()
+			attrs := []slog.Attr{
+				logger.Component("queue"),
+				logger.ID("task_id", task.ID.String()),
+				slog.String("task_name", task.TaskName),
+				slog.String("queue", task.Queue),
+				logger.RetryCount(int(task.RetryCount)),
+			}
+
+			log.LogAttrs(ctx, slog.LevelDebug, "task started", append(attrs, logger.Event("task_started"))...)
+
+			err := next(ctx, task)
+
+			attrs = append(attrs, logger.Duration(time.Since(start)))
+			if err != nil {
+				log.LogAttrs(ctx, slog.LevelError, "task handler failed",
+					append(attrs, logger.Event("task_failed"), logger.Error(err))...)
+				return err
+			}
+
+			log.LogAttrs(ctx, slog.LevelInfo, "task handler succeeded", append(attrs, logger.Event("task_succeeded"))...)
+			return nil
+		}
+	}
+}
+
+// RecoveryMiddleware converts a panic in the handler into an error wrapping ErrHandlerPanic,
+// so the task follows the regular retry and dead letter queue flow. The worker recovers
+// panics on its own as well; this middleware lets outer middleware such as
+// LoggingMiddleware observe the panic as an ordinary error.
+func RecoveryMiddleware() HandlerMiddleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(ctx context.Context, task *Task) (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					if rErr, ok := r.(error); ok {
+						err = fmt.Errorf("%w: %w", ErrHandlerPanic, rErr)
+						return
+					}
+					err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+				}
+			}()
+
+			return next(ctx, task)
+		}
+	}
+}
+
+// TimeoutMiddleware limits the execution time of each task. The handler context is
+// cancelled after d; handlers must honor ctx.Done() for the limit to take effect.
+// Errors returned after the deadline wrap ErrTaskTimeout and context.DeadlineExceeded.
+// Non-positive durations disable the middleware.
+func TimeoutMiddleware(d time.Duration) HandlerMiddleware {
+	return func(next HandleFunc) HandleFunc {
+		if d <= 0 {
+			return next
+		}
+
+		return func(ctx context.Context, task *Task) error {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			err := next(ctx, task)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return timeoutError(d, err)
+			}
+			return err
+		}
+	}
+}
+
+// timeoutError marks a handler error returned after the execution deadline,
+// so the task records a timeout rather than the handler's own error
+func timeoutError(d time.Duration, err error) error {
+	if errors.Is(err, ErrTaskTimeout) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s: %w", ErrTaskTimeout, d, err)
+	}
+	return fmt.Errorf("%w after %s: %w: %w", ErrTaskTimeout, d, context.DeadlineExceeded, err)
+}
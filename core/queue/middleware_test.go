@@ -0,0 +1,268 @@
+package queue_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+type middlewarePayload struct {
+	Value string `json:"value"`
+}
+
+func recordingMiddleware(mu *sync.Mutex, calls *[]string, name string) queue.HandlerMiddleware {
+	return func(next queue.HandleFunc) queue.HandleFunc {
+		return func(ctx context.Context, task *queue.Task) error {
+			mu.Lock()
+			*calls = append(*calls, name+":before")
+			mu.Unlock()
+
+			err := next(ctx, task)
+
+			mu.Lock()
+			*calls = append(*calls, name+":after")
+			mu.Unlock()
+			return err
+		}
+	}
+}
+
+func TestWorker_Middleware(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("worker middleware wraps handler middleware in registration order", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		var mu sync.Mutex
+		var calls []string
+		done := make(chan struct{})
+
+		worker, err := queue.NewWorker(storage,
+			queue.WithPullInterval(5*time.Millisecond),
+			queue.WithWorkerMiddleware(
+				recordingMiddleware(&mu, &calls, "global1"),
+				recordingMiddleware(&mu, &calls, "global2"),
+			),
+		)
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(
+			func(ctx context.Context, p middlewarePayload) error {
+				mu.Lock()
+				calls = append(calls, "handler")
+				mu.Unlock()
+				close(done)
+				return nil
+			},
+			queue.WithHandlerMiddleware(recordingMiddleware(&mu, &calls, "local")),
+		)))
+		require.NoError(t, worker.Start(ctx))
+		t.Cleanup(func() { _ = worker.Stop() })
+
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+		mustEnqueue(t, enqueuer, middlewarePayload{Value: "a"})
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("task was not processed")
+		}
+
+		require.Eventually(t, func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(calls) == 7
+		}, time.Second, 5*time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{
+			"global1:before", "global2:before", "local:before",
+			"handler",
+			"local:after", "global2:after", "global1:after",
+		}, calls)
+	})
+
+	t.Run("middleware can short circuit the handler", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		rejected := errors.New("rejected by middleware")
+		worker, err := queue.NewWorker(storage,
+			queue.WithPullInterval(5*time.Millisecond),
+			queue.WithWorkerMiddleware(func(next queue.HandleFunc) queue.HandleFunc {
+				return func(ctx context.Context, task *queue.Task) error {
+					return queue.NoRetry(rejected)
+				}
+			}),
+		)
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(
+			func(ctx context.Context, p middlewarePayload) error {
+				t.Error("handler must not run")
+				return nil
+			},
+		)))
+		require.NoError(t, worker.Start(ctx))
+		t.Cleanup(func() { _ = worker.Stop() })
+
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+		mustEnqueue(t, enqueuer, middlewarePayload{Value: "a"})
+
+		require.Eventually(t, func() bool {
+			entries, err := storage.ListDLQ(ctx, queue.DLQFilter{})
+			return err == nil && len(entries) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		entries, err := storage.ListDLQ(ctx, queue.DLQFilter{})
+		require.NoError(t, err)
+		assert.Contains(t, entries[0].Error, rejected.Error())
+	})
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	t.Parallel()
+
+	task := &queue.Task{ID: uuid.New(), TaskName: "panicky"}
+	mw := queue.RecoveryMiddleware()
+
+	t.Run("converts panic value to error", func(t *testing.T) {
+		t.Parallel()
+
+		err := mw(func(ctx context.Context, task *queue.Task) error {
+			panic("boom")
+		})(context.Background(), task)
+
+		require.ErrorIs(t, err, queue.ErrHandlerPanic)
+		assert.Contains(t, err.Error(), "boom")
+	})
+
+	t.Run("keeps panicked error in chain", func(t *testing.T) {
+		t.Parallel()
+
+		cause := errors.New("cause")
+		err := mw(func(ctx context.Context, task *queue.Task) error {
+			panic(cause)
+		})(context.Background(), task)
+
+		assert.ErrorIs(t, err, queue.ErrHandlerPanic)
+		assert.ErrorIs(t, err, cause)
+	})
+
+	t.Run("passes through regular results", func(t *testing.T) {
+		t.Parallel()
+
+		cause := errors.New("regular")
+		err := mw(func(ctx context.Context, task *queue.Task) error {
+			return cause
+		})(context.Background(), task)
+
+		assert.Equal(t, cause, err)
+	})
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	t.Parallel()
+
+	task := &queue.Task{ID: uuid.New(), TaskName: "slow"}
+
+	t.Run("cancels handler context after timeout", func(t *testing.T) {
+		t.Parallel()
+
+		err := queue.TimeoutMiddleware(20*time.Millisecond)(func(ctx context.Context, task *queue.Task) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})(context.Background(), task)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("marks errors returned after the deadline", func(t *testing.T) {
+		t.Parallel()
+
+		cause := errors.New("gave up")
+		err := queue.TimeoutMiddleware(20*time.Millisecond)(func(ctx context.Context, task *queue.Task) error {
+			<-ctx.Done()
+			return cause
+		})(context.Background(), task)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.ErrorIs(t, err, cause)
+	})
+
+	t.Run("fast handler is not affected", func(t *testing.T) {
+		t.Parallel()
+
+		err := queue.TimeoutMiddleware(time.Second)(func(ctx context.Context, task *queue.Task) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			return nil
+		})(context.Background(), task)
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("non-positive timeout is a no-op", func(t *testing.T) {
+		t.Parallel()
+
+		parent, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := queue.TimeoutMiddleware(0)(func(ctx context.Context, task *queue.Task) error {
+			assert.Equal(t, parent, ctx)
+			return nil
+		})(parent, task)
+
+		assert.NoError(t, err)
+	})
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	log := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	mw := queue.LoggingMiddleware(log)
+
+	task := &queue.Task{ID: uuid.New(), TaskName: "report", Queue: "default", RetryCount: 2}
+
+	require.NoError(t, mw(func(ctx context.Context, task *queue.Task) error {
+		return nil
+	})(context.Background(), task))
+
+	out := buf.String()
+	assert.Contains(t, out, `"msg":"task started"`)
+	assert.Contains(t, out, `"msg":"task handler succeeded"`)
+	assert.Contains(t, out, `"task_id":"`+task.ID.String()+`"`)
+	assert.Contains(t, out, `"task_name":"report"`)
+	assert.Contains(t, out, `"retry_count":2`)
+	assert.Contains(t, out, `"component":"queue"`)
+
+	buf.Reset()
+	cause := errors.New("boom")
+	err := mw(func(ctx context.Context, task *queue.Task) error {
+		return cause
+	})(context.Background(), task)
+
+	assert.Equal(t, cause, err)
+	assert.Contains(t, buf.String(), `"level":"ERROR"`)
+	assert.Contains(t, buf.String(), `"error":"boom"`)
+}
@@ -0,0 +1,351 @@
+package queue
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDurationBuckets are the histogram buckets in seconds used for task wait
+// and processing durations unless WithDurationBuckets sets others
+var DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// depthStatuses are the task states reported by the queue depth gauge
+var depthStatuses = []TaskStatus{TaskStatusPending, TaskStatusProcessing}
+
+// PrometheusMetrics implements Metrics and serves the collected values in the
+// Prometheus text exposition format, without depending on the Prometheus client.
+// Counters and histograms cover the events of the components sharing the instance;
+// queue depth is read from the storage on every scrape.
+//
+// Example:
+//
+//	metrics := queue.NewPrometheusMetrics(
+//		queue.WithQueueDepth(storage, "default", "emails"),
+//	)
+//	enqueuer, _ := queue.NewEnqueuer(storage, queue.WithEnqueuerMetrics(metrics))
+//	worker, _ := queue.NewWorker(storage, queue.WithWorkerMetrics(metrics))
+//	mux.Handle("GET /metrics", metrics)
+//
+// Exposed metrics, prefixed with the namespace ("queue" by default):
+//
+//	queue_tasks_enqueued_total{queue,task_name}             counter
+//	queue_tasks_succeeded_total{queue,task_name}            counter
+//	queue_tasks_failed_total{queue,task_name}               counter
+//	queue_tasks_dead_total{queue,task_name}                 counter
+//	queue_task_wait_seconds{queue,task_name}                histogram
+//	queue_task_duration_seconds{queue,task_name,status}     histogram
+//	queue_depth{queue,status}                               gauge
+//	queue_depth_errors_total                                counter
+type PrometheusMetrics struct {
+	namespace string
+	buckets   []float64
+	inspector QueueInspector
+	queues    []string
+
+	mu          sync.Mutex
+	enqueued    map[taskSeries]uint64
+	succeeded   map[taskSeries]uint64
+	failed      map[taskSeries]uint64
+	dead        map[taskSeries]uint64
+	wait        map[taskSeries]*histogram
+	duration    map[durationSeries]*histogram
+	seenQueues  map[string]struct{}
+	depthErrors uint64
+}
+
+// PrometheusOption is a functional option for configuring PrometheusMetrics
+type PrometheusOption func(*PrometheusMetrics)
+
+// WithMetricsNamespace sets the prefix of all metric names
+func WithMetricsNamespace(namespace string) PrometheusOption {
+	return func(m *PrometheusMetrics) {
+		if namespace != "" {
+			m.namespace = namespace
+		}
+	}
+}
+
+// WithDurationBuckets sets the upper bounds in seconds of the duration histogram buckets
+func WithDurationBuckets(buckets ...float64) PrometheusOption {
+	return func(m *PrometheusMetrics) {
+		if len(buckets) > 0 {
+			m.buckets = slices.Sorted(slices.Values(buckets))
+		}
+	}
+}
+
+// WithQueueDepth reports the number of pending and processing tasks per queue,
+// counted with the inspector on every scrape. Queues seen in recorded events are
+// reported in addition to the given ones.
+func WithQueueDepth(inspector QueueInspector, queues ...string) PrometheusOption {
+	return func(m *PrometheusMetrics) {
+		m.inspector = inspector
+		m.queues = append(m.queues, queues...)
+	}
+}
+
+type taskSeries struct {
+	queue    string
+	taskName string
+}
+
+type durationSeries struct {
+	taskSeries
+	status string
+}
+
+// histogram holds per-bucket observation counts; exposition makes them cumulative
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(buckets []float64, v float64) {
+	if i, _ := slices.BinarySearch(buckets, v); i < len(buckets) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// NewPrometheusMetrics creates an empty collector
+func NewPrometheusMetrics(opts ...PrometheusOption) *PrometheusMetrics {
+	m := &PrometheusMetrics{
+		namespace:  "queue",
+		buckets:    DefaultDurationBuckets,
+		enqueued:   make(map[taskSeries]uint64),
+		succeeded:  make(map[taskSeries]uint64),
+		failed:     make(map[taskSeries]uint64),
+		dead:       make(map[taskSeries]uint64),
+		wait:       make(map[taskSeries]*histogram),
+		duration:   make(map[durationSeries]*histogram),
+		seenQueues: make(map[string]struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// TaskEnqueued implements Metrics
+func (m *PrometheusMetrics) TaskEnqueued(queue, taskName string) {
+	m.count(m.enqueued, queue, taskName)
+}
+
+// TaskStarted implements Metrics
+func (m *PrometheusMetrics) TaskStarted(queue, taskName string, wait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	series := taskSeries{queue: queue, taskName: taskName}
+	histogramOf(m.wait, series, len(m.buckets)).observe(m.buckets, max(wait, 0).Seconds())
+	m.seenQueues[queue] = struct{}{}
+}
+
+// TaskSucceeded implements Metrics
+func (m *PrometheusMetrics) TaskSucceeded(queue, taskName string, duration time.Duration) {
+	m.observeDuration(queue, taskName, "succeeded", duration)
+	m.count(m.succeeded, queue, taskName)
+}
+
+// TaskFailed implements Metrics
+func (m *PrometheusMetrics) TaskFailed(queue, taskName string, duration time.Duration) {
+	m.observeDuration(queue, taskName, "failed", duration)
+	m.count(m.failed, queue, taskName)
+}
+
+// TaskMovedToDLQ implements Metrics
+func (m *PrometheusMetrics) TaskMovedToDLQ(queue, taskName string) {
+	m.count(m.dead, queue, taskName)
+}
+
+func (m *PrometheusMetrics) count(counter map[taskSeries]uint64, queue, taskName string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counter[taskSeries{queue: queue, taskName: taskName}]++
+	m.seenQueues[queue] = struct{}{}
+}
+
+func (m *PrometheusMetrics) observeDuration(queue, taskName, status string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	series := durationSeries{taskSeries: taskSeries{queue: queue, taskName: taskName}, status: status}
+	histogramOf(m.duration, series, len(m.buckets)).observe(m.buckets, duration.Seconds())
+}
+
+// histogramOf returns the histogram of the series, creating it on first use
+func histogramOf[K comparable](series map[K]*histogram, key K, buckets int) *histogram {
+	h, ok := series[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, buckets)}
+		series[key] = h
+	}
+	return h
+}
+
+// ServeHTTP implements http.Handler, writing all metrics in the Prometheus text format
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := m.WriteMetrics(r.Context(), w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// WriteMetrics writes all metrics in the Prometheus text format.
+// Queue depth is skipped and counted in queue_depth_errors_total when the storage fails.
+func (m *PrometheusMetrics) WriteMetrics(ctx context.Context, w io.Writer) error {
+	depth := m.collectDepth(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	m.writeCounter(bw, "tasks_enqueued_total", "Tasks stored for processing.", m.enqueued)
+	m.writeCounter(bw, "tasks_succeeded_total", "Tasks processed successfully.", m.succeeded)
+	m.writeCounter(bw, "tasks_failed_total", "Failed task attempts, including retried ones.", m.failed)
+	m.writeCounter(bw, "tasks_dead_total", "Tasks moved to the dead letter queue.", m.dead)
+
+	name := m.namespace + "_task_wait_seconds"
+	writeHeader(bw, name, "Time tasks spent due in the queue before a worker claimed them.", "histogram")
+	for _, key := range slices.SortedFunc(maps.Keys(m.wait), compareTaskSeries) {
+		m.writeHistogram(bw, name, taskLabels(key), m.wait[key])
+	}
+
+	name = m.namespace + "_task_duration_seconds"
+	writeHeader(bw, name, "Task handler execution time.", "histogram")
+	for _, key := range slices.SortedFunc(maps.Keys(m.duration), compareDurationSeries) {
+		labels := append(taskLabels(key.taskSeries), "status", key.status)
+		m.writeHistogram(bw, name, labels, m.duration[key])
+	}
+
+	if m.inspector != nil {
+		name = m.namespace + "_depth"
+		writeHeader(bw, name, "Tasks in the queue by status.", "gauge")
+		for _, queue := range slices.Sorted(maps.Keys(depth)) {
+			for _, status := range depthStatuses {
+				if n, ok := depth[queue][status]; ok {
+					writeSample(bw, name, []string{"queue", queue, "status", string(status)}, strconv.FormatInt(n, 10))
+				}
+			}
+		}
+
+		name = m.namespace + "_depth_errors_total"
+		writeHeader(bw, name, "Failed queue depth collections.", "counter")
+		writeSample(bw, name, nil, strconv.FormatUint(m.depthErrors, 10))
+	}
+
+	return bw.Flush()
+}
+
+// collectDepth counts the tasks of every known queue by status
+func (m *PrometheusMetrics) collectDepth(ctx context.Context) map[string]map[TaskStatus]int64 {
+	if m.inspector == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	queues := make(map[string]struct{}, len(m.queues)+len(m.seenQueues))
+	for _, q := range m.queues {
+		queues[q] = struct{}{}
+	}
+	maps.Copy(queues, m.seenQueues)
+	m.mu.Unlock()
+
+	depth := make(map[string]map[TaskStatus]int64, len(queues))
+	failed := false
+	for queue := range queues {
+		depth[queue] = make(map[TaskStatus]int64, len(depthStatuses))
+		for _, status := range depthStatuses {
+			n, err := m.inspector.CountTasks(ctx, TaskFilter{Queue: queue, Status: status})
+			if err != nil {
+				failed = true
+				continue
+			}
+			depth[queue][status] = n
+		}
+	}
+
+	if failed {
+		m.mu.Lock()
+		m.depthErrors++
+		m.mu.Unlock()
+	}
+	return depth
+}
+
+func (m *PrometheusMetrics) writeCounter(w *bufio.Writer, name, help string, counter map[taskSeries]uint64) {
+	name = m.namespace + "_" + name
+	writeHeader(w, name, help, "counter")
+	for _, key := range slices.SortedFunc(maps.Keys(counter), compareTaskSeries) {
+		writeSample(w, name, taskLabels(key), strconv.FormatUint(counter[key], 10))
+	}
+}
+
+func (m *PrometheusMetrics) writeHistogram(w *bufio.Writer, name string, labels []string, h *histogram) {
+	var cumulative uint64
+	for i, bound := range m.buckets {
+		cumulative += h.counts[i]
+		writeSample(w, name+"_bucket", append(slices.Clip(labels), "le", formatFloat(bound)), strconv.FormatUint(cumulative, 10))
+	}
+	writeSample(w, name+"_bucket", append(slices.Clip(labels), "le", "+Inf"), strconv.FormatUint(h.count, 10))
+	writeSample(w, name+"_sum", labels, formatFloat(h.sum))
+	writeSample(w, name+"_count", labels, strconv.FormatUint(h.count, 10))
+}
+
+func writeHeader(w *bufio.Writer, name, help, kind string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// writeSample writes one sample line; labels alternate names and values
+func writeSample(w *bufio.Writer, name string, labels []string, value string) {
+	w.WriteString(name)
+	if len(labels) > 0 {
+		w.WriteByte('{')
+		for i := 0; i < len(labels); i += 2 {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(labels[i])
+			w.WriteString(`="`)
+			w.WriteString(labelEscaper.Replace(labels[i+1]))
+			w.WriteByte('"')
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(value)
+	w.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func taskLabels(s taskSeries) []string {
+	return []string{"queue", s.queue, "task_name", s.taskName}
+}
+
+func compareTaskSeries(a, b taskSeries) int {
+	return cmp.Or(cmp.Compare(a.queue, b.queue), cmp.Compare(a.taskName, b.taskName))
+}
+
+func compareDurationSeries(a, b durationSeries) int {
+	return cmp.Or(compareTaskSeries(a.taskSeries, b.taskSeries), cmp.Compare(a.status, b.status))
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,163 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+type metricsSignup struct {
+	Email string `json:"email"`
+}
+
+type metricsCharge struct {
+	Amount int `json:"amount"`
+}
+
+type metricsReport struct{}
+
+func scrape(t *testing.T, handler http.Handler) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("collects enqueuer and worker events", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		metrics := queue.NewPrometheusMetrics(queue.WithQueueDepth(storage, "reports"))
+
+		enqueuer, err := queue.NewEnqueuer(storage, queue.WithEnqueuerMetrics(metrics))
+		require.NoError(t, err)
+		mustEnqueue(t, enqueuer, metricsSignup{Email: "a@example.com"})
+		mustEnqueue(t, enqueuer, metricsSignup{Email: "b@example.com"})
+		mustEnqueue(t, enqueuer, metricsCharge{Amount: 10}, queue.WithMaxRetries(0))
+		mustEnqueue(t, enqueuer, metricsReport{}, queue.WithQueue("reports"))
+
+		worker, err := queue.NewWorker(storage,
+			queue.WithPullInterval(5*time.Millisecond),
+			queue.WithWorkerMetrics(metrics),
+		)
+		require.NoError(t, err)
+		require.NoError(t, worker.RegisterHandlers(
+			queue.NewTaskHandler(func(ctx context.Context, p metricsSignup) error { return nil }),
+			queue.NewTaskHandler(func(ctx context.Context, p metricsCharge) error {
+				return errors.New("card declined")
+			}),
+		))
+		require.NoError(t, worker.Start(context.Background()))
+		t.Cleanup(func() { _ = worker.Stop() })
+
+		require.Eventually(t, func() bool {
+			return strings.Contains(scrape(t, metrics), `queue_tasks_dead_total{queue="default",task_name="queue_test.metricsCharge"} 1`) &&
+				strings.Contains(scrape(t, metrics), `queue_tasks_succeeded_total{queue="default",task_name="queue_test.metricsSignup"} 2`)
+		}, 5*time.Second, 10*time.Millisecond)
+
+		body := scrape(t, metrics)
+		for _, line := range []string{
+			"# TYPE queue_tasks_enqueued_total counter",
+			`queue_tasks_enqueued_total{queue="default",task_name="queue_test.metricsSignup"} 2`,
+			`queue_tasks_enqueued_total{queue="reports",task_name="queue_test.metricsReport"} 1`,
+			`queue_tasks_failed_total{queue="default",task_name="queue_test.metricsCharge"} 1`,
+			"# TYPE queue_task_wait_seconds histogram",
+			`queue_task_wait_seconds_count{queue="default",task_name="queue_test.metricsSignup"} 2`,
+			"# TYPE queue_task_duration_seconds histogram",
+			`queue_task_duration_seconds_count{queue="default",task_name="queue_test.metricsSignup",status="succeeded"} 2`,
+			`queue_task_duration_seconds_bucket{queue="default",task_name="queue_test.metricsCharge",status="failed",le="+Inf"} 1`,
+			"# TYPE queue_depth gauge",
+			`queue_depth{queue="reports",status="pending"} 1`,
+			`queue_depth{queue="default",status="pending"} 0`,
+			"queue_depth_errors_total 0",
+		} {
+			assert.Contains(t, body, line+"\n")
+		}
+	})
+
+	t.Run("collects scheduler events", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+
+		metrics := queue.NewPrometheusMetrics()
+		scheduler, err := queue.NewScheduler(storage,
+			queue.WithCheckInterval(10*time.Millisecond),
+			queue.WithSchedulerMetrics(metrics),
+		)
+		require.NoError(t, err)
+		require.NoError(t, scheduler.AddTask("nightly-report", queue.Daily()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = scheduler.Start(ctx)
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+
+		require.Eventually(t, func() bool {
+			return strings.Contains(scrape(t, metrics), `queue_tasks_enqueued_total{queue="default",task_name="nightly-report"} 1`)
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("writes cumulative histogram buckets", func(t *testing.T) {
+		t.Parallel()
+
+		metrics := queue.NewPrometheusMetrics(
+			queue.WithMetricsNamespace("jobs"),
+			queue.WithDurationBuckets(1, 0.1),
+		)
+		for _, d := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond, 500 * time.Millisecond, 2 * time.Second} {
+			metrics.TaskSucceeded("default", "resize", d)
+		}
+
+		var b strings.Builder
+		require.NoError(t, metrics.WriteMetrics(context.Background(), &b))
+
+		assert.Contains(t, b.String(), strings.Join([]string{
+			`jobs_task_duration_seconds_bucket{queue="default",task_name="resize",status="succeeded",le="0.1"} 2`,
+			`jobs_task_duration_seconds_bucket{queue="default",task_name="resize",status="succeeded",le="1"} 3`,
+			`jobs_task_duration_seconds_bucket{queue="default",task_name="resize",status="succeeded",le="+Inf"} 4`,
+			`jobs_task_duration_seconds_sum{queue="default",task_name="resize",status="succeeded"} 2.65`,
+			`jobs_task_duration_seconds_count{queue="default",task_name="resize",status="succeeded"} 4`,
+		}, "\n"))
+		assert.NotContains(t, b.String(), "jobs_depth")
+	})
+
+	t.Run("escapes label values", func(t *testing.T) {
+		t.Parallel()
+
+		metrics := queue.NewPrometheusMetrics()
+		metrics.TaskMovedToDLQ("default", "say \"hi\"\\\n")
+
+		var b strings.Builder
+		require.NoError(t, metrics.WriteMetrics(context.Background(), &b))
+		assert.Contains(t, b.String(), `queue_tasks_dead_total{queue="default",task_name="say \"hi\"\\\n"} 1`)
+	})
+}
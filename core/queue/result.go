@@ -0,0 +1,211 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ResultRepository is implemented by repositories that persist handler results.
+// The worker falls back to CompleteTask when the repository does not implement it.
+type ResultRepository interface {
+	// CompleteTaskWithResult marks a processing task as completed and stores its result
+	CompleteTaskWithResult(ctx context.Context, taskID uuid.UUID, result []byte) error
+}
+
+// TaskTracker is implemented by repositories that look up and cancel tasks by ID.
+// Every QueueInspector satisfies it. Required by Enqueuer.Cancel, GetResult and WaitForResult.
+type TaskTracker interface {
+	GetTask(ctx context.Context, taskID uuid.UUID) (*Task, error)
+	CancelTask(ctx context.Context, taskID uuid.UUID) error
+}
+
+// TaskResult describes the current state of an enqueued task and its result, if any
+type TaskResult struct {
+	TaskID      uuid.UUID       `json:"task_id"`
+	Status      TaskStatus      `json:"status"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	ProcessedAt *time.Time      `json:"processed_at,omitempty"`
+}
+
+// Done reports whether the task reached a final status
+func (r *TaskResult) Done() bool {
+	switch r.Status {
+	case TaskStatusCompleted, TaskStatusFailed, TaskStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// Decode unmarshals the task result into v
+func (r *TaskResult) Decode(v any) error {
+	if len(r.Result) == 0 {
+		return fmt.Errorf("task %s has no result", r.TaskID)
+	}
+	return json.Unmarshal(r.Result, v)
+}
+
+// Cancel cancels a pending task. Returns ErrTaskNotPending when the task is already
+// processing or finished. Requires a repository implementing TaskTracker.
+func (e *Enqueuer) Cancel(ctx context.Context, taskID uuid.UUID) error {
+	tracker, ok := e.repo.(TaskTracker)
+	if !ok {
+		return ErrTrackingNotSupported
+	}
+	return tracker.CancelTask(ctx, taskID)
+}
+
+// GetResult returns the current status of a task with its result once completed.
+// Results of encrypted tasks are decrypted with the cipher of WithEnqueuerEncryption.
+// Tasks moved to the dead letter queue are no longer tracked and return ErrTaskNotFound.
+// Requires a repository implementing TaskTracker.
+func (e *Enqueuer) GetResult(ctx context.Context, taskID uuid.UUID) (*TaskResult, error) {
+	tracker, ok := e.repo.(TaskTracker)
+	if !ok {
+		return nil, ErrTrackingNotSupported
+	}
+
+	task, err := tracker.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := task.Result
+	if e.cipher != nil && len(data) > 0 {
+		data, err = e.cipher.Decrypt(ctx, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt result of task %s: %w", task.ID, err)
+		}
+	}
+
+	result := &TaskResult{
+		TaskID:      task.ID,
+		Status:      task.Status,
+		Result:      data,
+		ProcessedAt: task.ProcessedAt,
+	}
+	if task.Error != nil {
+		result.Error = *task.Error
+	}
+
+	return result, nil
+}
+
+// WaitForResult polls the task until it finishes or ctx is done.
+// Returns the result of a completed task, ErrTaskCancelled for a cancelled task and
+// ErrTaskFailed for a task that failed or disappeared into the dead letter queue
+// while being watched. Use a context deadline to bound the wait.
+func (e *Enqueuer) WaitForResult(ctx context.Context, taskID uuid.UUID) (*TaskResult, error) {
+	ticker := time.NewTicker(e.resultPollInterval)
+	defer ticker.Stop()
+
+	seen := false
+	for {
+		result, err := e.GetResult(ctx, taskID)
+		switch {
+		case errors.Is(err, ErrTaskNotFound) && seen:
+			return nil, fmt.Errorf("%w: task %s was moved to the dead letter queue", ErrTaskFailed, taskID)
+		case err != nil:
+			return nil, err
+		}
+		seen = true
+
+		switch result.Status {
+		case TaskStatusCompleted:
+			return result, nil
+		case TaskStatusCancelled:
+			return result, fmt.Errorf("%w: %s", ErrTaskCancelled, taskID)
+		case TaskStatusFailed:
+			return result, fmt.Errorf("%w: %s", ErrTaskFailed, result.Error)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// TaskResultHandlerFunc processes a task and returns a result.
+// In a chain the result becomes the payload of the next step.
+type TaskResultHandlerFunc[T, R any] func(ctx context.Context, payload T) (R, error)
+
+// NewTaskResultHandler creates a handler for payload type T that returns a result of type R.
+// The result is stored with SetResult when the task succeeds.
+func NewTaskResultHandler[T, R any](handler TaskResultHandlerFunc[T, R], opts ...HandlerOption) Handler {
+	var payload T
+	h := &resultTaskHandler[T, R]{
+		name:    qualifiedStructName(payload),
+		handler: handler,
+	}
+	for _, opt := range opts {
+		opt(&h.opts)
+	}
+	return h
+}
+
+type resultTaskHandler[T, R any] struct {
+	name    string
+	handler TaskResultHandlerFunc[T, R]
+	opts    handlerOptions
+}
+
+func (h *resultTaskHandler[T, R]) Name() string {
+	return h.name
+}
+
+func (h *resultTaskHandler[T, R]) Handle(ctx context.Context, payload json.RawMessage) error {
+	var t T
+	if err := json.Unmarshal(payload, &t); err != nil {
+		return err
+	}
+
+	result, err := h.handler(ctx, t)
+	if err != nil {
+		return err
+	}
+
+	if err := SetResult(ctx, result); err != nil {
+		return NoRetry(err)
+	}
+
+	return nil
+}
+
+func (h *resultTaskHandler[T, R]) handlerOptions() *handlerOptions {
+	return &h.opts
+}
+
+// SetResult stores the result of the running task. It is marshaled to JSON and saved
+// with the task when the handler succeeds, and becomes the payload of the next chain step.
+// Handlers created with NewTaskResultHandler call it with their return value.
+// Outside of a worker it is a no-op.
+func SetResult(ctx context.Context, result any) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result of type %T: %w", result, err)
+	}
+	if holder, ok := ctx.Value(resultHolderKey{}).(*resultHolder); ok {
+		holder.data = data
+	}
+	return nil
+}
+
+// resultHolderKey is the context key of the per-task result holder set by the worker
+type resultHolderKey struct{}
+
+// resultHolder receives the result of a handler during task execution
+type resultHolder struct {
+	data json.RawMessage
+}
+
+// withResultHolder attaches a result holder to the handler context
+func withResultHolder(ctx context.Context, holder *resultHolder) context.Context {
+	return context.WithValue(ctx, resultHolderKey{}, holder)
+}
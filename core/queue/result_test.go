@@ -0,0 +1,193 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+type resultReport struct {
+	ID int `json:"id"`
+}
+
+type resultRendered struct {
+	URL string `json:"url"`
+}
+
+type resultNotify struct {
+	Email string `json:"email"`
+}
+
+func TestEnqueuer_Results(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	newEnqueuer := func(t *testing.T) (*queue.Enqueuer, *queue.MemoryStorage) {
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueuer, err := queue.NewEnqueuer(storage, queue.WithResultPollInterval(5*time.Millisecond))
+		require.NoError(t, err)
+		return enqueuer, storage
+	}
+
+	t.Run("waits for result of result handler", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, storage := newEnqueuer(t)
+
+		startWorkflowWorker(t, storage,
+			queue.NewTaskResultHandler(func(ctx context.Context, r resultReport) (resultRendered, error) {
+				return resultRendered{URL: "https://example.com/reports/1"}, nil
+			}),
+		)
+
+		id := mustEnqueue(t, enqueuer, resultReport{ID: 1})
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		result, err := enqueuer.WaitForResult(waitCtx, id)
+		require.NoError(t, err)
+		assert.Equal(t, id, result.TaskID)
+		assert.Equal(t, queue.TaskStatusCompleted, result.Status)
+		assert.True(t, result.Done())
+		assert.NotNil(t, result.ProcessedAt)
+
+		var rendered resultRendered
+		require.NoError(t, result.Decode(&rendered))
+		assert.Equal(t, "https://example.com/reports/1", rendered.URL)
+	})
+
+	t.Run("plain handler stores result with SetResult", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, storage := newEnqueuer(t)
+
+		startWorkflowWorker(t, storage,
+			queue.NewTaskHandler(func(ctx context.Context, n resultNotify) error {
+				return queue.SetResult(ctx, map[string]string{"sent_to": n.Email})
+			}),
+		)
+
+		id := mustEnqueue(t, enqueuer, resultNotify{Email: "user@example.com"})
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		result, err := enqueuer.WaitForResult(waitCtx, id)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"sent_to":"user@example.com"}`, string(result.Result))
+	})
+
+	t.Run("completed task without result", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, storage := newEnqueuer(t)
+
+		startWorkflowWorker(t, storage,
+			queue.NewTaskHandler(func(ctx context.Context, n resultNotify) error { return nil }),
+		)
+
+		id := mustEnqueue(t, enqueuer, resultNotify{})
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		result, err := enqueuer.WaitForResult(waitCtx, id)
+		require.NoError(t, err)
+		assert.Empty(t, result.Result)
+		assert.Error(t, result.Decode(&map[string]any{}))
+	})
+
+	t.Run("pending task status", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, _ := newEnqueuer(t)
+
+		id := mustEnqueue(t, enqueuer, resultReport{ID: 1})
+
+		result, err := enqueuer.GetResult(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, queue.TaskStatusPending, result.Status)
+		assert.False(t, result.Done())
+		assert.Empty(t, result.Result)
+	})
+
+	t.Run("cancel pending task", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, _ := newEnqueuer(t)
+
+		id := mustEnqueue(t, enqueuer, resultReport{ID: 1})
+		require.NoError(t, enqueuer.Cancel(ctx, id))
+
+		result, err := enqueuer.WaitForResult(ctx, id)
+		assert.ErrorIs(t, err, queue.ErrTaskCancelled)
+		require.NotNil(t, result)
+		assert.Equal(t, queue.TaskStatusCancelled, result.Status)
+
+		assert.ErrorIs(t, enqueuer.Cancel(ctx, id), queue.ErrTaskNotPending)
+	})
+
+	t.Run("failed task reports dead letter", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, storage := newEnqueuer(t)
+
+		startWorkflowWorker(t, storage,
+			queue.NewTaskHandler(func(ctx context.Context, r resultReport) error {
+				return queue.NoRetry(errors.New("report not found"))
+			}),
+		)
+
+		id := mustEnqueue(t, enqueuer, resultReport{ID: 1})
+
+		waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		_, err := enqueuer.WaitForResult(waitCtx, id)
+		assert.ErrorIs(t, err, queue.ErrTaskFailed)
+	})
+
+	t.Run("wait honors context", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, _ := newEnqueuer(t)
+
+		id := mustEnqueue(t, enqueuer, resultReport{ID: 1})
+
+		waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		_, err := enqueuer.WaitForResult(waitCtx, id)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		t.Parallel()
+		enqueuer, _ := newEnqueuer(t)
+
+		_, err := enqueuer.GetResult(ctx, uuid.New())
+		assert.ErrorIs(t, err, queue.ErrTaskNotFound)
+
+		_, err = enqueuer.WaitForResult(ctx, uuid.New())
+		assert.ErrorIs(t, err, queue.ErrTaskNotFound)
+	})
+
+	t.Run("repository without tracking support", func(t *testing.T) {
+		t.Parallel()
+
+		enqueuer, err := queue.NewEnqueuer(&mockEnqueuerRepo{})
+		require.NoError(t, err)
+
+		id, err := enqueuer.Enqueue(ctx, resultReport{ID: 1})
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, enqueuer.Cancel(ctx, id), queue.ErrTrackingNotSupported)
+		_, err = enqueuer.GetResult(ctx, id)
+		assert.ErrorIs(t, err, queue.ErrTrackingNotSupported)
+		_, err = enqueuer.WaitForResult(ctx, id)
+		assert.ErrorIs(t, err, queue.ErrTrackingNotSupported)
+	})
+}
@@ -0,0 +1,40 @@
+package queue
+
+import (
+	"errors"
+	"time"
+)
+
+// RetryAfter wraps a handler error to request the next attempt after the given delay,
+// overriding the configured backoff. Retries are still limited by the task's MaxRetries.
+func RetryAfter(err error, delay time.Duration) error {
+	if err == nil {
+		err = errors.New("retry requested")
+	}
+	return &retryAfterError{err: err, delay: max(delay, 0)}
+}
+
+// NoRetry wraps a handler error to fail the task permanently and move it to the
+// dead letter queue without further attempts. The result matches ErrSkipRetry via errors.Is.
+func NoRetry(err error) error {
+	if err == nil {
+		return ErrSkipRetry
+	}
+	return &noRetryError{err: err}
+}
+
+type retryAfterError struct {
+	err   error
+	delay time.Duration
+}
+
+func (e *retryAfterError) Error() string { return e.err.Error() }
+func (e *retryAfterError) Unwrap() error { return e.err }
+
+type noRetryError struct {
+	err error
+}
+
+func (e *noRetryError) Error() string        { return e.err.Error() }
+func (e *noRetryError) Unwrap() error        { return e.err }
+func (e *noRetryError) Is(target error) bool { return target == ErrSkipRetry }
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,14 +20,30 @@ type SchedulerRepository interface {
 	GetPendingTaskByName(ctx context.Context, taskName string) (*Task, error)
 }
 
+// LeaderLock elects a single active scheduler among application replicas.
+// Only the instance holding the lock creates periodic tasks; another instance
+// takes over when the leader releases the lock or stops renewing it.
+// Implementations are available as pg.SchedulerLock and redis.SchedulerLock.
+type LeaderLock interface {
+	// TryAcquire takes the lock or renews it when already held by this instance.
+	// Returns false when another instance holds the lock.
+	TryAcquire(ctx context.Context) (bool, error)
+
+	// Release gives up the lock so another instance can take over immediately
+	Release(ctx context.Context) error
+}
+
 // Scheduler manages periodic task scheduling
 type Scheduler struct {
-	repo     SchedulerRepository
-	tasks    map[string]*scheduledTask
-	mu       sync.RWMutex
-	ticker   *time.Ticker
-	interval time.Duration
-	logger   *slog.Logger
+	repo       SchedulerRepository
+	tasks      map[string]*scheduledTask
+	mu         sync.RWMutex
+	ticker     *time.Ticker
+	interval   time.Duration
+	logger     *slog.Logger
+	leaderLock LeaderLock
+	leader     atomic.Bool
+	metrics    Metrics
 }
 
 // scheduledTask holds configuration for a periodic task
@@ -49,6 +66,7 @@ func NewScheduler(repo SchedulerRepository, opts ...SchedulerOption) (*Scheduler
 	options := &schedulerOptions{
 		checkInterval: 30 * time.Second,
 		logger:        slog.Default(),
+		metrics:       noopMetrics{},
 	}
 
 	// Apply options
@@ -57,10 +75,12 @@ func NewScheduler(repo SchedulerRepository, opts ...SchedulerOption) (*Scheduler
 	}
 
 	return &Scheduler{
-		repo:     repo,
-		tasks:    make(map[string]*scheduledTask),
-		interval: options.checkInterval,
-		logger:   options.logger,
+		repo:       repo,
+		tasks:      make(map[string]*scheduledTask),
+		interval:   options.checkInterval,
+		logger:     options.logger,
+		leaderLock: options.leaderLock,
+		metrics:    options.metrics,
 	}, nil
 }
 
@@ -119,8 +139,13 @@ func (s *Scheduler) Start(ctx context.Context) error {
 	s.ticker = time.NewTicker(s.interval)
 	defer s.ticker.Stop()
 
+	// Hand leadership over on shutdown
+	defer s.releaseLeadership()
+
 	// Check immediately on start
-	s.checkTasks(ctx)
+	if s.acquireLeadership(ctx) {
+		s.checkTasks(ctx)
+	}
 
 	// Then check periodically
 	for {
@@ -129,11 +154,76 @@ func (s *Scheduler) Start(ctx context.Context) error {
 			s.logger.Info("scheduler shutting down")
 			return ctx.Err()
 		case <-s.ticker.C:
-			s.checkTasks(ctx)
+			if s.acquireLeadership(ctx) {
+				s.checkTasks(ctx)
+			}
 		}
 	}
 }
 
+// IsLeader reports whether this scheduler currently creates periodic tasks.
+// Always true for a running scheduler without a leader lock.
+func (s *Scheduler) IsLeader() bool {
+	return s.leader.Load()
+}
+
+// acquireLeadership takes or renews the leader lock and reports whether this
+// instance should schedule tasks. Errors count as lost leadership, so two instances
+// never schedule at the same time.
+func (s *Scheduler) acquireLeadership(ctx context.Context) bool {
+	if s.leaderLock == nil {
+		s.leader.Store(true)
+		return true
+	}
+
+	acquired, err := s.leaderLock.TryAcquire(ctx)
+	if err != nil {
+		s.logger.Error("failed to acquire scheduler leadership",
+			slog.String("error", err.Error()))
+		acquired = false
+	}
+
+	wasLeader := s.leader.Swap(acquired)
+	switch {
+	case acquired && !wasLeader:
+		// Start from a clean state: the previous leader may have scheduled tasks meanwhile
+		s.resetTaskState()
+		s.logger.Info("scheduler became leader")
+	case !acquired && wasLeader:
+		s.logger.Warn("scheduler lost leadership")
+	}
+
+	return acquired
+}
+
+// releaseLeadership releases the leader lock held by this instance
+func (s *Scheduler) releaseLeadership() {
+	if !s.leader.Swap(false) || s.leaderLock == nil {
+		return
+	}
+
+	// The scheduler context is already cancelled on shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := s.leaderLock.Release(ctx); err != nil {
+		s.logger.Error("failed to release scheduler leadership",
+			slog.String("error", err.Error()))
+		return
+	}
+	s.logger.Info("scheduler released leadership")
+}
+
+// resetTaskState forgets when tasks were last scheduled
+func (s *Scheduler) resetTaskState() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, t := range s.tasks {
+		t.lastScheduledAt = nil
+	}
+}
+
 // checkTasks checks all registered tasks and creates any that are due
 func (s *Scheduler) checkTasks(ctx context.Context) {
 	// Get a snapshot of tasks
@@ -160,6 +250,11 @@ func (s *Scheduler) checkTasks(ctx context.Context) {
 func (s *Scheduler) scheduleTaskIfNeeded(ctx context.Context, task *scheduledTask, now time.Time) error {
 	nextRun := s.calculateNextRun(task, now)
 
+	// Schedules that never match again (e.g. cron for February 30th) return the zero time
+	if nextRun.IsZero() {
+		return nil
+	}
+
 	// Check if task should be scheduled
 	if !s.shouldScheduleTask(task, nextRun, now) {
 		return nil
@@ -252,7 +347,12 @@ func (s *Scheduler) createTask(ctx context.Context, task *scheduledTask, schedul
 		CreatedAt:   time.Now(),
 	}
 
-	return s.repo.CreateTask(ctx, newTask)
+	if err := s.repo.CreateTask(ctx, newTask); err != nil {
+		return err
+	}
+	s.metrics.TaskEnqueued(newTask.Queue, newTask.TaskName)
+
+	return nil
 }
 
 // RemoveTask removes a periodic task from the scheduler
@@ -11,6 +11,8 @@ type SchedulerOption func(*schedulerOptions)
 type schedulerOptions struct {
 	checkInterval time.Duration
 	logger        *slog.Logger
+	leaderLock    LeaderLock
+	metrics       Metrics
 }
 
 // WithCheckInterval sets how often scheduler checks for due tasks
@@ -31,6 +33,26 @@ func WithSchedulerLogger(logger *slog.Logger) SchedulerOption {
 	}
 }
 
+// WithSchedulerMetrics reports every created periodic task to metrics
+func WithSchedulerMetrics(metrics Metrics) SchedulerOption {
+	return func(o *schedulerOptions) {
+		if metrics != nil {
+			o.metrics = metrics
+		}
+	}
+}
+
+// WithLeaderLock runs the scheduler in leader mode: only the replica holding the lock
+// creates periodic tasks. The lock is acquired or renewed on every check, so its expiry
+// must be longer than the check interval.
+func WithLeaderLock(lock LeaderLock) SchedulerOption {
+	return func(o *schedulerOptions) {
+		if lock != nil {
+			o.leaderLock = lock
+		}
+	}
+}
+
 // SchedulerTaskOption is a functional option for configuring a scheduled task
 type SchedulerTaskOption func(*schedulerTaskOptions)
 
@@ -561,3 +561,117 @@ func TestSchedulerWithLogger(t *testing.T) {
 
 	// Just verify it was created with the logger, don't need to run it
 }
+
+// fakeElection hands a single leader lock to competing schedulers
+type fakeElection struct {
+	mu     sync.Mutex
+	holder string
+}
+
+type fakeLeaderLock struct {
+	election *fakeElection
+	id       string
+	err      error
+}
+
+func (l *fakeLeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	if l.err != nil {
+		return false, l.err
+	}
+
+	l.election.mu.Lock()
+	defer l.election.mu.Unlock()
+
+	if l.election.holder == "" || l.election.holder == l.id {
+		l.election.holder = l.id
+		return true, nil
+	}
+	return false, nil
+}
+
+func (l *fakeLeaderLock) Release(ctx context.Context) error {
+	l.election.mu.Lock()
+	defer l.election.mu.Unlock()
+
+	if l.election.holder == l.id {
+		l.election.holder = ""
+	}
+	return nil
+}
+
+func TestScheduler_LeaderLock(t *testing.T) {
+	t.Parallel()
+
+	t.Run("only leader schedules and follower takes over", func(t *testing.T) {
+		t.Parallel()
+
+		repo := newMockSchedulerRepo()
+		election := &fakeElection{}
+
+		newScheduler := func(id string) *queue.Scheduler {
+			scheduler, err := queue.NewScheduler(repo,
+				queue.WithCheckInterval(10*time.Millisecond),
+				queue.WithLeaderLock(&fakeLeaderLock{election: election, id: id}),
+			)
+			require.NoError(t, err)
+			require.NoError(t, scheduler.AddTask("report", queue.EveryInterval(time.Hour)))
+			return scheduler
+		}
+
+		leader, follower := newScheduler("a"), newScheduler("b")
+
+		leaderCtx, stopLeader := context.WithCancel(context.Background())
+		leaderDone := make(chan struct{})
+		go func() {
+			_ = leader.Start(leaderCtx)
+			close(leaderDone)
+		}()
+		require.Eventually(t, leader.IsLeader, time.Second, 5*time.Millisecond)
+
+		followerCtx, stopFollower := context.WithCancel(context.Background())
+		defer stopFollower()
+		go func() { _ = follower.Start(followerCtx) }()
+
+		time.Sleep(50 * time.Millisecond)
+		assert.False(t, follower.IsLeader())
+		assert.Equal(t, 1, repo.countTasksByName("report"))
+
+		// The task is processed, then the leader shuts down
+		for _, task := range repo.getTasksByName("report") {
+			repo.mu.Lock()
+			task.Status = queue.TaskStatusCompleted
+			repo.mu.Unlock()
+		}
+		stopLeader()
+		<-leaderDone
+		assert.False(t, leader.IsLeader())
+
+		require.Eventually(t, follower.IsLeader, time.Second, 5*time.Millisecond)
+		require.Eventually(t, func() bool {
+			return repo.countTasksByName("report") == 2
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("lock errors stop scheduling", func(t *testing.T) {
+		t.Parallel()
+
+		repo := newMockSchedulerRepo()
+		scheduler, err := queue.NewScheduler(repo,
+			queue.WithCheckInterval(10*time.Millisecond),
+			queue.WithSchedulerLogger(slog.New(slog.NewTextHandler(io.Discard, nil))),
+			queue.WithLeaderLock(&fakeLeaderLock{
+				election: &fakeElection{},
+				err:      errors.New("connection refused"),
+			}),
+		)
+		require.NoError(t, err)
+		require.NoError(t, scheduler.AddTask("report", queue.EveryInterval(time.Hour)))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_ = scheduler.Start(ctx)
+
+		assert.False(t, scheduler.IsLeader())
+		assert.Zero(t, repo.countTasksByName("report"))
+	})
+}
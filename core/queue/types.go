@@ -25,6 +25,7 @@ const (
 	TaskStatusProcessing TaskStatus = "processing"
 	TaskStatusCompleted  TaskStatus = "completed"
 	TaskStatusFailed     TaskStatus = "failed"
+	TaskStatusCancelled  TaskStatus = "cancelled"
 )
 
 // Priority represents task priority (0-100, higher is more important)
@@ -48,35 +49,45 @@ func (p Priority) Valid() bool {
 
 // Task represents a task in the queue
 type Task struct {
-	ID          uuid.UUID  `json:"id"`
-	Queue       string     `json:"queue"`
-	TaskType    TaskType   `json:"task_type"`
-	TaskName    string     `json:"task_name"`
-	Payload     []byte     `json:"payload,omitempty"`
-	Status      TaskStatus `json:"status"`
-	Priority    Priority   `json:"priority"`
-	RetryCount  int8       `json:"retry_count"`
-	MaxRetries  int8       `json:"max_retries"`
-	ScheduledAt time.Time  `json:"scheduled_at"`
-	LockedUntil *time.Time `json:"locked_until,omitempty"`
-	LockedBy    *uuid.UUID `json:"locked_by,omitempty"`
-	ProcessedAt *time.Time `json:"processed_at,omitempty"`
-	Error       *string    `json:"error,omitempty"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ID          uuid.UUID      `json:"id"`
+	Queue       string         `json:"queue"`
+	TaskType    TaskType       `json:"task_type"`
+	TaskName    string         `json:"task_name"`
+	Payload     []byte         `json:"payload,omitempty"`
+	Status      TaskStatus     `json:"status"`
+	Priority    Priority       `json:"priority"`
+	RetryCount  int8           `json:"retry_count"`
+	MaxRetries  int8           `json:"max_retries"`
+	Backoff     *BackoffPolicy `json:"backoff,omitempty"`
+	UniqueKey   *string        `json:"unique_key,omitempty"`
+	UniqueUntil *time.Time     `json:"unique_until,omitempty"`
+	BatchID     *uuid.UUID     `json:"batch_id,omitempty"`
+	Next        []WorkflowStep `json:"next,omitempty"`
+	ScheduledAt time.Time      `json:"scheduled_at"`
+	LockedUntil *time.Time     `json:"locked_until,omitempty"`
+	LockedBy    *uuid.UUID     `json:"locked_by,omitempty"`
+	ProcessedAt *time.Time     `json:"processed_at,omitempty"`
+	Error       *string        `json:"error,omitempty"`
+	Result      []byte         `json:"result,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
 }
 
 // TasksDlq represents a task in the dead letter queue
 // Stores failed tasks that exhausted all retries for manual inspection and recovery
 type TasksDlq struct {
-	ID         uuid.UUID `json:"id"`
-	TaskID     uuid.UUID `json:"task_id"`
-	Queue      string    `json:"queue"`
-	TaskType   TaskType  `json:"task_type"`
-	TaskName   string    `json:"task_name"`
-	Payload    []byte    `json:"payload,omitempty"`
-	Priority   Priority  `json:"priority"`
-	Error      string    `json:"error"`
-	RetryCount int8      `json:"retry_count"`
-	FailedAt   time.Time `json:"failed_at"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         uuid.UUID      `json:"id"`
+	TaskID     uuid.UUID      `json:"task_id"`
+	Queue      string         `json:"queue"`
+	TaskType   TaskType       `json:"task_type"`
+	TaskName   string         `json:"task_name"`
+	Payload    []byte         `json:"payload,omitempty"`
+	Priority   Priority       `json:"priority"`
+	Error      string         `json:"error"`
+	RetryCount int8           `json:"retry_count"`
+	MaxRetries int8           `json:"max_retries"`
+	Backoff    *BackoffPolicy `json:"backoff,omitempty"`
+	BatchID    *uuid.UUID     `json:"batch_id,omitempty"`
+	Next       []WorkflowStep `json:"next,omitempty"`
+	FailedAt   time.Time      `json:"failed_at"`
+	CreatedAt  time.Time      `json:"created_at"`
 }
@@ -0,0 +1,54 @@
+package queue
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UniqueConflict selects what happens when a task is enqueued with a unique key
+// that is already held by another pending or processing task.
+type UniqueConflict string
+
+const (
+	// UniqueConflictSkip drops the new task and keeps the existing one
+	UniqueConflictSkip UniqueConflict = "skip"
+	// UniqueConflictReplace cancels the existing task if it is still pending and creates the new one.
+	// A processing task keeps running, but the key moves to the new task.
+	UniqueConflictReplace UniqueConflict = "replace"
+	// UniqueConflictError rejects the new task with ErrDuplicateTask
+	UniqueConflictError UniqueConflict = "error"
+)
+
+// Valid checks if the conflict behavior is one of the known values
+func (c UniqueConflict) Valid() bool {
+	switch c {
+	case UniqueConflictSkip, UniqueConflictReplace, UniqueConflictError:
+		return true
+	}
+	return false
+}
+
+// UniqueTaskRepository is implemented by repositories that support WithUniqueKey.
+// The check for an existing key holder and the task creation must be atomic,
+// so concurrent enqueuers cannot both create a task for the same key.
+//
+// A task holds its unique key while it is pending or processing and, when
+// UniqueUntil is set, until that time. CreateUniqueTask returns the ID of the
+// task holding the key after the call: the new task when it was created, or the
+// existing one when it was skipped. UniqueConflictError returns ErrDuplicateTask.
+type UniqueTaskRepository interface {
+	CreateUniqueTask(ctx context.Context, task *Task, onConflict UniqueConflict) (uuid.UUID, error)
+}
+
+// holdsUniqueKey reports whether the task still blocks other tasks with the same unique key.
+func (t *Task) holdsUniqueKey(now time.Time) bool {
+	if t.UniqueKey == nil {
+		return false
+	}
+	if t.Status != TaskStatusPending && t.Status != TaskStatusProcessing {
+		return false
+	}
+	return t.UniqueUntil == nil || now.Before(*t.UniqueUntil)
+}
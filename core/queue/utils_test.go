@@ -117,7 +117,7 @@ func TestQualifiedStructName_ThroughEnqueuer(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo.tasks = nil // Clear tasks
 
-			err := enqueuer.Enqueue(context.Background(), tt.payload)
+			_, err := enqueuer.Enqueue(context.Background(), tt.payload)
 			require.NoError(t, err)
 
 			require.Len(t, repo.tasks, 1)
@@ -164,7 +164,7 @@ func TestQualifiedStructName_AnonymousStruct(t *testing.T) {
 		Value: 42,
 	}
 
-	err = enqueuer.Enqueue(context.Background(), payload)
+	_, err = enqueuer.Enqueue(context.Background(), payload)
 	require.NoError(t, err)
 
 	require.Len(t, repo.tasks, 1)
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -21,8 +22,10 @@ type WorkerRepository interface {
 	// CompleteTask marks task as completed
 	CompleteTask(ctx context.Context, taskID uuid.UUID) error
 
-	// FailTask marks task as failed and increments retry count
-	FailTask(ctx context.Context, taskID uuid.UUID, errorMsg string) error
+	// FailTask records the error and increments retry count.
+	// When retryAt is nil the task is marked as failed, otherwise it returns
+	// to pending and is scheduled at retryAt. The worker makes the retry decision.
+	FailTask(ctx context.Context, taskID uuid.UUID, errorMsg string, retryAt *time.Time) error
 
 	// MoveToDLQ moves task to dead letter queue
 	MoveToDLQ(ctx context.Context, taskID uuid.UUID) error
@@ -31,11 +34,29 @@ type WorkerRepository interface {
 	ExtendLock(ctx context.Context, taskID uuid.UUID, duration time.Duration) error
 }
 
+// ReleaseRepository is implemented by repositories that return claimed tasks to pending
+// without recording a failure. Required by worker concurrency and rate limits.
+type ReleaseRepository interface {
+	// ReleaseTask unlocks a processing task and schedules it at scheduledAt
+	// without incrementing its retry count
+	ReleaseTask(ctx context.Context, taskID uuid.UUID, scheduledAt time.Time) error
+}
+
+// OrderedClaimRepository is implemented by repositories that claim tasks by queue order.
+// Used by workers with strict or weighted queues; without it the worker claims
+// from one queue at a time.
+type OrderedClaimRepository interface {
+	// ClaimTaskInOrder atomically claims the highest priority available task
+	// of the first queue in the list that has one
+	ClaimTaskInOrder(ctx context.Context, workerID uuid.UUID, queues []string, lockDuration time.Duration) (*Task, error)
+}
+
 // Worker processes tasks from the queue
 type Worker struct {
 	repo     WorkerRepository
 	handlers map[string]Handler
 	queues   []string
+	order    queueOrder
 	workerID uuid.UUID
 	sem      chan struct{}
 	wg       sync.WaitGroup
@@ -43,14 +64,25 @@ type Worker struct {
 	stopMu   sync.Mutex // Protects stopping state and WaitGroup operations
 
 	// Configuration
-	pullInterval time.Duration
-	lockTimeout  time.Duration
-	logger       *slog.Logger
+	pullInterval      time.Duration
+	lockTimeout       time.Duration
+	heartbeatInterval time.Duration
+	taskTimeout       time.Duration
+	drainTimeout      time.Duration
+	logger            *slog.Logger
+	backoff           Backoff
+	middleware        []HandlerMiddleware
+	metrics           Metrics
+	cipher            *PayloadCipher
+	throttle          *throttle
+	inflight          *inflight
 
 	// State management
-	ctx      context.Context
-	cancel   context.CancelFunc
-	stopping atomic.Bool
+	ctx         context.Context
+	cancel      context.CancelFunc
+	taskCtx     context.Context // Parent of handler contexts, cancelled when a drain times out
+	cancelTasks context.CancelFunc
+	stopping    atomic.Bool
 }
 
 // NewWorker creates a new task worker
@@ -66,6 +98,8 @@ func NewWorker(repo WorkerRepository, opts ...WorkerOption) (*Worker, error) {
 		lockTimeout:        5 * time.Minute,
 		maxConcurrentTasks: 1,
 		logger:             slog.Default(),
+		backoff:            defaultBackoff,
+		metrics:            noopMetrics{},
 	}
 
 	// Apply options
@@ -73,15 +107,33 @@ func NewWorker(repo WorkerRepository, opts ...WorkerOption) (*Worker, error) {
 		opt(options)
 	}
 
+	// Timings derived from the lock timeout unless set explicitly
+	if options.heartbeatInterval == 0 {
+		options.heartbeatInterval = max(options.lockTimeout/3, time.Millisecond)
+	}
+	if options.taskTimeout == 0 {
+		options.taskTimeout = options.lockTimeout
+	}
+
 	return &Worker{
-		repo:         repo,
-		handlers:     make(map[string]Handler),
-		queues:       options.queues,
-		workerID:     uuid.New(),
-		sem:          make(chan struct{}, options.maxConcurrentTasks),
-		pullInterval: options.pullInterval,
-		lockTimeout:  options.lockTimeout,
-		logger:       options.logger,
+		repo:              repo,
+		handlers:          make(map[string]Handler),
+		queues:            options.queues,
+		order:             options.queueOrder,
+		workerID:          uuid.New(),
+		sem:               make(chan struct{}, options.maxConcurrentTasks),
+		pullInterval:      options.pullInterval,
+		lockTimeout:       options.lockTimeout,
+		heartbeatInterval: options.heartbeatInterval,
+		taskTimeout:       options.taskTimeout,
+		drainTimeout:      options.drainTimeout,
+		logger:            options.logger,
+		backoff:           options.backoff,
+		middleware:        options.middleware,
+		metrics:           options.metrics,
+		cipher:            options.cipher,
+		throttle:          newThrottle(options.queueConcurrency, options.queueRateLimits, options.pullInterval),
+		inflight:          newInflight(),
 	}, nil
 }
 
@@ -121,7 +173,15 @@ func (w *Worker) Start(ctx context.Context) error {
 		return ErrNoHandlers
 	}
 
+	if _, ok := w.repo.(ReleaseRepository); !ok && (w.hasLimits() || w.drainTimeout > 0) {
+		w.mu.Unlock()
+		return ErrReleaseNotSupported
+	}
+
 	w.ctx, w.cancel = context.WithCancel(ctx)
+	// Handlers are not tied to the worker context so shutdown lets them finish
+	w.taskCtx, w.cancelTasks = context.WithCancel(context.Background())
+	w.inflight.reset()
 	w.mu.Unlock()
 
 	// Reset stopping flag
@@ -138,7 +198,9 @@ func (w *Worker) Start(ctx context.Context) error {
 	return nil
 }
 
-// Stop gracefully shuts down the worker
+// Stop gracefully shuts down the worker: it stops pulling tasks and waits for the
+// running ones to complete. With WithDrainTimeout, handlers still running after the
+// timeout are cancelled and their tasks released back to pending.
 func (w *Worker) Stop() error {
 	w.mu.Lock()
 	if w.cancel == nil {
@@ -151,7 +213,7 @@ func (w *Worker) Stop() error {
 	w.stopping.Store(true)
 	w.stopMu.Unlock()
 
-	cancel := w.cancel
+	cancel, cancelTasks := w.cancel, w.cancelTasks
 	w.cancel = nil
 	w.mu.Unlock()
 
@@ -162,12 +224,16 @@ func (w *Worker) Stop() error {
 	w.logger.Info("worker stopping, waiting for active tasks to complete",
 		slog.String("worker_id", w.workerID.String()))
 
-	w.wg.Wait()
+	var err error
+	if !w.wait() {
+		err = w.abort(cancelTasks)
+	}
+	cancelTasks()
 
 	w.logger.Info("worker stopped",
 		slog.String("worker_id", w.workerID.String()))
 
-	return nil
+	return err
 }
 
 // Run starts the worker and returns a function suitable for errgroup
@@ -232,8 +298,14 @@ func (w *Worker) run() {
 
 // pullAndProcess pulls a task and processes it
 func (w *Worker) pullAndProcess() error {
+	// Skip queues at their concurrency or rate limit
+	queues := w.throttle.claimable(w.order.next(w.queues), time.Now())
+	if len(queues) == 0 {
+		return nil
+	}
+
 	// Claim next available task
-	task, err := w.repo.ClaimTask(w.ctx, w.workerID, w.queues, w.lockTimeout)
+	task, err := w.claimTask(queues)
 	if err != nil {
 		// Check if it's ErrNoTaskToClaim - this is normal, not an error
 		if errors.Is(err, ErrNoTaskToClaim) {
@@ -253,13 +325,98 @@ func (w *Worker) pullAndProcess() error {
 		slog.String("task_name", task.TaskName),
 		slog.String("queue", task.Queue))
 
+	release, throttled, err := w.reserve(task)
+	if err != nil || throttled {
+		return err
+	}
+	defer release()
+
 	// Process the task
 	return w.processTask(task)
 }
 
+// claimTask claims the next task from the queues, by priority or by queue order
+// depending on the worker configuration
+func (w *Worker) claimTask(queues []string) (*Task, error) {
+	if !w.order.claimsInOrder() {
+		return w.repo.ClaimTask(w.ctx, w.workerID, queues, w.lockTimeout)
+	}
+
+	if repo, ok := w.repo.(OrderedClaimRepository); ok {
+		return repo.ClaimTaskInOrder(w.ctx, w.workerID, queues, w.lockTimeout)
+	}
+
+	// One claim per queue until one of them has a task
+	for _, queue := range queues {
+		task, err := w.repo.ClaimTask(w.ctx, w.workerID, []string{queue}, w.lockTimeout)
+		if errors.Is(err, ErrNoTaskToClaim) || (err == nil && task == nil) {
+			continue
+		}
+		return task, err
+	}
+	return nil, ErrNoTaskToClaim
+}
+
+// reserve applies the concurrency and rate limits to a claimed task. A throttled task
+// is released back to pending and scheduled after the limit's delay.
+func (w *Worker) reserve(task *Task) (func(), bool, error) {
+	var limits handlerLimits
+	w.mu.RLock()
+	if h, ok := w.handlers[task.TaskName].(configuredHandler); ok {
+		limits.concurrency = h.handlerOptions().concurrency
+		limits.rate = h.handlerOptions().rateLimit
+	}
+	w.mu.RUnlock()
+
+	release, delay, err := w.throttle.acquire(w.ctx, task, limits)
+	if err != nil {
+		w.logger.Warn("rate limiter failed, processing task without limit",
+			slog.String("worker_id", w.workerID.String()),
+			slog.String("task_id", task.ID.String()),
+			slog.String("error", err.Error()))
+	}
+	if delay == 0 {
+		return release, false, nil
+	}
+
+	w.logger.Debug("task throttled",
+		slog.String("worker_id", w.workerID.String()),
+		slog.String("task_id", task.ID.String()),
+		slog.String("task_name", task.TaskName),
+		slog.String("queue", task.Queue),
+		slog.Duration("retry_in", delay))
+
+	releaser, ok := w.repo.(ReleaseRepository)
+	if !ok {
+		return nil, true, ErrReleaseNotSupported
+	}
+	if err := releaser.ReleaseTask(w.ctx, task.ID, time.Now().Add(delay)); err != nil {
+		return nil, true, fmt.Errorf("failed to release throttled task %s: %w", task.ID, err)
+	}
+
+	return nil, true, nil
+}
+
+// hasLimits reports whether the worker or any registered handler has concurrency
+// or rate limits. Must be called with w.mu held.
+func (w *Worker) hasLimits() bool {
+	if w.throttle.enabled() {
+		return true
+	}
+	for _, handler := range w.handlers {
+		if h, ok := handler.(configuredHandler); ok {
+			if h.handlerOptions().concurrency > 0 || h.handlerOptions().rateLimit != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // processTask executes a task with its handler
 func (w *Worker) processTask(task *Task) (retErr error) {
 	start := time.Now()
+	var handler Handler
 
 	// Add panic recovery
 	defer func() {
@@ -270,35 +427,65 @@ func (w *Worker) processTask(task *Task) (retErr error) {
 				slog.String("task_id", task.ID.String()),
 				slog.String("task_name", task.TaskName),
 				slog.Any("panic", r))
-			// Treat panic as task failure
-			duration := time.Since(start)
-			_ = w.handleTaskFailure(task, retErr, duration)
+			// Treat panic as task failure unless the task was released on shutdown
+			if w.inflight.remove(task.ID) {
+				duration := time.Since(start)
+				_ = w.handleTaskFailure(task, handler, retErr, duration)
+			}
 		}
 	}()
 
 	// Find handler
 	w.mu.RLock()
 	handler, ok := w.handlers[task.TaskName]
+	taskCtx := w.taskCtx
 	w.mu.RUnlock()
 
 	if !ok {
 		return w.handleMissingHandler(task)
 	}
 
+	// A task claimed while the worker drains goes straight back to pending
+	if !w.inflight.add(task) {
+		return w.releaseTask(task)
+	}
+	w.metrics.TaskStarted(task.Queue, task.TaskName, start.Sub(task.ScheduledAt))
+
 	// Create context with timeout that's not tied to worker lifecycle
 	// This allows graceful shutdown to let tasks complete
-	ctx, cancel := context.WithTimeout(context.Background(), w.lockTimeout)
+	timeout := w.timeoutFor(handler)
+	ctx, cancel := context.WithTimeout(taskCtx, timeout)
 	defer cancel()
 
-	// Execute handler
-	err := handler.Handle(ctx, task.Payload)
+	// Keep the task locked while the handler runs
+	stopHeartbeat := w.startHeartbeat(task)
+	defer stopHeartbeat()
+
+	// Collect the result stored by the handler with SetResult
+	result := &resultHolder{}
+	ctx = withResultHolder(ctx, result)
+
+	// Execute handler wrapped in worker and handler middleware
+	err := w.handleFunc(handler)(ctx, task)
 	duration := time.Since(start)
+	stopHeartbeat()
+
+	if !w.inflight.remove(task.ID) {
+		w.logger.Warn("task finished after it was released on shutdown, discarding outcome",
+			slog.String("worker_id", w.workerID.String()),
+			slog.String("task_id", task.ID.String()),
+			slog.String("task_name", task.TaskName))
+		return nil
+	}
 
 	if err != nil {
-		return w.handleTaskFailure(task, err, duration)
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = timeoutError(timeout, err)
+		}
+		return w.handleTaskFailure(task, handler, err, duration)
 	}
 
-	return w.handleTaskSuccess(task, duration)
+	return w.handleTaskSuccess(task, result.data, duration)
 }
 
 // handleMissingHandler processes tasks that have no registered handler
@@ -310,6 +497,9 @@ func (w *Worker) processTask(task *Task) (retErr error) {
 // 2. Manually requeue tasks from DLQ once handler is available
 // 3. Investigate why tasks were enqueued without corresponding handlers
 func (w *Worker) handleMissingHandler(task *Task) error {
+	ctx, cancel := w.outcomeContext()
+	defer cancel()
+
 	w.logger.Error("no handler registered for task type",
 		slog.String("worker_id", w.workerID.String()),
 		slog.String("task_id", task.ID.String()),
@@ -317,14 +507,17 @@ func (w *Worker) handleMissingHandler(task *Task) error {
 
 	// Mark as failed to record the specific error
 	errorMsg := "no handler registered for task type: " + task.TaskName
-	if err := w.repo.FailTask(w.ctx, task.ID, errorMsg); err != nil {
+	if err := w.repo.FailTask(ctx, task.ID, errorMsg, nil); err != nil {
 		return fmt.Errorf("failed to mark task %s as failed: %w", task.ID, err)
 	}
+	w.metrics.TaskFailed(task.Queue, task.TaskName, 0)
 
 	// Move directly to DLQ - no point in retrying without a handler
-	if err := w.repo.MoveToDLQ(w.ctx, task.ID); err != nil {
+	if err := w.repo.MoveToDLQ(ctx, task.ID); err != nil {
 		return fmt.Errorf("failed to move task %s to DLQ: %w", task.ID, err)
 	}
+	w.metrics.TaskMovedToDLQ(task.Queue, task.TaskName)
+	w.finishBatchTask(ctx, task, false)
 
 	return ErrHandlerNotFound
 }
@@ -332,53 +525,188 @@ func (w *Worker) handleMissingHandler(task *Task) error {
 // handleTaskFailure processes failed task execution
 //
 // Retry decision logic:
-// 1. Always calls FailTask first to record the error and increment retry count
-// 2. Checks if task has exhausted all retries (RetryCount >= MaxRetries)
-// 3. If retries remain: FailTask already reset task to "pending" with backoff
-// 4. If no retries remain: Move to DLQ for manual inspection
+// 1. NoRetry errors and tasks that used all retries (RetryCount >= MaxRetries) are not retried
+// 2. Otherwise the delay comes from RetryAfter, or from the task, handler or worker backoff
+// 3. FailTask persists the decision: pending at retryAt, or failed when retryAt is nil
+// 4. Tasks that will not be retried are moved to DLQ for manual inspection
 //
-// The separation of FailTask and MoveToDLQ allows the storage layer to:
-// - Track failure history and error messages
-// - Implement exponential backoff strategies
-// - Maintain audit trails of task processing attempts
-func (w *Worker) handleTaskFailure(task *Task, execErr error, duration time.Duration) error {
-	w.logger.Error("task failed",
+// Keeping the decision in the worker means storage backends only record outcomes
+// and every backend applies identical retry semantics.
+func (w *Worker) handleTaskFailure(task *Task, handler Handler, execErr error, duration time.Duration) error {
+	ctx, cancel := w.outcomeContext()
+	defer cancel()
+
+	retryAt := w.nextRetryAt(task, handler, execErr)
+
+	attrs := []any{
 		slog.String("worker_id", w.workerID.String()),
 		slog.String("task_id", task.ID.String()),
 		slog.String("task_name", task.TaskName),
 		slog.Int("retry_count", int(task.RetryCount)),
 		slog.Int("max_retries", int(task.MaxRetries)),
 		slog.Duration("duration", duration),
-		slog.String("error", execErr.Error()))
+		slog.String("error", execErr.Error()),
+	}
+	if errors.Is(execErr, ErrTaskTimeout) {
+		attrs = append(attrs, slog.String("reason", "timeout"))
+	}
+	if retryAt != nil {
+		attrs = append(attrs, slog.Time("retry_at", *retryAt))
+	}
+	w.logger.Error("task failed", attrs...)
 
-	// Always mark as failed first to record the error
-	if err := w.repo.FailTask(w.ctx, task.ID, execErr.Error()); err != nil {
+	if err := w.repo.FailTask(ctx, task.ID, execErr.Error(), retryAt); err != nil {
 		return fmt.Errorf("failed to update task %s status to failed: %w", task.ID, err)
 	}
+	w.metrics.TaskFailed(task.Queue, task.TaskName, duration)
 
-	// Check if this was the last retry (FailTask may have updated retry count)
-	if task.RetryCount >= task.MaxRetries {
-		// Move to DLQ
-		if err := w.repo.MoveToDLQ(w.ctx, task.ID); err != nil {
+	if retryAt == nil {
+		if err := w.repo.MoveToDLQ(ctx, task.ID); err != nil {
 			return fmt.Errorf("failed to move task %s to DLQ after max retries: %w", task.ID, err)
 		}
+		w.metrics.TaskMovedToDLQ(task.Queue, task.TaskName)
 
 		w.logger.Warn("task moved to dead letter queue",
 			slog.String("worker_id", w.workerID.String()),
 			slog.String("task_id", task.ID.String()),
 			slog.String("task_name", task.TaskName))
 
-		return nil
+		w.finishBatchTask(ctx, task, false)
 	}
 
 	return nil
 }
 
+// timeoutFor resolves the maximum execution time: handler option, then worker default.
+func (w *Worker) timeoutFor(handler Handler) time.Duration {
+	if h, ok := handler.(configuredHandler); ok {
+		if d := h.handlerOptions().timeout; d > 0 {
+			return d
+		}
+	}
+	return w.taskTimeout
+}
+
+// startHeartbeat extends the task lock every heartbeat interval until the returned
+// function is called. The returned function is safe to call more than once.
+//
+// Lock extension uses its own context: the worker context is cancelled on shutdown
+// while running tasks are still allowed to finish and must keep their locks.
+func (w *Worker) startHeartbeat(task *Task) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(w.heartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), w.heartbeatInterval)
+				err := w.repo.ExtendLock(ctx, task.ID, w.lockTimeout)
+				cancel()
+				if err != nil {
+					w.logger.Warn("failed to extend task lock",
+						slog.String("worker_id", w.workerID.String()),
+						slog.String("task_id", task.ID.String()),
+						slog.String("task_name", task.TaskName),
+						slog.String("error", err.Error()))
+				}
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			<-stopped
+		})
+	}
+}
+
+// handleFunc wraps the handler in the worker middleware, then the handler's own middleware
+func (w *Worker) handleFunc(handler Handler) HandleFunc {
+	h := func(ctx context.Context, task *Task) error {
+		payload, err := w.decryptPayload(ctx, task)
+		if err != nil {
+			return err
+		}
+		return handler.Handle(ctx, payload)
+	}
+
+	middleware := w.middleware
+	if ch, ok := handler.(configuredHandler); ok && len(ch.handlerOptions().middleware) > 0 {
+		middleware = append(slices.Clip(middleware), ch.handlerOptions().middleware...)
+	}
+
+	return chainMiddleware(h, middleware...)
+}
+
+// decryptPayload returns the plaintext payload of a task
+func (w *Worker) decryptPayload(ctx context.Context, task *Task) ([]byte, error) {
+	if !IsEncryptedPayload(task.Payload) {
+		return task.Payload, nil
+	}
+	if w.cipher == nil {
+		return nil, ErrPayloadEncrypted
+	}
+
+	payload, err := w.cipher.Decrypt(ctx, task.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload of task %s: %w", task.ID, err)
+	}
+	return payload, nil
+}
+
+// nextRetryAt returns when the failed task should run again, or nil if it must not be retried.
+func (w *Worker) nextRetryAt(task *Task, handler Handler, execErr error) *time.Time {
+	if errors.Is(execErr, ErrSkipRetry) || task.RetryCount >= task.MaxRetries {
+		return nil
+	}
+
+	var delay time.Duration
+	var retryAfter *retryAfterError
+	if errors.As(execErr, &retryAfter) {
+		delay = retryAfter.delay
+	} else {
+		delay = w.backoffFor(task, handler).Delay(int(task.RetryCount) + 1)
+	}
+
+	retryAt := time.Now().Add(delay)
+	return &retryAt
+}
+
+// backoffFor resolves the backoff for a task: task policy, then handler option, then worker default.
+func (w *Worker) backoffFor(task *Task, handler Handler) Backoff {
+	if task.Backoff != nil {
+		return *task.Backoff
+	}
+	if h, ok := handler.(configuredHandler); ok {
+		if b := h.handlerOptions().backoff; b != nil {
+			return b
+		}
+	}
+	return w.backoff
+}
+
 // handleTaskSuccess processes successful task completion
-func (w *Worker) handleTaskSuccess(task *Task, duration time.Duration) error {
-	if err := w.repo.CompleteTask(w.ctx, task.ID); err != nil {
-		return fmt.Errorf("failed to mark task %s as completed: %w", task.ID, err)
+// Chain tasks create their next step in the same repository call, using the
+// handler result as payload.
+func (w *Worker) handleTaskSuccess(task *Task, result []byte, duration time.Duration) error {
+	ctx, cancel := w.outcomeContext()
+	defer cancel()
+
+	if err := w.completeTask(ctx, task, result); err != nil {
+		return err
 	}
+	w.metrics.TaskSucceeded(task.Queue, task.TaskName, duration)
+	w.finishBatchTask(ctx, task, true)
 
 	w.logger.Info("task completed successfully",
 		slog.String("worker_id", w.workerID.String()),
@@ -390,8 +718,90 @@ func (w *Worker) handleTaskSuccess(task *Task, duration time.Duration) error {
 	return nil
 }
 
-// ExtendLockForTask extends the lock timeout for a long-running task
-// This should be called periodically for tasks that take longer than lockTimeout
+// completeTask marks the task completed, storing its result or advancing its chain
+func (w *Worker) completeTask(ctx context.Context, task *Task, result []byte) error {
+	if len(task.Next) > 0 {
+		if repo, ok := w.repo.(WorkflowRepository); ok {
+			if result == nil {
+				result = []byte("null")
+			}
+			payload, err := w.encryptResult(ctx, task, result)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt next chain step of task %s: %w", task.ID, err)
+			}
+			next := NextTask(task.Next, payload)
+			if err := repo.CompleteTaskWithNext(ctx, task.ID, next); err != nil {
+				return fmt.Errorf("failed to complete task %s with next chain step: %w", task.ID, err)
+			}
+			w.metrics.TaskEnqueued(next.Queue, next.TaskName)
+			return nil
+		}
+
+		w.logger.Error("repository does not support chains, remaining steps are dropped",
+			slog.String("worker_id", w.workerID.String()),
+			slog.String("task_id", task.ID.String()),
+			slog.String("task_name", task.TaskName))
+	}
+
+	if repo, ok := w.repo.(ResultRepository); ok && result != nil {
+		result, err := w.encryptResult(ctx, task, result)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt result of task %s: %w", task.ID, err)
+		}
+		if err := repo.CompleteTaskWithResult(ctx, task.ID, result); err != nil {
+			return fmt.Errorf("failed to mark task %s as completed: %w", task.ID, err)
+		}
+		return nil
+	}
+
+	if err := w.repo.CompleteTask(ctx, task.ID); err != nil {
+		return fmt.Errorf("failed to mark task %s as completed: %w", task.ID, err)
+	}
+	return nil
+}
+
+// encryptResult encrypts a result with the workspace of the task when its payload is encrypted
+func (w *Worker) encryptResult(ctx context.Context, task *Task, result []byte) ([]byte, error) {
+	if w.cipher == nil {
+		return result, nil
+	}
+	return w.cipher.encryptLike(ctx, task.Payload, result)
+}
+
+// finishBatchTask records the task outcome in its batch. Failures are logged only:
+// the task itself has already been completed or moved to the DLQ.
+func (w *Worker) finishBatchTask(ctx context.Context, task *Task, succeeded bool) {
+	if task.BatchID == nil {
+		return
+	}
+
+	repo, ok := w.repo.(WorkflowRepository)
+	if !ok {
+		return
+	}
+
+	batch, err := repo.FinishBatchTask(ctx, *task.BatchID, succeeded)
+	if err != nil {
+		w.logger.Error("failed to update batch progress",
+			slog.String("worker_id", w.workerID.String()),
+			slog.String("task_id", task.ID.String()),
+			slog.String("batch_id", task.BatchID.String()),
+			slog.String("error", err.Error()))
+		return
+	}
+
+	if batch.Finished() {
+		w.logger.Info("batch finished",
+			slog.String("worker_id", w.workerID.String()),
+			slog.String("batch_id", batch.ID.String()),
+			slog.Int("succeeded", batch.Succeeded),
+			slog.Int("failed", batch.Failed))
+	}
+}
+
+// ExtendLockForTask extends the lock timeout for a long-running task.
+// The worker already extends locks of running tasks on every heartbeat,
+// so this is only needed to push a lock further ahead manually.
 func (w *Worker) ExtendLockForTask(ctx context.Context, taskID uuid.UUID, extension time.Duration) error {
 	return w.repo.ExtendLock(ctx, taskID, extension)
 }
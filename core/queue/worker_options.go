@@ -1,8 +1,12 @@
 package queue
 
 import (
+	"cmp"
 	"log/slog"
+	"slices"
 	"time"
+
+	"github.com/dmitrymomot/foundation/pkg/ratelimiter"
 )
 
 // WorkerOption is a functional option for configuring a worker
@@ -10,16 +14,70 @@ type WorkerOption func(*workerOptions)
 
 type workerOptions struct {
 	queues             []string
+	queueOrder         queueOrder
 	pullInterval       time.Duration
 	lockTimeout        time.Duration
+	heartbeatInterval  time.Duration
+	taskTimeout        time.Duration
+	drainTimeout       time.Duration
 	maxConcurrentTasks int
 	logger             *slog.Logger
+	backoff            Backoff
+	middleware         []HandlerMiddleware
+	metrics            Metrics
+	cipher             *PayloadCipher
+	queueConcurrency   map[string]int
+	queueRateLimits    map[string]*ratelimiter.Bucket
 }
 
-// WithQueues sets which queues the worker should pull from
+// WithQueues sets which queues the worker should pull from.
+// Tasks are claimed by priority across all queues; see WithStrictQueues
+// and WithWeightedQueues for queue based ordering.
 func WithQueues(queues ...string) WorkerOption {
 	return func(o *workerOptions) {
 		o.queues = queues
+		o.queueOrder = queueOrder{}
+	}
+}
+
+// WithStrictQueues sets the queues the worker pulls from in strict order: a queue
+// is only claimed from while all queues before it have no available tasks.
+// Priority orders tasks within a queue. Lower queues can starve while higher ones stay busy.
+func WithStrictQueues(queues ...string) WorkerOption {
+	return func(o *workerOptions) {
+		o.queues = queues
+		o.queueOrder = queueOrder{mode: queueModeStrict}
+	}
+}
+
+// WithWeightedQueues sets the queues the worker pulls from with their weights, e.g.
+// critical=6, default=3, low=1. Each claim tries the queues in a random order where a
+// queue comes first with probability weight/total, so a busy queue gets most of the
+// throughput without starving the others. Priority orders tasks within a queue.
+// Queues with a weight below 1 are ignored.
+func WithWeightedQueues(weights map[string]int) WorkerOption {
+	return func(o *workerOptions) {
+		queues := make([]string, 0, len(weights))
+		for queue, weight := range weights {
+			if queue != "" && weight > 0 {
+				queues = append(queues, queue)
+			}
+		}
+		if len(queues) == 0 {
+			return
+		}
+
+		// Heaviest first for a stable order in logs
+		slices.SortFunc(queues, func(a, b string) int {
+			return cmp.Or(cmp.Compare(weights[b], weights[a]), cmp.Compare(a, b))
+		})
+
+		order := queueOrder{mode: queueModeWeighted, weights: make([]int, len(queues))}
+		for i, queue := range queues {
+			order.weights[i] = weights[queue]
+		}
+		o.queues = queues
+		o.queueOrder = order
 	}
 }
 
@@ -41,6 +99,42 @@ func WithLockTimeout(d time.Duration) WorkerOption {
 	}
 }
 
+// WithHeartbeatInterval sets how often the worker extends the lock of a running task.
+// Each heartbeat moves the lock deadline one lock timeout ahead, so tasks running longer
+// than the lock timeout are not reclaimed by other workers. Defaults to a third of the lock timeout.
+func WithHeartbeatInterval(d time.Duration) WorkerOption {
+	return func(o *workerOptions) {
+		if d > 0 {
+			o.heartbeatInterval = d
+		}
+	}
+}
+
+// WithTaskTimeout sets the default maximum execution time of a task.
+// The handler context is cancelled when it is exceeded and the failure is recorded
+// with ErrTaskTimeout. Defaults to the lock timeout; WithHandlerTimeout overrides it per handler.
+func WithTaskTimeout(d time.Duration) WorkerOption {
+	return func(o *workerOptions) {
+		if d > 0 {
+			o.taskTimeout = d
+		}
+	}
+}
+
+// WithDrainTimeout sets how long Stop waits for running tasks. Handlers still running
+// after the timeout get their context cancelled and their tasks are released back to
+// pending without counting a retry, so other workers pick them up right away instead of
+// waiting for the locks to expire. Outcomes of handlers returning after the release are
+// discarded. Requires a repository implementing ReleaseRepository.
+// By default Stop waits for running tasks indefinitely.
+func WithDrainTimeout(d time.Duration) WorkerOption {
+	return func(o *workerOptions) {
+		if d > 0 {
+			o.drainTimeout = d
+		}
+	}
+}
+
 // WithMaxConcurrentTasks sets the maximum number of concurrent tasks
 func WithMaxConcurrentTasks(n int) WorkerOption {
 	return func(o *workerOptions) {
@@ -50,6 +144,37 @@ func WithMaxConcurrentTasks(n int) WorkerOption {
 	}
 }
 
+// WithQueueConcurrency limits how many tasks of the queue the worker processes at once,
+// so a flood on one queue can't take every slot of WithMaxConcurrentTasks.
+// The worker stops claiming from a queue at its limit.
+func WithQueueConcurrency(queue string, n int) WorkerOption {
+	return func(o *workerOptions) {
+		if queue == "" || n <= 0 {
+			return
+		}
+		if o.queueConcurrency == nil {
+			o.queueConcurrency = make(map[string]int)
+		}
+		o.queueConcurrency[queue] = n
+	}
+}
+
+// WithQueueRateLimit limits the throughput of the queue with a token bucket, e.g.
+// 10 tasks per second for a third-party API. A token keyed "queue:<name>" is
+// consumed for every task; throttled tasks return to pending without counting a retry.
+// Use a bucket with a shared store to enforce the limit across worker instances.
+func WithQueueRateLimit(queue string, limiter *ratelimiter.Bucket) WorkerOption {
+	return func(o *workerOptions) {
+		if queue == "" || limiter == nil {
+			return
+		}
+		if o.queueRateLimits == nil {
+			o.queueRateLimits = make(map[string]*ratelimiter.Bucket)
+		}
+		o.queueRateLimits[queue] = limiter
+	}
+}
+
 // WithWorkerLogger sets the logger for the worker
 func WithWorkerLogger(logger *slog.Logger) WorkerOption {
 	return func(o *workerOptions) {
@@ -58,3 +183,42 @@ func WithWorkerLogger(logger *slog.Logger) WorkerOption {
 		}
 	}
 }
+
+// WithWorkerBackoff sets the default retry backoff for all handlers of the worker.
+// Handler and task level backoff settings take precedence.
+func WithWorkerBackoff(backoff Backoff) WorkerOption {
+	return func(o *workerOptions) {
+		if backoff != nil {
+			o.backoff = backoff
+		}
+	}
+}
+
+// WithWorkerMetrics reports task starts, outcomes and durations to metrics
+func WithWorkerMetrics(metrics Metrics) WorkerOption {
+	return func(o *workerOptions) {
+		if metrics != nil {
+			o.metrics = metrics
+		}
+	}
+}
+
+// WithWorkerEncryption decrypts encrypted payloads with the cipher before they reach
+// the handler; middleware still sees the stored payload. Results and chain steps
+// following an encrypted task are encrypted for the same workspace.
+// Without it, tasks with encrypted payloads fail with ErrPayloadEncrypted.
+func WithWorkerEncryption(cipher *PayloadCipher) WorkerOption {
+	return func(o *workerOptions) {
+		if cipher != nil {
+			o.cipher = cipher
+		}
+	}
+}
+
+// WithWorkerMiddleware adds middleware applied to every handler of the worker.
+// Worker middleware runs outside handler middleware; the first one is the outermost.
+func WithWorkerMiddleware(middleware ...HandlerMiddleware) WorkerOption {
+	return func(o *workerOptions) {
+		o.middleware = append(o.middleware, middleware...)
+	}
+}
@@ -38,8 +38,8 @@ func (m *MockWorkerRepository) CompleteTask(ctx context.Context, taskID uuid.UUI
 	return args.Error(0)
 }
 
-func (m *MockWorkerRepository) FailTask(ctx context.Context, taskID uuid.UUID, errorMsg string) error {
-	args := m.Called(ctx, taskID, errorMsg)
+func (m *MockWorkerRepository) FailTask(ctx context.Context, taskID uuid.UUID, errorMsg string, retryAt *time.Time) error {
+	args := m.Called(ctx, taskID, errorMsg, retryAt)
 	return args.Error(0)
 }
 
@@ -337,7 +337,9 @@ func TestWorker_ProcessTask(t *testing.T) {
 			Return(task, nil).Once()
 		mockRepo.On("ClaimTask", mock.Anything, mock.Anything, []string{queue.DefaultQueueName}, mock.Anything).
 			Return(nil, queue.ErrNoTaskToClaim).Maybe()
-		mockRepo.On("FailTask", mock.Anything, task.ID, "processing failed").Return(nil).Once()
+		mockRepo.On("FailTask", mock.Anything, task.ID, "processing failed", mock.MatchedBy(func(at *time.Time) bool {
+			return at != nil && at.After(time.Now())
+		})).Return(nil).Once()
 
 		worker, err := queue.NewWorker(mockRepo, queue.WithPullInterval(50*time.Millisecond))
 		require.NoError(t, err)
@@ -392,7 +394,7 @@ func TestWorker_ProcessTask(t *testing.T) {
 			Return(task, nil).Once()
 		mockRepo.On("ClaimTask", mock.Anything, mock.Anything, []string{queue.DefaultQueueName}, mock.Anything).
 			Return(nil, queue.ErrNoTaskToClaim).Maybe()
-		mockRepo.On("FailTask", mock.Anything, task.ID, "permanent failure").Return(nil).Once()
+		mockRepo.On("FailTask", mock.Anything, task.ID, "permanent failure", (*time.Time)(nil)).Return(nil).Once()
 		mockRepo.On("MoveToDLQ", mock.Anything, task.ID).Return(nil).Once()
 
 		worker, err := queue.NewWorker(mockRepo, queue.WithPullInterval(50*time.Millisecond))
@@ -441,7 +443,7 @@ func TestWorker_ProcessTask(t *testing.T) {
 			Return(task, nil).Once()
 		mockRepo.On("ClaimTask", mock.Anything, mock.Anything, []string{queue.DefaultQueueName}, mock.Anything).
 			Return(nil, queue.ErrNoTaskToClaim).Maybe()
-		mockRepo.On("FailTask", mock.Anything, task.ID, "no handler registered for task type: unregistered.Handler").Return(nil).Once()
+		mockRepo.On("FailTask", mock.Anything, task.ID, "no handler registered for task type: unregistered.Handler", (*time.Time)(nil)).Return(nil).Once()
 		mockRepo.On("MoveToDLQ", mock.Anything, task.ID).Return(nil).Once()
 
 		worker, err := queue.NewWorker(mockRepo, queue.WithPullInterval(50*time.Millisecond))
@@ -496,7 +498,7 @@ func TestWorker_ProcessTask(t *testing.T) {
 			Return(nil, queue.ErrNoTaskToClaim).Maybe()
 		mockRepo.On("FailTask", mock.Anything, task.ID, mock.MatchedBy(func(msg string) bool {
 			return strings.Contains(msg, "panic")
-		})).Return(nil).Once()
+		}), mock.Anything).Return(nil).Once()
 
 		worker, err := queue.NewWorker(mockRepo, queue.WithPullInterval(50*time.Millisecond))
 		require.NoError(t, err)
@@ -880,3 +882,244 @@ func TestWorkerWithLogger(t *testing.T) {
 	// The main purpose of this test is to ensure the logger option is accepted
 	// and doesn't cause any issues during initialization
 }
+
+func TestWorker_RetryDecisions(t *testing.T) {
+	t.Parallel()
+
+	// runFailingTask processes a single task whose handler returns handlerErr
+	// and returns the retryAt value passed to FailTask.
+	runFailingTask := func(t *testing.T, task *queue.Task, handlerErr error, workerOpts []queue.WorkerOption, handlerOpts ...queue.HandlerOption) *time.Time {
+		t.Helper()
+
+		mockRepo := new(MockWorkerRepository)
+		defer mockRepo.AssertExpectations(t)
+
+		mockRepo.On("ClaimTask", mock.Anything, mock.Anything, []string{queue.DefaultQueueName}, mock.Anything).
+			Return(task, nil).Once()
+		mockRepo.On("ClaimTask", mock.Anything, mock.Anything, []string{queue.DefaultQueueName}, mock.Anything).
+			Return(nil, queue.ErrNoTaskToClaim).Maybe()
+
+		retryAtCh := make(chan *time.Time, 1)
+		mockRepo.On("FailTask", mock.Anything, task.ID, handlerErr.Error(), mock.Anything).
+			Run(func(args mock.Arguments) {
+				retryAtCh <- args.Get(3).(*time.Time)
+			}).Return(nil).Once()
+		mockRepo.On("MoveToDLQ", mock.Anything, task.ID).Return(nil).Maybe()
+
+		opts := append([]queue.WorkerOption{queue.WithPullInterval(20 * time.Millisecond)}, workerOpts...)
+		worker, err := queue.NewWorker(mockRepo, opts...)
+		require.NoError(t, err)
+
+		handler := queue.NewTaskHandler(func(ctx context.Context, payload testPayload) error {
+			return handlerErr
+		}, handlerOpts...)
+		require.NoError(t, worker.RegisterHandler(handler))
+		require.NoError(t, worker.Start(context.Background()))
+		defer func() { _ = worker.Stop() }()
+
+		select {
+		case retryAt := <-retryAtCh:
+			return retryAt
+		case <-time.After(2 * time.Second):
+			t.Fatal("task was not failed in time")
+			return nil
+		}
+	}
+
+	newTask := func(retryCount, maxRetries int8) *queue.Task {
+		return &queue.Task{
+			ID:          uuid.New(),
+			Queue:       queue.DefaultQueueName,
+			TaskType:    queue.TaskTypeOneTime,
+			TaskName:    "queue_test.testPayload",
+			Payload:     []byte(`{}`),
+			Status:      queue.TaskStatusProcessing,
+			Priority:    queue.PriorityMedium,
+			RetryCount:  retryCount,
+			MaxRetries:  maxRetries,
+			ScheduledAt: time.Now(),
+			CreatedAt:   time.Now(),
+		}
+	}
+
+	assertDelay := func(t *testing.T, retryAt *time.Time, expected time.Duration) {
+		t.Helper()
+		require.NotNil(t, retryAt)
+		assert.WithinDuration(t, time.Now().Add(expected), *retryAt, time.Second)
+	}
+
+	t.Run("default linear backoff", func(t *testing.T) {
+		t.Parallel()
+
+		retryAt := runFailingTask(t, newTask(1, 3), errors.New("failed"), nil)
+		assertDelay(t, retryAt, time.Minute)
+	})
+
+	t.Run("worker backoff", func(t *testing.T) {
+		t.Parallel()
+
+		retryAt := runFailingTask(t, newTask(0, 3), errors.New("failed"),
+			[]queue.WorkerOption{queue.WithWorkerBackoff(queue.FixedBackoff(time.Hour))})
+		assertDelay(t, retryAt, time.Hour)
+	})
+
+	t.Run("handler backoff overrides worker backoff", func(t *testing.T) {
+		t.Parallel()
+
+		custom := queue.BackoffFunc(func(attempt int) time.Duration {
+			return time.Duration(attempt) * 10 * time.Minute
+		})
+		retryAt := runFailingTask(t, newTask(1, 3), errors.New("failed"),
+			[]queue.WorkerOption{queue.WithWorkerBackoff(queue.FixedBackoff(time.Hour))},
+			queue.WithHandlerBackoff(custom))
+		assertDelay(t, retryAt, 20*time.Minute)
+	})
+
+	t.Run("task backoff overrides handler backoff", func(t *testing.T) {
+		t.Parallel()
+
+		task := newTask(2, 5)
+		policy := queue.ExponentialBackoff(time.Minute, 0, 0)
+		task.Backoff = &policy
+		retryAt := runFailingTask(t, task, errors.New("failed"), nil,
+			queue.WithHandlerBackoff(queue.FixedBackoff(time.Hour)))
+		assertDelay(t, retryAt, 4*time.Minute)
+	})
+
+	t.Run("retry after overrides backoff", func(t *testing.T) {
+		t.Parallel()
+
+		retryAt := runFailingTask(t, newTask(0, 3), queue.RetryAfter(errors.New("rate limited"), 5*time.Minute), nil)
+		assertDelay(t, retryAt, 5*time.Minute)
+	})
+
+	t.Run("no retry fails permanently", func(t *testing.T) {
+		t.Parallel()
+
+		retryAt := runFailingTask(t, newTask(0, 3), queue.NoRetry(errors.New("invalid payload")), nil)
+		assert.Nil(t, retryAt)
+	})
+
+	t.Run("exhausted retries fail permanently", func(t *testing.T) {
+		t.Parallel()
+
+		retryAt := runFailingTask(t, newTask(3, 3), queue.RetryAfter(errors.New("failed"), time.Minute), nil)
+		assert.Nil(t, retryAt)
+	})
+}
+
+func TestWorker_LockHeartbeat(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := queue.NewMemoryStorage()
+	t.Cleanup(func() { _ = storage.Close() })
+
+	type SlowPayload struct{}
+
+	var initial, extended time.Time
+	done := make(chan struct{})
+
+	worker, err := queue.NewWorker(storage,
+		queue.WithPullInterval(5*time.Millisecond),
+		queue.WithLockTimeout(100*time.Millisecond),
+		queue.WithHeartbeatInterval(20*time.Millisecond),
+		queue.WithTaskTimeout(time.Second),
+	)
+	require.NoError(t, err)
+	require.NoError(t, worker.RegisterHandler(queue.NewTaskHandler(func(ctx context.Context, _ SlowPayload) error {
+		defer close(done)
+
+		tasks, err := storage.ListTasks(ctx, queue.TaskFilter{Status: queue.TaskStatusProcessing})
+		if err != nil || len(tasks) != 1 {
+			return errors.New("task not processing")
+		}
+		initial = *tasks[0].LockedUntil
+
+		// Outlive the original lock deadline
+		time.Sleep(200 * time.Millisecond)
+
+		task, err := storage.GetTask(ctx, tasks[0].ID)
+		if err != nil {
+			return err
+		}
+		extended = *task.LockedUntil
+		return nil
+	})))
+	require.NoError(t, worker.Start(ctx))
+	t.Cleanup(func() { _ = worker.Stop() })
+
+	enqueuer, err := queue.NewEnqueuer(storage)
+	require.NoError(t, err)
+	mustEnqueue(t, enqueuer, SlowPayload{})
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("task was not processed")
+	}
+
+	assert.True(t, extended.After(initial.Add(100*time.Millisecond)),
+		"lock should move forward while the handler runs: initial %s, extended %s", initial, extended)
+
+	require.Eventually(t, func() bool {
+		n, err := storage.CountTasks(ctx, queue.TaskFilter{Status: queue.TaskStatusCompleted})
+		return err == nil && n == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestWorker_TaskTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	storage := queue.NewMemoryStorage()
+	t.Cleanup(func() { _ = storage.Close() })
+
+	type StuckPayload struct{}
+	type QuickPayload struct{}
+
+	quickDone := make(chan struct{})
+
+	worker, err := queue.NewWorker(storage,
+		queue.WithPullInterval(5*time.Millisecond),
+		queue.WithMaxConcurrentTasks(2),
+		queue.WithTaskTimeout(time.Second),
+	)
+	require.NoError(t, err)
+	require.NoError(t, worker.RegisterHandlers(
+		queue.NewTaskHandler(func(ctx context.Context, _ StuckPayload) error {
+			<-ctx.Done()
+			return errors.New("gave up waiting")
+		}, queue.WithHandlerTimeout(30*time.Millisecond)),
+		queue.NewTaskHandler(func(ctx context.Context, _ QuickPayload) error {
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.Greater(t, time.Until(deadline), 500*time.Millisecond)
+			close(quickDone)
+			return nil
+		}),
+	))
+	require.NoError(t, worker.Start(ctx))
+	t.Cleanup(func() { _ = worker.Stop() })
+
+	enqueuer, err := queue.NewEnqueuer(storage)
+	require.NoError(t, err)
+	mustEnqueue(t, enqueuer, StuckPayload{}, queue.WithMaxRetries(0))
+	mustEnqueue(t, enqueuer, QuickPayload{})
+
+	select {
+	case <-quickDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("quick task was not processed")
+	}
+
+	require.Eventually(t, func() bool {
+		entries, err := storage.ListDLQ(ctx, queue.DLQFilter{})
+		return err == nil && len(entries) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	entries, err := storage.ListDLQ(ctx, queue.DLQFilter{})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(entries[0].Error, queue.ErrTaskTimeout.Error()), entries[0].Error)
+	assert.Contains(t, entries[0].Error, "gave up waiting")
+}
@@ -0,0 +1,338 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowRepository persists batches and advances chains.
+// Implemented by MemoryStorage and the persistent storage backends.
+type WorkflowRepository interface {
+	// CreateBatch stores the batch together with all of its tasks atomically
+	CreateBatch(ctx context.Context, batch *Batch, tasks []*Task) error
+
+	// GetBatch returns a batch with its progress counters
+	GetBatch(ctx context.Context, batchID uuid.UUID) (*Batch, error)
+
+	// FinishBatchTask records the outcome of one batch task. The call that finishes the
+	// last task marks the batch finished and creates its callback task (see Batch.CallbackTask).
+	FinishBatchTask(ctx context.Context, batchID uuid.UUID, succeeded bool) (*Batch, error)
+
+	// CompleteTaskWithNext marks a processing task as completed and creates the next
+	// chain task in the same operation
+	CompleteTaskWithNext(ctx context.Context, taskID uuid.UUID, next *Task) error
+}
+
+// Batch tracks the progress of a group of tasks enqueued with Enqueuer.EnqueueGroup.
+// A batch finishes when every task has either completed or been moved to the dead
+// letter queue; tasks that are still retrying count as pending.
+type Batch struct {
+	ID         uuid.UUID  `json:"id"`
+	Total      int        `json:"total"`
+	Succeeded  int        `json:"succeeded"`
+	Failed     int        `json:"failed"`
+	OnComplete *Task      `json:"on_complete,omitempty"`
+	OnFailure  *Task      `json:"on_failure,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Pending returns the number of tasks that have not finished yet
+func (b *Batch) Pending() int {
+	return max(b.Total-b.Succeeded-b.Failed, 0)
+}
+
+// Finished reports whether all tasks of the batch have finished
+func (b *Batch) Finished() bool {
+	return b.FinishedAt != nil
+}
+
+// Progress returns the finished share of the batch between 0 and 1
+func (b *Batch) Progress() float64 {
+	if b.Total == 0 {
+		return 1
+	}
+	return float64(b.Succeeded+b.Failed) / float64(b.Total)
+}
+
+// CallbackTask returns the task to create when the batch finishes: OnComplete when
+// every task succeeded, OnFailure otherwise. Returns nil when no callback is configured.
+// Storage backends call it from FinishBatchTask; now becomes the task's schedule time.
+func (b *Batch) CallbackTask(now time.Time) *Task {
+	callback := b.OnComplete
+	if b.Failed > 0 {
+		callback = b.OnFailure
+	}
+	if callback == nil {
+		return nil
+	}
+
+	task := *callback
+	task.Status = TaskStatusPending
+	task.ScheduledAt = now
+	task.CreatedAt = now
+	return &task
+}
+
+// WorkflowStep describes a chain task created by the worker after the previous
+// step succeeds. Its payload is the result of the previous step.
+type WorkflowStep struct {
+	TaskName   string         `json:"task_name"`
+	Queue      string         `json:"queue"`
+	Priority   Priority       `json:"priority"`
+	MaxRetries int8           `json:"max_retries"`
+	Backoff    *BackoffPolicy `json:"backoff,omitempty"`
+}
+
+// NextTask builds the task for the first of the remaining steps with the given payload.
+func NextTask(steps []WorkflowStep, payload []byte) *Task {
+	if len(steps) == 0 {
+		return nil
+	}
+
+	step := steps[0]
+	now := time.Now()
+	task := &Task{
+		ID:          uuid.New(),
+		Queue:       step.Queue,
+		TaskType:    TaskTypeOneTime,
+		TaskName:    step.TaskName,
+		Payload:     payload,
+		Status:      TaskStatusPending,
+		Priority:    step.Priority,
+		MaxRetries:  step.MaxRetries,
+		Backoff:     step.Backoff,
+		ScheduledAt: now,
+		CreatedAt:   now,
+	}
+	if len(steps) > 1 {
+		task.Next = steps[1:]
+	}
+	return task
+}
+
+// Group collects tasks enqueued together as a batch with optional callbacks.
+//
+// Example:
+//
+//	group := queue.NewGroup()
+//	for _, item := range items {
+//		group.Add(ProcessItem{ID: item.ID})
+//	}
+//	group.OnComplete(SendSummary{BatchID: group.ID()})
+//	group.OnFailure(ReportFailure{BatchID: group.ID()}, queue.WithPriority(queue.PriorityHigh))
+//	err := enqueuer.EnqueueGroup(ctx, group)
+type Group struct {
+	id         uuid.UUID
+	items      []groupItem
+	onComplete *groupItem
+	onFailure  *groupItem
+}
+
+type groupItem struct {
+	payload any
+	opts    []EnqueueOption
+}
+
+// NewGroup creates an empty group with a new batch ID
+func NewGroup() *Group {
+	return &Group{id: uuid.New()}
+}
+
+// ID returns the batch ID, known before enqueueing so callbacks can reference it
+func (g *Group) ID() uuid.UUID {
+	return g.id
+}
+
+// Add appends a task to the group
+func (g *Group) Add(payload any, opts ...EnqueueOption) *Group {
+	g.items = append(g.items, groupItem{payload: payload, opts: opts})
+	return g
+}
+
+// OnComplete sets the task created when all tasks of the group succeed
+func (g *Group) OnComplete(payload any, opts ...EnqueueOption) *Group {
+	g.onComplete = &groupItem{payload: payload, opts: opts}
+	return g
+}
+
+// OnFailure sets the task created when all tasks of the group finished
+// and at least one of them was moved to the dead letter queue
+func (g *Group) OnFailure(payload any, opts ...EnqueueOption) *Group {
+	g.onFailure = &groupItem{payload: payload, opts: opts}
+	return g
+}
+
+// Chain runs tasks sequentially: each step starts after the previous one succeeded
+// and receives its result as payload. A failed step stops the chain.
+//
+// Example:
+//
+//	chain := queue.NewChain(FetchReport{ID: id}).
+//		Then(queue.Step[RenderReport]()).
+//		Then(queue.Step[EmailReport](queue.WithQueue("emails")))
+//	err := enqueuer.EnqueueChain(ctx, chain)
+//
+// Steps producing a payload for the next step use NewTaskResultHandler.
+type Chain struct {
+	first chainItem
+	steps []chainItem
+}
+
+type chainItem struct {
+	payload  any
+	taskName string
+	opts     []EnqueueOption
+}
+
+// ChainStep identifies a chain step by the handler it runs. Create it with Step.
+type ChainStep struct {
+	taskName string
+	opts     []EnqueueOption
+}
+
+// Step creates a chain step processed by the handler for payload type T.
+// Queue, priority, max retries, backoff and task name options apply to the step.
+func Step[T any](opts ...EnqueueOption) ChainStep {
+	var payload T
+	return ChainStep{taskName: qualifiedStructName(payload), opts: opts}
+}
+
+// NewChain starts a chain with the payload of its first task
+func NewChain(payload any, opts ...EnqueueOption) *Chain {
+	return &Chain{first: chainItem{payload: payload, opts: opts}}
+}
+
+// Then appends a step to the chain
+func (c *Chain) Then(step ChainStep) *Chain {
+	c.steps = append(c.steps, chainItem{taskName: step.taskName, opts: step.opts})
+	return c
+}
+
+// EnqueueGroup creates all tasks of the group and the batch tracking them atomically.
+// Requires a repository implementing WorkflowRepository.
+func (e *Enqueuer) EnqueueGroup(ctx context.Context, group *Group) error {
+	if group == nil || len(group.items) == 0 {
+		return ErrNoItemsToEnqueue
+	}
+
+	repo, ok := e.repo.(WorkflowRepository)
+	if !ok {
+		return ErrWorkflowNotSupported
+	}
+
+	batch := &Batch{
+		ID:        group.id,
+		Total:     len(group.items),
+		CreatedAt: time.Now(),
+	}
+
+	tasks := make([]*Task, 0, len(group.items))
+	for _, item := range group.items {
+		task, err := e.buildGroupTask(ctx, item)
+		if err != nil {
+			return err
+		}
+		task.BatchID = &batch.ID
+		tasks = append(tasks, task)
+	}
+
+	var err error
+	if group.onComplete != nil {
+		if batch.OnComplete, err = e.buildGroupTask(ctx, *group.onComplete); err != nil {
+			return err
+		}
+	}
+	if group.onFailure != nil {
+		if batch.OnFailure, err = e.buildGroupTask(ctx, *group.onFailure); err != nil {
+			return err
+		}
+	}
+
+	if err := repo.CreateBatch(ctx, batch, tasks); err != nil {
+		return fmt.Errorf("failed to create batch %s: %w", batch.ID, err)
+	}
+	for _, task := range tasks {
+		e.metrics.TaskEnqueued(task.Queue, task.TaskName)
+	}
+
+	return nil
+}
+
+// EnqueueChain creates the first task of the chain and returns its ID; the worker creates
+// each following step when the previous one succeeds. Requires a repository implementing
+// WorkflowRepository.
+func (e *Enqueuer) EnqueueChain(ctx context.Context, chain *Chain) (uuid.UUID, error) {
+	if chain == nil {
+		return uuid.Nil, ErrNoItemsToEnqueue
+	}
+	if _, ok := e.repo.(WorkflowRepository); !ok {
+		return uuid.Nil, ErrWorkflowNotSupported
+	}
+
+	steps := make([]WorkflowStep, 0, len(chain.steps))
+	for _, item := range chain.steps {
+		options, err := e.enqueueOptions(item.opts)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		taskName := item.taskName
+		if options.taskName != "" {
+			taskName = options.taskName
+		}
+		steps = append(steps, WorkflowStep{
+			TaskName:   taskName,
+			Queue:      options.queue,
+			Priority:   options.priority,
+			MaxRetries: options.maxRetries,
+			Backoff:    options.backoff,
+		})
+	}
+
+	return e.Enqueue(ctx, chain.first.payload, append(chain.first.opts, withNextSteps(steps))...)
+}
+
+// GetBatch returns the progress of a batch created with EnqueueGroup
+func (e *Enqueuer) GetBatch(ctx context.Context, batchID uuid.UUID) (*Batch, error) {
+	repo, ok := e.repo.(WorkflowRepository)
+	if !ok {
+		return nil, ErrWorkflowNotSupported
+	}
+	return repo.GetBatch(ctx, batchID)
+}
+
+// buildGroupTask builds a task of a group using the enqueuer defaults
+func (e *Enqueuer) buildGroupTask(ctx context.Context, item groupItem) (*Task, error) {
+	if item.payload == nil {
+		return nil, ErrPayloadNil
+	}
+	options, err := e.enqueueOptions(item.opts)
+	if err != nil {
+		return nil, err
+	}
+	if options.uniqueKey != "" {
+		return nil, errors.New("unique keys are not supported for batch tasks")
+	}
+
+	task, err := e.buildTask(item.payload, options)
+	if err != nil {
+		return nil, err
+	}
+	if err := e.encryptPayload(ctx, task, options.workspace); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// withNextSteps attaches the remaining chain steps to a task
+func withNextSteps(steps []WorkflowStep) EnqueueOption {
+	return func(o *enqueueOptions) {
+		if len(steps) > 0 {
+			o.next = steps
+		}
+	}
+}
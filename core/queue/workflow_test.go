@@ -0,0 +1,340 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+type workflowItem struct {
+	N int `json:"n"`
+}
+
+type workflowSummary struct {
+	BatchID uuid.UUID `json:"batch_id"`
+}
+
+type workflowFailure struct {
+	BatchID uuid.UUID `json:"batch_id"`
+}
+
+type workflowDouble struct {
+	N int `json:"n"`
+}
+
+type workflowFormat struct {
+	N int `json:"n"`
+}
+
+func startWorkflowWorker(t *testing.T, storage *queue.MemoryStorage, handlers ...queue.Handler) {
+	t.Helper()
+
+	worker, err := queue.NewWorker(storage,
+		queue.WithPullInterval(5*time.Millisecond),
+		queue.WithMaxConcurrentTasks(4),
+		queue.WithWorkerBackoff(queue.FixedBackoff(0)),
+	)
+	require.NoError(t, err)
+	require.NoError(t, worker.RegisterHandlers(handlers...))
+	require.NoError(t, worker.Start(context.Background()))
+	t.Cleanup(func() { _ = worker.Stop() })
+}
+
+func TestEnqueuer_EnqueueGroup(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("runs on complete callback after all tasks succeed", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+
+		var mu sync.Mutex
+		var processed int
+		summary := make(chan uuid.UUID, 1)
+
+		startWorkflowWorker(t, storage,
+			queue.NewTaskHandler(func(ctx context.Context, item workflowItem) error {
+				mu.Lock()
+				processed++
+				mu.Unlock()
+				return nil
+			}),
+			queue.NewTaskHandler(func(ctx context.Context, s workflowSummary) error {
+				summary <- s.BatchID
+				return nil
+			}),
+		)
+
+		group := queue.NewGroup()
+		for i := range 5 {
+			group.Add(workflowItem{N: i})
+		}
+		group.OnComplete(workflowSummary{BatchID: group.ID()})
+		group.OnFailure(workflowFailure{BatchID: group.ID()})
+
+		require.NoError(t, enqueuer.EnqueueGroup(ctx, group))
+
+		select {
+		case id := <-summary:
+			assert.Equal(t, group.ID(), id)
+		case <-time.After(5 * time.Second):
+			t.Fatal("on complete callback did not run")
+		}
+
+		mu.Lock()
+		assert.Equal(t, 5, processed)
+		mu.Unlock()
+
+		batch, err := enqueuer.GetBatch(ctx, group.ID())
+		require.NoError(t, err)
+		assert.Equal(t, 5, batch.Succeeded)
+		assert.Zero(t, batch.Failed)
+		assert.Zero(t, batch.Pending())
+		assert.True(t, batch.Finished())
+		assert.InDelta(t, 1.0, batch.Progress(), 0.0001)
+	})
+
+	t.Run("runs on failure callback when a task is dead lettered", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+
+		failure := make(chan uuid.UUID, 1)
+
+		startWorkflowWorker(t, storage,
+			queue.NewTaskHandler(func(ctx context.Context, item workflowItem) error {
+				if item.N == 1 {
+					return queue.NoRetry(errors.New("bad item"))
+				}
+				return nil
+			}),
+			queue.NewTaskHandler(func(ctx context.Context, s workflowSummary) error {
+				t.Error("on complete callback must not run")
+				return nil
+			}),
+			queue.NewTaskHandler(func(ctx context.Context, f workflowFailure) error {
+				failure <- f.BatchID
+				return nil
+			}),
+		)
+
+		group := queue.NewGroup().
+			Add(workflowItem{N: 0}).
+			Add(workflowItem{N: 1}).
+			Add(workflowItem{N: 2})
+		group.OnComplete(workflowSummary{BatchID: group.ID()})
+		group.OnFailure(workflowFailure{BatchID: group.ID()})
+
+		require.NoError(t, enqueuer.EnqueueGroup(ctx, group))
+
+		select {
+		case id := <-failure:
+			assert.Equal(t, group.ID(), id)
+		case <-time.After(5 * time.Second):
+			t.Fatal("on failure callback did not run")
+		}
+
+		batch, err := enqueuer.GetBatch(ctx, group.ID())
+		require.NoError(t, err)
+		assert.Equal(t, 2, batch.Succeeded)
+		assert.Equal(t, 1, batch.Failed)
+	})
+
+	t.Run("empty group", func(t *testing.T) {
+		t.Parallel()
+
+		enqueuer, err := queue.NewEnqueuer(queue.NewMemoryStorage())
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, enqueuer.EnqueueGroup(ctx, queue.NewGroup()), queue.ErrNoItemsToEnqueue)
+	})
+
+	t.Run("repository without workflow support", func(t *testing.T) {
+		t.Parallel()
+
+		enqueuer, err := queue.NewEnqueuer(&mockEnqueuerRepo{})
+		require.NoError(t, err)
+
+		err = enqueuer.EnqueueGroup(ctx, queue.NewGroup().Add(workflowItem{}))
+		assert.ErrorIs(t, err, queue.ErrWorkflowNotSupported)
+
+		_, err = enqueuer.EnqueueChain(ctx, queue.NewChain(workflowItem{}))
+		assert.ErrorIs(t, err, queue.ErrWorkflowNotSupported)
+
+		_, err = enqueuer.GetBatch(ctx, uuid.New())
+		assert.ErrorIs(t, err, queue.ErrWorkflowNotSupported)
+	})
+
+	t.Run("unknown batch", func(t *testing.T) {
+		t.Parallel()
+
+		enqueuer, err := queue.NewEnqueuer(queue.NewMemoryStorage())
+		require.NoError(t, err)
+
+		_, err = enqueuer.GetBatch(ctx, uuid.New())
+		assert.ErrorIs(t, err, queue.ErrBatchNotFound)
+	})
+}
+
+func TestEnqueuer_EnqueueChain(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("feeds each step result into the next step", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+
+		done := make(chan int, 1)
+
+		startWorkflowWorker(t, storage,
+			queue.NewTaskResultHandler(func(ctx context.Context, item workflowItem) (workflowDouble, error) {
+				return workflowDouble{N: item.N + 1}, nil
+			}),
+			queue.NewTaskResultHandler(func(ctx context.Context, d workflowDouble) (workflowFormat, error) {
+				return workflowFormat{N: d.N * 2}, nil
+			}),
+			queue.NewTaskHandler(func(ctx context.Context, f workflowFormat) error {
+				done <- f.N
+				return nil
+			}),
+		)
+
+		chain := queue.NewChain(workflowItem{N: 20}).
+			Then(queue.Step[workflowDouble]()).
+			Then(queue.Step[workflowFormat](queue.WithPriority(queue.PriorityHigh)))
+
+		_, err = enqueuer.EnqueueChain(ctx, chain)
+		require.NoError(t, err)
+
+		select {
+		case n := <-done:
+			assert.Equal(t, 42, n)
+		case <-time.After(5 * time.Second):
+			t.Fatal("chain did not finish")
+		}
+	})
+
+	t.Run("failed step stops the chain", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+
+		startWorkflowWorker(t, storage,
+			queue.NewTaskResultHandler(func(ctx context.Context, item workflowItem) (workflowDouble, error) {
+				return workflowDouble{}, queue.NoRetry(errors.New("step failed"))
+			}),
+			queue.NewTaskHandler(func(ctx context.Context, d workflowDouble) error {
+				t.Error("next step must not run")
+				return nil
+			}),
+		)
+
+		_, err = enqueuer.EnqueueChain(ctx, queue.NewChain(workflowItem{N: 1}).Then(queue.Step[workflowDouble]()))
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			entries, err := storage.ListDLQ(ctx, queue.DLQFilter{})
+			return err == nil && len(entries) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		count, err := storage.CountTasks(ctx, queue.TaskFilter{})
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("requeued failed step resumes the chain", func(t *testing.T) {
+		t.Parallel()
+
+		storage := queue.NewMemoryStorage()
+		t.Cleanup(func() { _ = storage.Close() })
+		enqueuer, err := queue.NewEnqueuer(storage)
+		require.NoError(t, err)
+
+		var attempts atomic.Int32
+		done := make(chan int, 1)
+
+		startWorkflowWorker(t, storage,
+			queue.NewTaskResultHandler(func(ctx context.Context, item workflowItem) (workflowDouble, error) {
+				if attempts.Add(1) == 1 {
+					return workflowDouble{}, queue.NoRetry(errors.New("step failed"))
+				}
+				return workflowDouble{N: item.N * 2}, nil
+			}),
+			queue.NewTaskHandler(func(ctx context.Context, d workflowDouble) error {
+				done <- d.N
+				return nil
+			}),
+		)
+
+		backoff := queue.FixedBackoff(time.Second)
+		chain := queue.NewChain(workflowItem{N: 21}, queue.WithBackoff(backoff)).Then(queue.Step[workflowDouble]())
+		_, err = enqueuer.EnqueueChain(ctx, chain)
+		require.NoError(t, err)
+
+		var entries []*queue.TasksDlq
+		require.Eventually(t, func() bool {
+			entries, err = storage.ListDLQ(ctx, queue.DLQFilter{})
+			return err == nil && len(entries) == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		require.Len(t, entries[0].Next, 1)
+		assert.Equal(t, &backoff, entries[0].Backoff)
+
+		requeued, err := storage.RequeueDLQ(ctx, entries[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, entries[0].Next, requeued.Next)
+		assert.Equal(t, &backoff, requeued.Backoff)
+
+		select {
+		case n := <-done:
+			assert.Equal(t, 42, n)
+		case <-time.After(5 * time.Second):
+			t.Fatal("chain did not resume")
+		}
+	})
+}
+
+func TestBatch_CallbackTask(t *testing.T) {
+	t.Parallel()
+
+	onComplete := &queue.Task{ID: uuid.New(), TaskName: "complete"}
+	onFailure := &queue.Task{ID: uuid.New(), TaskName: "failure"}
+	now := time.Now()
+
+	batch := &queue.Batch{Total: 2, Succeeded: 2, OnComplete: onComplete, OnFailure: onFailure}
+	callback := batch.CallbackTask(now)
+	require.NotNil(t, callback)
+	assert.Equal(t, onComplete.ID, callback.ID)
+	assert.Equal(t, queue.TaskStatusPending, callback.Status)
+	assert.Equal(t, now, callback.ScheduledAt)
+
+	batch = &queue.Batch{Total: 2, Succeeded: 1, Failed: 1, OnComplete: onComplete, OnFailure: onFailure}
+	assert.Equal(t, onFailure.ID, batch.CallbackTask(now).ID)
+
+	batch = &queue.Batch{Total: 1, Failed: 1, OnComplete: onComplete}
+	assert.Nil(t, batch.CallbackTask(now))
+}
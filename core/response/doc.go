@@ -1,6 +1,6 @@
 // Package response provides HTTP response utilities for web applications.
 // It offers a consistent API for generating various types of HTTP responses
-// including JSON, HTML templates, files, redirects, streaming responses, WebSockets,
+// including JSON, XML, HTML templates, files, redirects, streaming responses, WebSockets,
 // Server-Sent Events, and HTMX-enhanced responses.
 //
 // # Basic Usage
@@ -31,6 +31,10 @@
 //	// JSON with custom status code
 //	response.JSONWithStatus(user, http.StatusCreated)
 //
+//	// XML for clients that expect application/xml
+//	response.XML(invoice)
+//	response.XMLWithStatus(invoice, http.StatusCreated)
+//
 // # Basic Response Types
 //
 // Create simple text and HTML responses:
@@ -94,6 +98,18 @@
 //	// CSV with headers
 //	response.CSVWithHeaders([]string{"Name", "Age"}, userRows, "users.csv")
 //
+// # Content Negotiation
+//
+// Serve the same resource in several formats based on the Accept header.
+// Negotiate honors q-values and wildcards, sets Vary: Accept, and returns
+// ErrNotAcceptable (406) when no offer is acceptable:
+//
+//	return response.Negotiate(map[string]handler.Response{
+//		"application/json": response.JSON(users),
+//		"text/html":        response.Templ(views.Users(users)),
+//		"text/csv":         response.CSVWithHeaders(headers, rows, "users.csv"),
+//	})
+//
 // # Redirects
 //
 // Handle HTTP redirections (with automatic HTMX support):
@@ -186,6 +202,9 @@
 //	}()
 //	response.StreamJSON(items)
 //
+//	// Newline-delimited JSON from a slice or iterator
+//	response.NDJSON(slices.Values(users))
+//
 // # HTMX Support
 //
 // Enhanced responses for HTMX applications:
@@ -273,8 +292,24 @@
 //	response.Error(httpErr)
 //
 //	// Use error handlers for consistent error processing
-//	response.ErrorHandler(ctx, err)     // Plain text error response
-//	response.JSONErrorHandler(ctx, err) // JSON error response
+//	response.ErrorHandler(ctx, err)        // Plain text error response
+//	response.JSONErrorHandler(ctx, err)    // JSON error response
+//	response.ProblemErrorHandler(ctx, err) // RFC 9457 application/problem+json response
+//
+// # Problem Details
+//
+// ProblemErrorHandler renders errors as RFC 9457 problem details. HTTPError maps
+// to the type, title, status, detail and instance members, its code and details
+// become extension members, and validation errors are listed in the "errors"
+// extension with their field paths. Register type URIs for error values with
+// NewProblemErrorHandler:
+//
+//	r := router.New[*router.Context](
+//		router.WithErrorHandler(response.NewProblemErrorHandler[*router.Context](
+//			response.WithProblemType(billing.ErrInsufficientFunds, "https://example.com/problems/insufficient-funds"),
+//			response.WithProblemType(response.ErrConflict, "https://example.com/problems/conflict"),
+//		)),
+//	)
 //
 // # Rendering Responses
 //
@@ -0,0 +1,160 @@
+package response
+
+import (
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+)
+
+// Negotiate selects the response for the media type the client prefers according
+// to the Accept header, honoring q-values and wildcards. Offers are keyed by media
+// type, e.g. "application/json" or "text/html". The Vary: Accept header is always set.
+//
+// When several offers are equally acceptable, the one matching the most specific
+// Accept range wins, then the one listed first in Accept, then the media type that
+// sorts first; a request without Accept accepts every offer. When no offer is
+// acceptable, ErrNotAcceptable is returned to the error handler with the available
+// media types in its details.
+//
+// Example:
+//
+//	return response.Negotiate(map[string]handler.Response{
+//		"application/json": response.JSON(users),
+//		"text/html":        response.Templ(views.Users(users)),
+//		"text/csv":         response.CSV(rows, "users.csv"),
+//	})
+func Negotiate(offers map[string]handler.Response) handler.Response {
+	available := make([]offer, 0, len(offers))
+	for mediaType, resp := range offers {
+		if resp == nil {
+			continue
+		}
+		available = append(available, offer{mediaType: baseMediaType(mediaType), response: resp})
+	}
+	sort.Slice(available, func(i, j int) bool {
+		return available[i].mediaType < available[j].mediaType
+	})
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Add("Vary", "Accept")
+
+		if best, ok := negotiate(r.Header.Values("Accept"), available); ok {
+			return best.response(w, r)
+		}
+
+		types := make([]string, len(available))
+		for i, o := range available {
+			types[i] = o.mediaType
+		}
+		return ErrNotAcceptable.WithDetails(map[string]any{"available": types})
+	}
+}
+
+// offer is a response available for a media type.
+type offer struct {
+	mediaType string
+	response  handler.Response
+}
+
+// acceptRange is a media range of the Accept header.
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+	index        int
+}
+
+// negotiate returns the best offer for the Accept header values.
+func negotiate(accept []string, offers []offer) (offer, bool) {
+	ranges := parseAccept(accept)
+	if len(ranges) == 0 {
+		// No Accept header means any media type is acceptable
+		ranges = []acceptRange{{typ: "*", subtype: "*", q: 1}}
+	}
+
+	var (
+		best                       offer
+		bestQ                      float64
+		bestSpecificity, bestIndex int
+		found                      bool
+	)
+	for _, o := range offers {
+		q, specificity, index, ok := matchAccept(ranges, o.mediaType)
+		if !ok || q == 0 {
+			continue
+		}
+		better := !found || q > bestQ ||
+			(q == bestQ && specificity > bestSpecificity) ||
+			(q == bestQ && specificity == bestSpecificity && index < bestIndex)
+		if better {
+			best, bestQ, bestSpecificity, bestIndex, found = o, q, specificity, index, true
+		}
+	}
+	return best, found
+}
+
+// matchAccept returns the quality of a media type given by the most specific
+// matching range, the specificity of that range and its position in Accept.
+func matchAccept(ranges []acceptRange, mediaType string) (q float64, specificity, index int, ok bool) {
+	typ, subtype, _ := strings.Cut(mediaType, "/")
+	specificity = -1
+	for _, ar := range ranges {
+		var s int
+		switch {
+		case ar.typ == typ && ar.subtype == subtype:
+			s = 2
+		case ar.typ == typ && ar.subtype == "*":
+			s = 1
+		case ar.typ == "*" && ar.subtype == "*":
+			s = 0
+		default:
+			continue
+		}
+		if s > specificity {
+			q, specificity, index, ok = ar.q, s, ar.index, true
+		}
+	}
+	return q, specificity, index, ok
+}
+
+// parseAccept parses Accept header values into media ranges, skipping malformed ones.
+func parseAccept(values []string) []acceptRange {
+	var ranges []acceptRange
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			mediaType, params, err := mime.ParseMediaType(part)
+			if err != nil {
+				continue
+			}
+			typ, subtype, ok := strings.Cut(mediaType, "/")
+			if !ok || (typ == "*" && subtype != "*") {
+				continue
+			}
+
+			q := 1.0
+			if v, exists := params["q"]; exists {
+				q, err = strconv.ParseFloat(v, 64)
+				if err != nil || q < 0 || q > 1 {
+					continue
+				}
+			}
+			ranges = append(ranges, acceptRange{typ: typ, subtype: subtype, q: q, index: len(ranges)})
+		}
+	}
+	return ranges
+}
+
+// baseMediaType returns the lowercased media type without parameters.
+func baseMediaType(mediaType string) string {
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		return parsed
+	}
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
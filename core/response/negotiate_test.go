@@ -0,0 +1,76 @@
+package response_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/response"
+)
+
+func TestNegotiate(t *testing.T) {
+	t.Parallel()
+
+	offers := map[string]handler.Response{
+		"application/json":         response.JSON(map[string]string{"name": "Alice"}),
+		"text/html; charset=utf-8": response.HTML("<p>Alice</p>"),
+		"text/csv":                 response.Bytes([]byte("name\nAlice\n"), "text/csv"),
+	}
+
+	tests := []struct {
+		name        string
+		accept      []string
+		contentType string
+	}{
+		{"no accept header", nil, "application/json; charset=utf-8"},
+		{"exact match", []string{"text/csv"}, "text/csv"},
+		{"browser", []string{"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"}, "text/html; charset=utf-8"},
+		{"q-values", []string{"application/json;q=0.5, text/csv;q=0.9"}, "text/csv"},
+		{"type wildcard", []string{"text/*"}, "text/csv"},
+		{"accept order breaks ties", []string{"text/html, application/json"}, "text/html; charset=utf-8"},
+		{"specific range overrides wildcard", []string{"*/*;q=0.1, application/json;q=0"}, "text/csv"},
+		{"multiple header values", []string{"image/png", "text/html;q=0.4"}, "text/html; charset=utf-8"},
+		{"case insensitive", []string{"Application/JSON"}, "application/json; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+			for _, v := range tt.accept {
+				req.Header.Add("Accept", v)
+			}
+			w := httptest.NewRecorder()
+
+			require.NoError(t, response.Negotiate(offers)(w, req))
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, "Accept", w.Header().Get("Vary"))
+		})
+	}
+
+	t.Run("not acceptable", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		req.Header.Set("Accept", "application/xml, text/*;q=0")
+		w := httptest.NewRecorder()
+
+		err := response.Negotiate(offers)(w, req)
+		var httpErr response.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusNotAcceptable, httpErr.Status)
+		assert.Equal(t, []string{"application/json", "text/csv", "text/html"}, httpErr.Details["available"])
+		assert.Equal(t, "Accept", w.Header().Get("Vary"))
+
+		w = httptest.NewRecorder()
+		response.JSONErrorHandler(&testContext{w: w, r: req}, err)
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+		assert.Contains(t, w.Body.String(), `"code":"not_acceptable"`)
+	})
+}
@@ -0,0 +1,171 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/validator"
+)
+
+// ProblemContentType is the media type of RFC 9457 problem details.
+const ProblemContentType = "application/problem+json"
+
+// ProblemFieldError is an entry of the "errors" extension of a problem,
+// describing why a request field is invalid.
+type ProblemFieldError struct {
+	Field  string `json:"field"`
+	Detail string `json:"detail"`
+}
+
+// ProblemOption configures a problem details error handler.
+type ProblemOption func(*problemConfig)
+
+type problemConfig struct {
+	types []problemType
+}
+
+// problemType is a type URI registered for an error value.
+type problemType struct {
+	target error
+	uri    string
+}
+
+// WithProblemType sets the type URI of problems for errors matching target.
+// HTTPError targets match errors with the same status and code, e.g.
+// ErrPaymentRequired, and other targets match with errors.Is. The first
+// matching registration wins; unmatched problems have the type "about:blank".
+func WithProblemType(target error, typeURI string) ProblemOption {
+	return func(c *problemConfig) {
+		if target != nil && typeURI != "" {
+			c.types = append(c.types, problemType{target: target, uri: typeURI})
+		}
+	}
+}
+
+// ProblemErrorHandler returns errors as RFC 9457 problem details with the
+// application/problem+json content type. See NewProblemErrorHandler.
+func ProblemErrorHandler[C handler.Context](ctx C, err error) {
+	renderProblem(ctx, err, problemConfig{})
+}
+
+// NewProblemErrorHandler creates an error handler returning errors as RFC 9457
+// problem details. HTTPError maps to the type, title, status, detail and instance
+// members: the title is the status text, the detail is the message when it differs
+// from the title, and the instance is the request path. The error code and details
+// are added as extension members. Field messages of validator.ValidationErrors and
+// the "fields" details of binder.Typed become the "errors" extension:
+//
+//	{
+//		"type": "about:blank",
+//		"title": "Unprocessable Entity",
+//		"status": 422,
+//		"instance": "/users",
+//		"code": "unprocessable_entity",
+//		"errors": [{"field": "email", "detail": "must be a valid email address"}]
+//	}
+func NewProblemErrorHandler[C handler.Context](opts ...ProblemOption) func(ctx C, err error) {
+	var cfg problemConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return func(ctx C, err error) {
+		renderProblem(ctx, err, cfg)
+	}
+}
+
+// renderProblem writes the problem details of err.
+func renderProblem(ctx handler.Context, err error, cfg problemConfig) {
+	var httpErr HTTPError
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &httpErr) && errors.As(err, &validationErrs) {
+		// Validation errors returned as is are client errors
+		httpErr = ErrUnprocessableEntity
+	} else {
+		httpErr = convertToHTTPError(err)
+	}
+
+	problem := make(map[string]any, len(httpErr.Details)+7)
+	for k, v := range httpErr.Details {
+		problem[k] = v
+	}
+
+	title := http.StatusText(httpErr.Status)
+	if title == "" {
+		title = httpErr.Message
+	}
+	problem["type"] = cfg.typeURI(err, httpErr)
+	problem["title"] = title
+	problem["status"] = httpErr.Status
+	if httpErr.Message != "" && httpErr.Message != title {
+		problem["detail"] = httpErr.Message
+	} else {
+		delete(problem, "detail")
+	}
+	if r := ctx.Request(); r != nil && r.URL != nil {
+		problem["instance"] = r.URL.Path
+	} else {
+		delete(problem, "instance")
+	}
+	if httpErr.Code != "" {
+		problem["code"] = httpErr.Code
+	}
+
+	if fieldErrs := problemFieldErrors(validationErrs, httpErr.Details); len(fieldErrs) > 0 {
+		problem["errors"] = fieldErrs
+		delete(problem, "fields")
+	}
+
+	Render(ctx, func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(httpErr.Status)
+		return json.NewEncoder(w).Encode(problem)
+	})
+}
+
+// typeURI returns the registered type URI of the error.
+func (c problemConfig) typeURI(err error, httpErr HTTPError) string {
+	for _, pt := range c.types {
+		var target HTTPError
+		if errors.As(pt.target, &target) {
+			if target.Status == httpErr.Status && target.Code == httpErr.Code {
+				return pt.uri
+			}
+			continue
+		}
+		if errors.Is(err, pt.target) {
+			return pt.uri
+		}
+	}
+	return "about:blank"
+}
+
+// problemFieldErrors collects field errors from validation errors, or from the
+// "fields" details (field path to messages) of HTTPError.
+func problemFieldErrors(validationErrs validator.ValidationErrors, details map[string]any) []ProblemFieldError {
+	var fieldErrs []ProblemFieldError
+	for _, e := range validationErrs {
+		fieldErrs = append(fieldErrs, ProblemFieldError{Field: e.Field, Detail: e.Message})
+	}
+	if len(fieldErrs) > 0 {
+		return fieldErrs
+	}
+
+	fields, ok := details["fields"].(map[string][]string)
+	if !ok {
+		return nil
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, msg := range fields[name] {
+			fieldErrs = append(fieldErrs, ProblemFieldError{Field: name, Detail: msg})
+		}
+	}
+	return fieldErrs
+}
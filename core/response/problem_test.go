@@ -0,0 +1,158 @@
+package response_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/response"
+	"github.com/dmitrymomot/foundation/core/validator"
+)
+
+var errInsufficientFunds = errors.New("insufficient funds")
+
+type paymentError struct{ err error }
+
+func (e paymentError) Error() string   { return e.err.Error() }
+func (e paymentError) Unwrap() error   { return e.err }
+func (e paymentError) StatusCode() int { return http.StatusPaymentRequired }
+
+func TestProblemErrorHandler(t *testing.T) {
+	t.Parallel()
+
+	handle := response.NewProblemErrorHandler[*testContext](
+		response.WithProblemType(errInsufficientFunds, "https://example.com/problems/insufficient-funds"),
+		response.WithProblemType(response.ErrConflict, "https://example.com/problems/conflict"),
+	)
+
+	tests := []struct {
+		name     string
+		handler  func(*testContext, error)
+		err      error
+		status   int
+		expected map[string]any
+	}{
+		{
+			name:    "HTTPError with message",
+			handler: response.ProblemErrorHandler[*testContext],
+			err:     response.ErrNotFound.WithMessage("user 42 does not exist"),
+			status:  http.StatusNotFound,
+			expected: map[string]any{
+				"type":     "about:blank",
+				"title":    "Not Found",
+				"status":   float64(http.StatusNotFound),
+				"detail":   "user 42 does not exist",
+				"instance": "/users/42",
+				"code":     "not_found",
+			},
+		},
+		{
+			name:    "details become extensions",
+			handler: response.ProblemErrorHandler[*testContext],
+			err:     response.ErrTooManyRequests.WithDetails(map[string]any{"retry_after": 30, "status": "ignored"}),
+			status:  http.StatusTooManyRequests,
+			expected: map[string]any{
+				"type":        "about:blank",
+				"title":       "Too Many Requests",
+				"status":      float64(http.StatusTooManyRequests),
+				"instance":    "/users/42",
+				"code":        "too_many_requests",
+				"retry_after": float64(30),
+			},
+		},
+		{
+			name:    "registered error value",
+			handler: handle,
+			err:     paymentError{err: fmt.Errorf("charge: %w", errInsufficientFunds)},
+			status:  http.StatusPaymentRequired,
+			expected: map[string]any{
+				"type":     "https://example.com/problems/insufficient-funds",
+				"title":    "Payment Required",
+				"status":   float64(http.StatusPaymentRequired),
+				"instance": "/users/42",
+				"code":     "payment_required",
+				"cause":    "charge: insufficient funds",
+			},
+		},
+		{
+			name:    "registered HTTPError",
+			handler: handle,
+			err:     response.ErrConflict.WithMessage("email already taken"),
+			status:  http.StatusConflict,
+			expected: map[string]any{
+				"type":     "https://example.com/problems/conflict",
+				"title":    "Conflict",
+				"status":   float64(http.StatusConflict),
+				"detail":   "email already taken",
+				"instance": "/users/42",
+				"code":     "conflict",
+			},
+		},
+		{
+			name:    "validation errors",
+			handler: handle,
+			err: validator.ValidationErrors{
+				{Field: "Email", Message: "must be a valid email address"},
+				{Field: "Address.City", Message: "is required"},
+			},
+			status: http.StatusUnprocessableEntity,
+			expected: map[string]any{
+				"type":     "about:blank",
+				"title":    "Unprocessable Entity",
+				"status":   float64(http.StatusUnprocessableEntity),
+				"instance": "/users/42",
+				"code":     "unprocessable_entity",
+				"errors": []any{
+					map[string]any{"field": "Email", "detail": "must be a valid email address"},
+					map[string]any{"field": "Address.City", "detail": "is required"},
+				},
+			},
+		},
+		{
+			name:    "field details",
+			handler: response.ProblemErrorHandler[*testContext],
+			err: response.ErrUnprocessableEntity.WithDetails(map[string]any{
+				"fields": map[string][]string{
+					"name":         {"is required"},
+					"address.city": {"is required", "is too short"},
+				},
+			}),
+			status: http.StatusUnprocessableEntity,
+			expected: map[string]any{
+				"type":     "about:blank",
+				"title":    "Unprocessable Entity",
+				"status":   float64(http.StatusUnprocessableEntity),
+				"instance": "/users/42",
+				"code":     "unprocessable_entity",
+				"errors": []any{
+					map[string]any{"field": "address.city", "detail": "is required"},
+					map[string]any{"field": "address.city", "detail": "is too short"},
+					map[string]any{"field": "name", "detail": "is required"},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := httptest.NewRequest(http.MethodPost, "/users/42?notify=true", nil)
+			w := httptest.NewRecorder()
+			tt.handler(&testContext{w: w, r: req}, tt.err)
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, response.ProblemContentType, w.Header().Get("Content-Type"))
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expected, body)
+		})
+	}
+}
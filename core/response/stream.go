@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"iter"
 	"net/http"
 
 	"github.com/dmitrymomot/foundation/core/handler"
@@ -134,3 +135,53 @@ func StreamJSON(items <-chan any, opts ...StreamOption) handler.Response {
 		}
 	}
 }
+
+// NDJSON creates a newline-delimited JSON response with 200 OK status, writing each
+// item of the sequence as a separate line. Items are encoded as they are produced
+// and flushed when the writer supports it, so large result sets and database cursors
+// can be sent without buffering. Encoding stops when the client disconnects.
+//
+// The response uses Content-Type: application/x-ndjson
+//
+// Example:
+//
+//	return response.NDJSON(slices.Values(users))
+//
+//	return response.NDJSON(func(yield func(Order) bool) {
+//	    for rows.Next() {
+//	        var o Order
+//	        if rows.Scan(&o.ID, &o.Total) != nil || !yield(o) {
+//	            return
+//	        }
+//	    }
+//	})
+func NDJSON[T any](items iter.Seq[T]) handler.Response {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+
+		if items == nil {
+			return nil
+		}
+
+		flusher, _ := w.(http.Flusher)
+		encoder := json.NewEncoder(w)
+		ctx := r.Context()
+
+		var err error
+		for item := range items {
+			if ctx.Err() != nil {
+				break
+			}
+			if err = encoder.Encode(item); err != nil {
+				err = fmt.Errorf("failed to encode item: %w", err)
+				break
+			}
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+		return err
+	}
+}
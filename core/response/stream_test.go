@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -751,3 +752,60 @@ func TestStreamJSON_ErrorHandler(t *testing.T) {
 		assert.Contains(t, output, `{"item":"3"}`)
 	})
 }
+
+func TestNDJSON(t *testing.T) {
+	t.Parallel()
+
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	t.Run("writes each item as a line", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		err := response.NDJSON(slices.Values([]user{{1, "Alice"}, {2, "Bob"}}))(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, "{\"id\":1,\"name\":\"Alice\"}\n{\"id\":2,\"name\":\"Bob\"}\n", w.Body.String())
+		assert.True(t, w.Flushed)
+	})
+
+	t.Run("stops when the client disconnects", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		produced := 0
+		items := func(yield func(int) bool) {
+			for i := range 100 {
+				produced++
+				if i == 2 {
+					cancel()
+				}
+				if !yield(i) {
+					return
+				}
+			}
+		}
+
+		w := httptest.NewRecorder()
+		err := response.NDJSON(items)(w, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx))
+
+		require.NoError(t, err)
+		assert.Equal(t, "0\n1\n", w.Body.String())
+		assert.Equal(t, 3, produced)
+	})
+
+	t.Run("returns encoding errors", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		err := response.NDJSON(slices.Values([]any{1, make(chan int)}))(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		require.Error(t, err)
+		assert.Equal(t, "1\n", w.Body.String())
+	})
+}
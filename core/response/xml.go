@@ -0,0 +1,43 @@
+package response
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+)
+
+// XML creates an application/xml response with 200 OK status.
+// The document starts with the standard XML header and is encoded directly to the response writer.
+func XML(v any) handler.Response {
+	return XMLWithStatus(v, http.StatusOK)
+}
+
+// XMLWithStatus creates an application/xml response with custom status code.
+// A zero status defaults to 204 No Content for nil values and 200 OK otherwise.
+func XMLWithStatus(v any, status int) handler.Response {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+
+		if status == 0 {
+			if v == nil {
+				status = http.StatusNoContent
+			} else {
+				status = http.StatusOK
+			}
+		}
+
+		w.WriteHeader(status)
+
+		// Respect HTTP spec: certain status codes must not include response body
+		switch status {
+		case http.StatusNoContent, http.StatusNotModified:
+			return nil
+		}
+
+		if _, err := w.Write([]byte(xml.Header)); err != nil {
+			return err
+		}
+		return xml.NewEncoder(w).Encode(v)
+	}
+}
@@ -0,0 +1,78 @@
+package response_test
+
+import (
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/response"
+)
+
+type xmlOrder struct {
+	XMLName xml.Name `xml:"order"`
+	ID      string   `xml:"id,attr"`
+	Total   float64  `xml:"total"`
+}
+
+func TestXML(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		resp     func() http.HandlerFunc
+		status   int
+		expected string
+	}{
+		{
+			name:     "default status",
+			resp:     func() http.HandlerFunc { return adapt(response.XML(xmlOrder{ID: "o-1", Total: 9.5})) },
+			status:   http.StatusOK,
+			expected: xml.Header + `<order id="o-1"><total>9.5</total></order>`,
+		},
+		{
+			name:     "custom status",
+			resp:     func() http.HandlerFunc { return adapt(response.XMLWithStatus(xmlOrder{ID: "o-2"}, http.StatusCreated)) },
+			status:   http.StatusCreated,
+			expected: xml.Header + `<order id="o-2"><total>0</total></order>`,
+		},
+		{
+			name:   "nil value without status",
+			resp:   func() http.HandlerFunc { return adapt(response.XMLWithStatus(nil, 0)) },
+			status: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			w := httptest.NewRecorder()
+			tt.resp()(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+			assert.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "application/xml; charset=utf-8", w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expected, w.Body.String())
+		})
+	}
+
+	t.Run("returns encoding errors", func(t *testing.T) {
+		t.Parallel()
+
+		w := httptest.NewRecorder()
+		err := response.XML(map[string]string{"a": "b"})(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Error(t, err)
+	})
+}
+
+// adapt turns a response into an http.HandlerFunc that fails on render errors
+func adapt(resp func(w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := resp(w, r); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
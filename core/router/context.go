@@ -11,6 +11,7 @@ type Context struct {
 	w      http.ResponseWriter
 	r      *http.Request
 	params map[string]string
+	route  *Route
 }
 
 // Deadline returns the time when work done on behalf of this context should be canceled.
@@ -30,6 +31,9 @@ func (c *Context) Err() error {
 
 // Value returns the value associated with this context for key, or nil if no value is associated with key.
 func (c *Context) Value(key any) any {
+	if _, ok := key.(matchedRouteKey); ok && c.route != nil {
+		return *c.route
+	}
 	return c.r.Context().Value(key)
 }
 
@@ -66,3 +70,77 @@ func newContext(w http.ResponseWriter, r *http.Request, params map[string]string
 		params: params,
 	}
 }
+
+// RoutePattern returns the pattern of the matched route, including the prefixes of
+// the routers it is mounted in, e.g. "/api/users/{id}". It is empty if no route matched.
+func (c *Context) RoutePattern() string {
+	rt, _ := MatchedRoute(c)
+	return rt.Pattern
+}
+
+// RouteMeta returns the metadata value attached to the matched route with the Meta option,
+// or nil if the route has no value for the key.
+func (c *Context) RouteMeta(key string) any {
+	rt, _ := MatchedRoute(c)
+	return rt.Meta[key]
+}
+
+// setRoute sets the matched route; it implements routeSetter.
+func (c *Context) setRoute(rt *Route) {
+	c.route = rt
+}
+
+// routeSetter is implemented by *Context and custom contexts embedding it, which
+// receive the matched route directly rather than through the request context.
+type routeSetter interface {
+	setRoute(*Route)
+}
+
+// matchedRouteKey is the context key of the matched Route.
+type matchedRouteKey struct{}
+
+// routeScopeKey is the context key of the routeScope of mounted and host routers.
+type routeScopeKey struct{}
+
+// routeScope tracks the mount prefix and host pattern a request was delegated through.
+type routeScope struct {
+	prefix string
+	host   string
+}
+
+// MatchedRoute returns the route matched for the request, with its full pattern and
+// metadata. Pass the handler context: it is available to the route's middleware and
+// handler, and ok is false if no route matched.
+//
+// Example:
+//
+//	func RequireScopes(next handler.HandlerFunc[*router.Context]) handler.HandlerFunc[*router.Context] {
+//		return func(ctx *router.Context) handler.Response {
+//			rt, _ := router.MatchedRoute(ctx)
+//			scopes, _ := rt.Meta["scopes"].([]string)
+//			...
+//		}
+//	}
+func MatchedRoute(ctx context.Context) (Route, bool) {
+	rt, ok := ctx.Value(matchedRouteKey{}).(Route)
+	return rt, ok
+}
+
+// withRouteScope returns a context with a copy of the current routeScope updated by fn.
+func withRouteScope(ctx context.Context, fn func(*routeScope)) context.Context {
+	scope, _ := ctx.Value(routeScopeKey{}).(routeScope)
+	fn(&scope)
+	return context.WithValue(ctx, routeScopeKey{}, scope)
+}
+
+// matchedRoute returns the route of the endpoint that matched within the scope of ctx.
+func matchedRoute(ctx context.Context, method, pattern string, route routeConfig) *Route {
+	scope, _ := ctx.Value(routeScopeKey{}).(routeScope)
+	return &Route{
+		Method:  method,
+		Pattern: joinPattern(scope.prefix, pattern),
+		Name:    route.name,
+		Host:    scope.host,
+		Meta:    route.meta,
+	}
+}
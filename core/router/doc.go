@@ -125,6 +125,81 @@
 //	mainRouter := router.New[*router.Context]()
 //	mainRouter.Mount("/api/v1", apiRouter)
 //
+// # Host Routing
+//
+// Serve different routers by host. Host patterns match whole labels: static
+// labels, {param} labels (optionally with a regexp) readable through
+// ctx.Param, and a leading * wildcard matching one or more labels. The most
+// specific matching pattern wins; requests for other hosts are served by the
+// router's own routes, or by a router mounted for the "*" host:
+//
+//	r.Host("admin.example.com", func(r router.Router[*router.Context]) {
+//		r.Use(adminAuth)
+//		r.Get("/", adminDashboardHandler)
+//	})
+//
+//	r.Host("{tenant}.example.com", func(r router.Router[*router.Context]) {
+//		r.Route("/projects", func(r router.Router[*router.Context]) {
+//			r.Get("/{id}", func(ctx *router.Context) handler.Response {
+//				tenant := ctx.Param("tenant")
+//				// ...
+//			})
+//		})
+//		r.Mount("/api", apiRouter)
+//	})
+//
+//	r.MountHost("*", marketingRouter)
+//
+// Host routers have their own middleware stack, which runs after the middleware
+// of the parent router.
+//
+// # Named Routes
+//
+// Name routes at registration and build their paths with URL instead of
+// hardcoding them in templates and redirects. Paths include the prefixes of
+// Route and Mount, and parameters are escaped and checked against regexp
+// constraints:
+//
+//	r.Route("/users", func(r router.Router[*router.Context]) {
+//		r.Get("/{id:[0-9]+}", showUserHandler, router.Name("users.show"))
+//	})
+//	r.Get("/files/*", filesHandler, router.Name("files"))
+//
+//	path, err := r.URL("users.show", "id", "42")           // /users/42
+//	path, err = r.URL("files", "*", "reports/2025/q1.pdf") // /files/reports/2025/q1.pdf
+//
+// URL returns ErrUnknownRoute, ErrMissingRouteParam or ErrInvalidRouteParam
+// when the path cannot be built.
+//
+// # Route Metadata
+//
+// Attach metadata such as required scopes or a rate limit tier to a route with
+// Meta. Middleware reads the matched route, whose pattern includes the prefixes
+// of Route and Mount, and its metadata from the request context:
+//
+//	r.Delete("/users/{id}", deleteUserHandler,
+//		router.Meta("scopes", []string{"users:write"}),
+//		router.Meta("rate_tier", "strict"),
+//	)
+//
+//	func requireScopes(next handler.HandlerFunc[*router.Context]) handler.HandlerFunc[*router.Context] {
+//		return func(ctx *router.Context) handler.Response {
+//			scopes, _ := ctx.RouteMeta("scopes").([]string)
+//			// ctx.RoutePattern() == "/users/{id}"
+//			...
+//		}
+//	}
+//
+// MethodWith registers one handler for several methods with the same options:
+//
+//	r.MethodWith("/users/{id}", updateUserHandler, []string{"PUT", "PATCH"},
+//		router.Name("users.update"),
+//		router.Meta("scopes", []string{"users:write"}),
+//	)
+//
+// MatchedRoute returns the same information for custom context types, and
+// Routes lists the metadata of every route.
+//
 // # Performance
 //
 // The router uses a radix tree for O(k) path matching where k is the key length,
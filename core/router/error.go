@@ -19,12 +19,21 @@ var (
 	ErrNilSubrouter     = errors.New("nil subrouter")
 	ErrInvalidPattern   = errors.New("invalid route path pattern")
 
+	// Host errors
+	ErrInvalidHostPattern = errors.New("invalid host pattern")
+
 	// Tree errors
 	ErrInvalidRegexp    = errors.New("invalid route path pattern regexp")
 	ErrMissingChild     = errors.New("missing child router")
 	ErrWildcardPosition = errors.New("wildcard position must be last")
 	ErrParamDelimiter   = errors.New("param delimiter must be unique")
 	ErrDuplicateParam   = errors.New("duplicate parameter name")
+
+	// URL errors
+	ErrDuplicateRouteName = errors.New("duplicate route name")
+	ErrUnknownRoute       = errors.New("unknown route name")
+	ErrMissingRouteParam  = errors.New("missing route parameter")
+	ErrInvalidRouteParam  = errors.New("invalid route parameter")
 )
 
 // statusCode is an unexported interface that errors can implement
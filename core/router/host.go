@@ -0,0 +1,189 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+)
+
+// hostParamsKey is the request context key of the parameters of the matched host pattern.
+type hostParamsKey struct{}
+
+// hostRoutes holds the host routers of a router, most specific pattern first.
+// It is shared by a router and its inline groups.
+type hostRoutes[C handler.Context] struct {
+	routes []*hostRoute[C]
+}
+
+// hostRoute is a router serving requests whose host matches the pattern.
+type hostRoute[C handler.Context] struct {
+	pattern  string
+	labels   []hostLabel
+	wildcard bool
+	params   int
+	router   *mux[C]
+}
+
+// hostLabel is a static label of a host pattern or a parameter.
+type hostLabel struct {
+	static string
+	key    string
+	rex    *regexp.Regexp
+}
+
+// add registers a host router keeping the routes ordered by specificity:
+// patterns without a wildcard first, then longer patterns, then patterns
+// with fewer parameters, then in registration order.
+func (h *hostRoutes[C]) add(pattern string, router *mux[C]) {
+	hr := parseHostPattern[C](pattern)
+	hr.router = router
+
+	for _, existing := range h.routes {
+		if existing.pattern == hr.pattern {
+			panic(fmt.Errorf("%w: '%s' is already registered", ErrInvalidHostPattern, pattern))
+		}
+	}
+
+	h.routes = append(h.routes, hr)
+	sort.SliceStable(h.routes, func(i, j int) bool {
+		a, b := h.routes[i], h.routes[j]
+		if a.wildcard != b.wildcard {
+			return !a.wildcard
+		}
+		if len(a.labels) != len(b.labels) {
+			return len(a.labels) > len(b.labels)
+		}
+		return a.params < b.params
+	})
+}
+
+// match returns the router and pattern of the first pattern matching the host,
+// and the host parameters.
+func (h *hostRoutes[C]) match(host string) (*mux[C], string, map[string]string) {
+	host = hostname(host)
+	if host == "" {
+		return nil, "", nil
+	}
+	labels := strings.Split(host, ".")
+
+	for _, hr := range h.routes {
+		if params, ok := hr.match(labels); ok {
+			return hr.router, hr.pattern, params
+		}
+	}
+	return nil, "", nil
+}
+
+// match matches the labels of a host against the pattern. A wildcard matches
+// one or more leading labels.
+func (hr *hostRoute[C]) match(labels []string) (map[string]string, bool) {
+	if hr.wildcard {
+		if len(labels) <= len(hr.labels) {
+			return nil, false
+		}
+		labels = labels[len(labels)-len(hr.labels):]
+	} else if len(labels) != len(hr.labels) {
+		return nil, false
+	}
+
+	var params map[string]string
+	for i, l := range hr.labels {
+		switch {
+		case l.key == "":
+			if labels[i] != l.static {
+				return nil, false
+			}
+		case l.rex != nil && !l.rex.MatchString(labels[i]):
+			return nil, false
+		default:
+			if params == nil {
+				params = make(map[string]string, hr.params)
+			}
+			params[l.key] = labels[i]
+		}
+	}
+	return params, true
+}
+
+// parseHostPattern parses a host pattern such as "admin.example.com",
+// "{tenant}.example.com", "{region:[a-z]{2}}.api.example.com" or "*.example.com".
+// Parameters and the wildcard cover whole labels; the wildcard must be the first label.
+func parseHostPattern[C handler.Context](pattern string) *hostRoute[C] {
+	host := hostname(pattern)
+	if host == "" {
+		panic(fmt.Errorf("%w: '%s'", ErrInvalidHostPattern, pattern))
+	}
+
+	hr := &hostRoute[C]{pattern: host}
+	labels := strings.Split(host, ".")
+	if labels[0] == "*" {
+		hr.wildcard = true
+		labels = labels[1:]
+	}
+
+	seen := make(map[string]bool)
+	for _, label := range labels {
+		switch {
+		case label == "" || label == "*" || strings.Contains(label, "*"):
+			panic(fmt.Errorf("%w: '%s'", ErrInvalidHostPattern, pattern))
+
+		case strings.HasPrefix(label, "{") && strings.HasSuffix(label, "}"):
+			key, rexpat, isRegexp := strings.Cut(label[1:len(label)-1], ":")
+			if key == "" {
+				panic(fmt.Errorf("%w: '%s'", ErrInvalidHostPattern, pattern))
+			}
+			if seen[key] {
+				panic(fmt.Errorf("%w: '%s' has duplicate key '%s'", ErrDuplicateParam, pattern, key))
+			}
+			seen[key] = true
+
+			l := hostLabel{key: key}
+			if isRegexp {
+				rex, err := regexp.Compile("^" + strings.TrimSuffix(strings.TrimPrefix(rexpat, "^"), "$") + "$")
+				if err != nil {
+					panic(fmt.Errorf("%w: '%s'", ErrInvalidRegexp, rexpat))
+				}
+				l.rex = rex
+			}
+			hr.labels = append(hr.labels, l)
+			hr.params++
+
+		case strings.ContainsAny(label, "{}"):
+			panic(fmt.Errorf("%w: '%s' parameters must cover whole labels", ErrInvalidHostPattern, pattern))
+
+		default:
+			hr.labels = append(hr.labels, hostLabel{static: label})
+		}
+	}
+
+	return hr
+}
+
+// hostname returns the lowercase host without port and trailing dot.
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// withHostParams stores host parameters in the request context, keeping the
+// parameters of host patterns matched by parent routers.
+func withHostParams(ctx context.Context, params map[string]string) context.Context {
+	if parent, ok := ctx.Value(hostParamsKey{}).(map[string]string); ok {
+		merged := make(map[string]string, len(parent)+len(params))
+		for k, v := range parent {
+			merged[k] = v
+		}
+		for k, v := range params {
+			merged[k] = v
+		}
+		params = merged
+	}
+	return context.WithValue(ctx, hostParamsKey{}, params)
+}
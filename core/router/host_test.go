@@ -0,0 +1,197 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/router"
+)
+
+func reply(body string) handler.HandlerFunc[*router.Context] {
+	return func(ctx *router.Context) handler.Response {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte(body + ctx.Param("tenant") + ctx.Param("region") + ctx.Param("id")))
+			return err
+		}
+	}
+}
+
+func serveHost(r http.Handler, host, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Host = host
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestHostRouting(t *testing.T) {
+	t.Parallel()
+
+	r := router.New[*router.Context]()
+	r.Get("/", reply("main"))
+
+	r.Host("admin.example.com", func(r router.Router[*router.Context]) {
+		r.Get("/", reply("admin"))
+		r.Route("/users", func(r router.Router[*router.Context]) {
+			r.Get("/{id}", reply("admin user "), router.Name("admin.user"))
+		})
+	})
+	r.Host("{tenant}.example.com", func(r router.Router[*router.Context]) {
+		r.Get("/", reply("tenant "))
+		r.Group(func(r router.Router[*router.Context]) {
+			r.Get("/projects/{id}", reply("project "))
+		})
+
+		api := router.New[*router.Context]()
+		api.Get("/status", reply("api "))
+		r.Mount("/api", api)
+	})
+	r.Host("{region:[a-z]{2}}.{tenant}.example.com", func(r router.Router[*router.Context]) {
+		r.Get("/", reply("regional "))
+	})
+	r.Host("*.example.org", func(r router.Router[*router.Context]) {
+		r.Get("/", reply("any org"))
+	})
+
+	tests := []struct {
+		host     string
+		path     string
+		expected string
+	}{
+		{"admin.example.com", "/", "admin"},
+		{"ADMIN.example.com:8443", "/", "admin"},
+		{"admin.example.com", "/users/7", "admin user 7"},
+		{"acme.example.com", "/", "tenant acme"},
+		{"acme.example.com", "/projects/42", "project acme42"},
+		{"acme.example.com", "/api/status", "api acme"},
+		{"eu.acme.example.com", "/", "regional acmeeu"},
+		{"a.b.example.org", "/", "any org"},
+		{"example.com", "/", "main"},
+		{"localhost:8080", "/", "main"},
+		{"europe.acme.example.com", "/", "main"},
+	}
+
+	for _, tt := range tests {
+		w := serveHost(r, tt.host, tt.path)
+		assert.Equal(t, http.StatusOK, w.Code, tt.host+tt.path)
+		assert.Equal(t, tt.expected, w.Body.String(), tt.host+tt.path)
+	}
+
+	t.Run("matched hosts do not fall back to path routes", func(t *testing.T) {
+		t.Parallel()
+
+		w := serveHost(r, "admin.example.com", "/projects/42")
+		assert.Equal(t, router.ErrNotFound.Error()+"\n", w.Body.String())
+	})
+
+	t.Run("lists host routes", func(t *testing.T) {
+		t.Parallel()
+
+		var routes []string
+		for _, route := range r.Routes() {
+			routes = append(routes, route.Host+route.Pattern)
+		}
+		assert.ElementsMatch(t, []string{
+			"/",
+			"admin.example.com/",
+			"admin.example.com/users/{id}",
+			"{tenant}.example.com/",
+			"{tenant}.example.com/projects/{id}",
+			"{tenant}.example.com/api/status",
+			"{region:[a-z]{2}}.{tenant}.example.com/",
+			"*.example.org/",
+		}, routes)
+	})
+
+	t.Run("builds URLs of host routes", func(t *testing.T) {
+		t.Parallel()
+
+		path, err := r.URL("admin.user", "id", "7")
+		require.NoError(t, err)
+		assert.Equal(t, "/users/7", path)
+	})
+}
+
+func TestHostFallbackRouter(t *testing.T) {
+	t.Parallel()
+
+	fallback := router.New[*router.Context]()
+	fallback.Get("/", reply("unknown host"))
+
+	r := router.New[*router.Context]()
+	r.Host("app.example.com", func(r router.Router[*router.Context]) {
+		r.Get("/", reply("app"))
+	})
+	r.MountHost("*", fallback)
+
+	assert.Equal(t, "app", serveHost(r, "app.example.com", "/").Body.String())
+	assert.Equal(t, "unknown host", serveHost(r, "evil.test", "/").Body.String())
+	assert.Equal(t, "unknown host", serveHost(r, "localhost", "/").Body.String())
+}
+
+func TestHostParentMiddleware(t *testing.T) {
+	t.Parallel()
+
+	type key struct{}
+
+	r := router.New[*router.Context]()
+	r.Use(func(next handler.HandlerFunc[*router.Context]) handler.HandlerFunc[*router.Context] {
+		return func(ctx *router.Context) handler.Response {
+			ctx.SetValue(key{}, "parent")
+			response := next(ctx)
+			return func(w http.ResponseWriter, r *http.Request) error {
+				w.Header().Set("X-Parent", ctx.Param("tenant"))
+				return response(w, r)
+			}
+		}
+	})
+	r.Host("{tenant}.example.com", func(r router.Router[*router.Context]) {
+		r.Use(func(next handler.HandlerFunc[*router.Context]) handler.HandlerFunc[*router.Context] {
+			return func(ctx *router.Context) handler.Response {
+				ctx.ResponseWriter().Header().Set("X-Host", "host")
+				return next(ctx)
+			}
+		})
+		r.Get("/", func(ctx *router.Context) handler.Response {
+			return func(w http.ResponseWriter, r *http.Request) error {
+				_, err := w.Write([]byte(ctx.Value(key{}).(string)))
+				return err
+			}
+		})
+		r.Get("/panic", func(ctx *router.Context) handler.Response {
+			panic("boom")
+		})
+	})
+
+	w := serveHost(r, "acme.example.com", "/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "parent", w.Body.String(), "values set by parent middleware reach host routes")
+	assert.Equal(t, "acme", w.Header().Get("X-Parent"))
+	assert.Equal(t, "host", w.Header().Get("X-Host"))
+
+	w = serveHost(r, "acme.example.com", "/panic")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "acme", w.Header().Get("X-Parent"))
+}
+
+func TestHostInvalidPatternsPanic(t *testing.T) {
+	t.Parallel()
+
+	h := func(r router.Router[*router.Context]) {}
+
+	for _, pattern := range []string{"", "api.*.example.com", "api-{region}.example.com", "{a}.{a}.example.com", "{id:[}.example.com"} {
+		r := router.New[*router.Context]()
+		assert.Panics(t, func() { r.Host(pattern, h) }, pattern)
+	}
+
+	r := router.New[*router.Context]()
+	r.Host("app.example.com", h)
+	assert.Panics(t, func() { r.Host("APP.example.com", h) })
+	assert.Panics(t, func() { r.Host("app.example.com", nil) })
+	assert.Panics(t, func() { r.MountHost("app.example.com", nil) })
+}
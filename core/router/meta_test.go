@@ -0,0 +1,189 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/router"
+)
+
+// matched responds with the matched route's pattern and rate tier
+func matched(ctx *router.Context) handler.Response {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		rt, ok := router.MatchedRoute(ctx)
+		if !ok {
+			return router.ErrNotFound
+		}
+		tier, _ := ctx.RouteMeta("rate_tier").(string)
+		_, err := w.Write([]byte(rt.Host + rt.Pattern + " " + tier))
+		return err
+	}
+}
+
+func TestRouteMeta(t *testing.T) {
+	t.Parallel()
+
+	requireScope := func(next handler.HandlerFunc[*router.Context]) handler.HandlerFunc[*router.Context] {
+		return func(ctx *router.Context) handler.Response {
+			scopes, _ := ctx.RouteMeta("scopes").([]string)
+			if len(scopes) > 0 && ctx.Request().Header.Get("X-Scope") != scopes[0] {
+				return func(w http.ResponseWriter, r *http.Request) error {
+					w.WriteHeader(http.StatusForbidden)
+					_, err := w.Write([]byte(ctx.RoutePattern()))
+					return err
+				}
+			}
+			return next(ctx)
+		}
+	}
+
+	r := router.New[*router.Context]()
+	r.Use(requireScope)
+	r.Get("/users/{id}", matched,
+		router.Name("users.show"),
+		router.Meta("scopes", []string{"users:read"}),
+		router.Meta("rate_tier", "standard"),
+	)
+	r.Route("/admin", func(r router.Router[*router.Context]) {
+		r.Use(requireScope)
+		r.Delete("/users/{id:[0-9]+}", matched, router.Meta("scopes", []string{"users:write"}))
+	})
+
+	api := router.New[*router.Context]()
+	api.Get("/", matched, router.Meta("rate_tier", "public"))
+	api.Get("/*", matched)
+	r.Mount("/api", api)
+
+	r.Host("{tenant}.example.com", func(r router.Router[*router.Context]) {
+		r.Get("/", matched, router.Meta("rate_tier", "tenant"))
+	})
+
+	t.Run("middleware reads route metadata", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "/users/{id}", w.Body.String())
+
+		req = httptest.NewRequest(http.MethodDelete, "/admin/users/7", nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, "/admin/users/{id:[0-9]+}", w.Body.String())
+	})
+
+	t.Run("handlers read the matched route", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name   string
+			method string
+			host   string
+			path   string
+			scope  string
+			body   string
+		}{
+			{"root route", http.MethodGet, "example.com", "/users/1", "users:read", "/users/{id} standard"},
+			{"sub-router route", http.MethodDelete, "example.com", "/admin/users/7", "users:write", "/admin/users/{id:[0-9]+} "},
+			{"mounted index route", http.MethodGet, "example.com", "/api", "", "/api public"},
+			{"mounted catch-all route", http.MethodGet, "example.com", "/api/v1/status", "", "/api/* "},
+			{"host route", http.MethodGet, "acme.example.com", "/", "", "{tenant}.example.com/ tenant"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+
+				req := httptest.NewRequest(tt.method, tt.path, nil)
+				req.Host = tt.host
+				req.Header.Set("X-Scope", tt.scope)
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+				require.Equal(t, http.StatusOK, w.Code)
+				assert.Equal(t, tt.body, w.Body.String())
+			})
+		}
+	})
+
+	t.Run("routes include metadata", func(t *testing.T) {
+		t.Parallel()
+
+		meta := make(map[string]map[string]any)
+		for _, rt := range r.Routes() {
+			meta[rt.Host+" "+rt.Method+" "+rt.Pattern] = rt.Meta
+		}
+
+		assert.Equal(t, map[string]any{"scopes": []string{"users:read"}, "rate_tier": "standard"}, meta[" GET /users/{id}"])
+		assert.Equal(t, map[string]any{"scopes": []string{"users:write"}}, meta[" DELETE /admin/users/{id:[0-9]+}"])
+		assert.Equal(t, map[string]any{"rate_tier": "public"}, meta[" GET /api"])
+		assert.Equal(t, map[string]any{"rate_tier": "tenant"}, meta["{tenant}.example.com GET /"])
+		assert.Nil(t, meta[" GET /api/*"])
+	})
+
+	t.Run("custom context types", func(t *testing.T) {
+		t.Parallel()
+
+		custom := router.New[*testCustomContext](router.WithContextFactory(
+			func(w http.ResponseWriter, r *http.Request, params map[string]string) *testCustomContext {
+				return &testCustomContext{w: w, r: r, params: params}
+			},
+		))
+		custom.Get("/reports/{id}", func(ctx *testCustomContext) handler.Response {
+			rt, ok := router.MatchedRoute(ctx)
+			return func(w http.ResponseWriter, r *http.Request) error {
+				if !ok {
+					return router.ErrNotFound
+				}
+				_, err := w.Write([]byte(rt.Pattern + " " + rt.Name + " " + rt.Meta["rate_tier"].(string)))
+				return err
+			}
+		}, router.Name("reports.show"), router.Meta("rate_tier", "batch"))
+
+		w := httptest.NewRecorder()
+		custom.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports/1", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "/reports/{id} reports.show batch", w.Body.String())
+	})
+
+	t.Run("options apply to every method", func(t *testing.T) {
+		t.Parallel()
+
+		r := router.New[*router.Context]()
+		r.MethodWith("/users/{id}", matched, []string{"PUT", "PATCH", "put"},
+			router.Name("users.update"),
+			router.Meta("rate_tier", "strict"),
+		)
+
+		for _, method := range []string{http.MethodPut, http.MethodPatch} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(method, "/users/1", nil))
+			require.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "/users/{id} strict", w.Body.String())
+		}
+
+		routes := r.Routes()
+		require.Len(t, routes, 2)
+		for _, rt := range routes {
+			assert.Equal(t, "users.update", rt.Name)
+			assert.Equal(t, map[string]any{"rate_tier": "strict"}, rt.Meta)
+		}
+
+		path, err := r.URL("users.update", "id", "7")
+		require.NoError(t, err)
+		assert.Equal(t, "/users/7", path)
+	})
+
+	t.Run("no route matched", func(t *testing.T) {
+		t.Parallel()
+
+		_, ok := router.MatchedRoute(httptest.NewRequest(http.MethodGet, "/", nil).Context())
+		assert.False(t, ok)
+	})
+}
@@ -581,3 +581,35 @@ func TestMountMethodOverride(t *testing.T) {
 		})
 	}
 }
+
+func TestMountedRoutesAreListed(t *testing.T) {
+	t.Parallel()
+
+	h := func(ctx *router.Context) handler.Response { return nil }
+
+	r := router.New[*router.Context]()
+	r.Get("/health", h)
+	r.Route("/users", func(r router.Router[*router.Context]) {
+		r.Get("/", h)
+		r.Get("/{id}", h, router.Name("users.show"))
+		r.Route("/{id}/posts", func(r router.Router[*router.Context]) {
+			r.Post("/", h)
+		})
+	})
+
+	api := router.New[*router.Context]()
+	api.Delete("/sessions/{id}", h)
+	r.Mount("/api/", api)
+
+	var routes []string
+	for _, route := range r.Routes() {
+		routes = append(routes, route.Method+" "+route.Pattern+" "+route.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"GET /health ",
+		"GET /users ",
+		"GET /users/{id} users.show",
+		"POST /users/{id}/posts ",
+		"DELETE /api/sessions/{id} ",
+	}, routes)
+}
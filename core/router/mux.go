@@ -1,6 +1,7 @@
 package router
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -20,12 +21,16 @@ type mux[C handler.Context] struct {
 	parent       *mux[C] // for sub-routers
 	inline       bool    // for inline groups
 	handler      handler.HandlerFunc[C]
+	urls         *urlRegistry   // named routes, shared with inline groups
+	hosts        *hostRoutes[C] // host routers, shared with inline groups
 }
 
 // newMux creates a new router instance.
 func newMux[C handler.Context](opts ...Option[C]) *mux[C] {
 	m := &mux[C]{
 		tree:         &node[C]{},
+		urls:         newURLRegistry(),
+		hosts:        &hostRoutes[C]{},
 		errorHandler: defaultErrorHandler[C],
 		logger:       slog.Default(),
 	}
@@ -52,6 +57,18 @@ func newMux[C handler.Context](opts ...Option[C]) *mux[C] {
 
 // ServeHTTP implements http.Handler interface.
 func (m *mux[C]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Host routers take precedence; unmatched hosts fall through to the path routes
+	if len(m.hosts.routes) > 0 {
+		if sub, pattern, params := m.hosts.match(r.Host); sub != nil {
+			ctx := withRouteScope(r.Context(), func(s *routeScope) { s.host = pattern })
+			if len(params) > 0 {
+				ctx = withHostParams(ctx, params)
+			}
+			m.serveHost(w, r.WithContext(ctx), sub, params)
+			return
+		}
+	}
+
 	ww := newResponseWriter(w)
 
 	// Use RawPath if available to preserve URL encoding
@@ -74,10 +91,25 @@ func (m *mux[C]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Find route and extract params
 	rn, eps, fn, params := m.tree.findRoute(method, path)
 
-	// Build params map
+	// Expose the matched route to middleware and handlers. Contexts that do not
+	// embed *Context read it from the request context instead.
+	var route *Route
+	if ep := eps[method]; fn != nil && ep != nil {
+		route = matchedRoute(r.Context(), r.Method, ep.pattern, ep.route)
+		var zero C
+		if _, ok := any(zero).(routeSetter); !ok {
+			r = r.WithContext(context.WithValue(r.Context(), matchedRouteKey{}, *route))
+		}
+	}
+
+	// Build params map; path params take precedence over host params
+	hostParams, _ := r.Context().Value(hostParamsKey{}).(map[string]string)
 	var paramsMap map[string]string
-	if len(params.Keys) > 0 {
-		paramsMap = make(map[string]string, len(params.Keys))
+	if len(params.Keys) > 0 || len(hostParams) > 0 {
+		paramsMap = make(map[string]string, len(params.Keys)+len(hostParams))
+		for key, value := range hostParams {
+			paramsMap[key] = value
+		}
 		for i, key := range params.Keys {
 			if i < len(params.Values) {
 				paramsMap[key] = params.Values[i]
@@ -87,32 +119,12 @@ func (m *mux[C]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	// Create context with params
 	ctx := m.newContext(ww, r, paramsMap)
+	if rs, ok := any(ctx).(routeSetter); ok && route != nil {
+		rs.setRoute(route)
+	}
 
 	// Recover from panics to prevent server crashes
-	defer func() {
-		if p := recover(); p != nil {
-			// Wrap panic in error with stack trace
-			panicErr := &panicError{
-				value: p,
-				stack: debug.Stack(),
-			}
-
-			// Check if response has already been written
-			if ww.Written() {
-				// Can't send error response, just log the panic
-				m.logger.Error("panic after response written",
-					"value", panicErr.value,
-					"stack", string(panicErr.stack),
-					"path", r.URL.Path,
-					"method", r.Method,
-					"status", ww.Status(),
-				)
-			} else {
-				// Response not written, can use error handler
-				m.errorHandler(ctx, panicErr)
-			}
-		}
-	}()
+	defer m.recoverPanic(ctx, ww, r)
 
 	// Check if we hit a mounted subrouter
 	if rn != nil && rn.subroutes != nil {
@@ -143,7 +155,8 @@ func (m *mux[C]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// Update request with the sub-path and delegate to subrouter
-		r2 := r.Clone(r.Context())
+		prefix := strings.TrimSuffix(mountPath, "/")
+		r2 := r.Clone(withRouteScope(r.Context(), func(s *routeScope) { s.prefix += prefix }))
 		r2.URL.Path = subPath
 		rn.subroutes.ServeHTTP(w, r2)
 		return
@@ -188,62 +201,126 @@ func (m *mux[C]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// serveHost dispatches a request matched by a host router through the middleware
+// of the router, so recovery, logging and the like also run for host routes.
+func (m *mux[C]) serveHost(w http.ResponseWriter, r *http.Request, sub http.Handler, params map[string]string) {
+	if len(m.middlewares) == 0 {
+		sub.ServeHTTP(w, r)
+		return
+	}
+
+	ww := newResponseWriter(w)
+	ctx := m.newContext(ww, r, params)
+	defer m.recoverPanic(ctx, ww, r)
+
+	// The request is taken from the context to keep the values set by middleware
+	fn := chain(m.middlewares, func(ctx C) handler.Response {
+		return func(w http.ResponseWriter, _ *http.Request) error {
+			sub.ServeHTTP(w, ctx.Request())
+			return nil
+		}
+	})
+
+	response := fn(ctx)
+	if response == nil {
+		m.errorHandler(ctx, ErrNilResponse)
+		return
+	}
+
+	if err := response(ww, r); err != nil {
+		m.errorHandler(ctx, err)
+	}
+}
+
+// recoverPanic handles a panic of a handler or middleware. It must be deferred.
+func (m *mux[C]) recoverPanic(ctx C, ww *responseWriter, r *http.Request) {
+	if p := recover(); p != nil {
+		// Wrap panic in error with stack trace
+		panicErr := &panicError{
+			value: p,
+			stack: debug.Stack(),
+		}
+
+		// Check if response has already been written
+		if ww.Written() {
+			// Can't send error response, just log the panic
+			m.logger.Error("panic after response written",
+				"value", panicErr.value,
+				"stack", string(panicErr.stack),
+				"path", r.URL.Path,
+				"method", r.Method,
+				"status", ww.Status(),
+			)
+		} else {
+			// Response not written, can use error handler
+			m.errorHandler(ctx, panicErr)
+		}
+	}
+}
+
 // Get registers a handler for GET requests.
-func (m *mux[C]) Get(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mGET, pattern, handler)
+func (m *mux[C]) Get(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mGET, pattern, handler, newRouteConfig(opts))
 }
 
 // Post registers a handler for POST requests.
-func (m *mux[C]) Post(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mPOST, pattern, handler)
+func (m *mux[C]) Post(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mPOST, pattern, handler, newRouteConfig(opts))
 }
 
 // Put registers a handler for PUT requests.
-func (m *mux[C]) Put(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mPUT, pattern, handler)
+func (m *mux[C]) Put(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mPUT, pattern, handler, newRouteConfig(opts))
 }
 
 // Delete registers a handler for DELETE requests.
-func (m *mux[C]) Delete(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mDELETE, pattern, handler)
+func (m *mux[C]) Delete(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mDELETE, pattern, handler, newRouteConfig(opts))
 }
 
 // Patch registers a handler for PATCH requests.
-func (m *mux[C]) Patch(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mPATCH, pattern, handler)
+func (m *mux[C]) Patch(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mPATCH, pattern, handler, newRouteConfig(opts))
 }
 
 // Head registers a handler for HEAD requests.
-func (m *mux[C]) Head(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mHEAD, pattern, handler)
+func (m *mux[C]) Head(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mHEAD, pattern, handler, newRouteConfig(opts))
 }
 
 // Options registers a handler for OPTIONS requests.
-func (m *mux[C]) Options(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mOPTIONS, pattern, handler)
+func (m *mux[C]) Options(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mOPTIONS, pattern, handler, newRouteConfig(opts))
 }
 
 // Connect registers a handler for CONNECT requests.
-func (m *mux[C]) Connect(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mCONNECT, pattern, handler)
+func (m *mux[C]) Connect(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mCONNECT, pattern, handler, newRouteConfig(opts))
 }
 
 // Trace registers a handler for TRACE requests.
-func (m *mux[C]) Trace(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mTRACE, pattern, handler)
+func (m *mux[C]) Trace(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mTRACE, pattern, handler, newRouteConfig(opts))
 }
 
 // Handle registers a handler for all HTTP methods.
-func (m *mux[C]) Handle(pattern string, handler handler.HandlerFunc[C]) {
-	m.handle(mALL, pattern, handler)
+func (m *mux[C]) Handle(pattern string, handler handler.HandlerFunc[C], opts ...RouteOption) {
+	m.handle(mALL, pattern, handler, newRouteConfig(opts))
 }
 
 // Method registers a handler for one or more specific HTTP methods.
 func (m *mux[C]) Method(pattern string, handler handler.HandlerFunc[C], methods ...string) {
+	m.MethodWith(pattern, handler, methods)
+}
+
+// MethodWith registers a handler for one or more specific HTTP methods
+// with route options. Every method shares the same name and metadata.
+func (m *mux[C]) MethodWith(pattern string, handler handler.HandlerFunc[C], methods []string, opts ...RouteOption) {
 	if len(methods) == 0 {
 		panic(fmt.Errorf("%w: no methods provided", ErrInvalidMethod))
 	}
 
+	cfg := newRouteConfig(opts)
 	seen := make(map[methodTyp]bool)
 	for _, method := range methods {
 		mt, ok := methodMap[strings.ToUpper(method)]
@@ -254,7 +331,7 @@ func (m *mux[C]) Method(pattern string, handler handler.HandlerFunc[C], methods
 			continue
 		}
 		seen[mt] = true
-		m.handle(mt, pattern, handler)
+		m.handle(mt, pattern, handler, cfg)
 	}
 }
 
@@ -274,6 +351,8 @@ func (m *mux[C]) With(middlewares ...handler.Middleware[C]) Router[C] {
 		inline:       true,
 		parent:       m,
 		tree:         m.tree,
+		urls:         m.urls,
+		hosts:        m.hosts,
 		middlewares:  middlewares,
 		errorHandler: m.errorHandler,
 		newContext:   m.newContext,
@@ -325,6 +404,9 @@ func (m *mux[C]) Mount(pattern string, sub Router[C]) {
 	subMux.logger = m.logger
 	subMux.newContext = m.newContext
 
+	// Named routes of the subrouter are resolved through the mount prefix
+	m.urls.mount(pattern, subMux.urls)
+
 	// Stub handler - actual routing is handled by the tree traversal
 	mountHandler := func(ctx C) handler.Response {
 		return nil
@@ -334,18 +416,18 @@ func (m *mux[C]) Mount(pattern string, sub Router[C]) {
 	var nodes []*node[C]
 
 	if pattern == "" || pattern[len(pattern)-1] != '/' {
-		n1 := m.handle(mALL|mSTUB, pattern, mountHandler)
+		n1 := m.handle(mALL|mSTUB, pattern, mountHandler, routeConfig{})
 		if n1 != nil {
 			nodes = append(nodes, n1)
 		}
-		n2 := m.handle(mALL|mSTUB, pattern+"/", mountHandler)
+		n2 := m.handle(mALL|mSTUB, pattern+"/", mountHandler, routeConfig{})
 		if n2 != nil {
 			nodes = append(nodes, n2)
 		}
 		pattern += "/"
 	}
 
-	n := m.handle(mALL|mSTUB, pattern+"*", mountHandler)
+	n := m.handle(mALL|mSTUB, pattern+"*", mountHandler, routeConfig{})
 	if n != nil {
 		nodes = append(nodes, n)
 	}
@@ -356,13 +438,64 @@ func (m *mux[C]) Mount(pattern string, sub Router[C]) {
 	}
 }
 
+// Host creates a new sub-router serving requests whose host matches the pattern.
+func (m *mux[C]) Host(pattern string, fn func(r Router[C])) Router[C] {
+	if fn == nil {
+		panic(fmt.Errorf("%w on host '%s'", ErrNilSubrouter, pattern))
+	}
+	subRouter := newMux[C]()
+
+	subRouter.errorHandler = m.errorHandler
+	subRouter.newContext = m.newContext
+	subRouter.logger = m.logger
+
+	fn(subRouter)
+	m.MountHost(pattern, subRouter)
+	return subRouter
+}
+
+// MountHost attaches a sub-router serving requests whose host matches the pattern.
+func (m *mux[C]) MountHost(pattern string, sub Router[C]) {
+	if sub == nil {
+		panic(fmt.Errorf("%w on host '%s'", ErrNilRouter, pattern))
+	}
+
+	subMux, ok := sub.(*mux[C])
+	if !ok {
+		panic("foundation: can only mount *mux[C] routers")
+	}
+
+	subMux.errorHandler = m.errorHandler
+	subMux.logger = m.logger
+	subMux.newContext = m.newContext
+
+	m.hosts.add(pattern, subMux)
+	m.urls.mount("", subMux.urls)
+}
+
+// URL builds the path of the route registered with the given name, including
+// the prefixes of the routers it is mounted under. Params are key-value pairs
+// filling the route parameters; use "*" as the key of a catch-all.
+func (m *mux[C]) URL(name string, params ...string) (string, error) {
+	return m.urls.build(name, params)
+}
+
 // Routes returns all registered routes.
 func (m *mux[C]) Routes() []Route {
-	return m.tree.routes()
+	routes := m.tree.routes()
+	for _, hr := range m.hosts.routes {
+		for _, rt := range hr.router.Routes() {
+			if rt.Host == "" {
+				rt.Host = hr.pattern
+			}
+			routes = append(routes, rt)
+		}
+	}
+	return routes
 }
 
 // handle registers a handler in the routing tree.
-func (m *mux[C]) handle(method methodTyp, pattern string, fn handler.HandlerFunc[C]) *node[C] {
+func (m *mux[C]) handle(method methodTyp, pattern string, fn handler.HandlerFunc[C], route routeConfig) *node[C] {
 	if len(pattern) == 0 || pattern[0] != '/' {
 		panic(fmt.Errorf("%w: '%s'", ErrInvalidPattern, pattern))
 	}
@@ -394,5 +527,9 @@ func (m *mux[C]) handle(method methodTyp, pattern string, fn handler.HandlerFunc
 		h = fn
 	}
 
-	return m.tree.insertRoute(method, pattern, h)
+	n := m.tree.insertRoute(method, pattern, h, route)
+	if route.name != "" {
+		m.urls.add(route.name, pattern)
+	}
+	return n
 }
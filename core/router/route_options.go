@@ -0,0 +1,39 @@
+package router
+
+// RouteOption configures a single route at registration.
+type RouteOption func(*routeConfig)
+
+// routeConfig holds the options of a registered route.
+type routeConfig struct {
+	name string
+	meta map[string]any
+}
+
+// newRouteConfig applies the route options.
+func newRouteConfig(opts []RouteOption) routeConfig {
+	var cfg routeConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// Name names the route so its path can be built with Router.URL.
+// A name identifies a single pattern; several methods may share it.
+func Name(name string) RouteOption {
+	return func(cfg *routeConfig) {
+		cfg.name = name
+	}
+}
+
+// Meta attaches a metadata value to the route, such as required scopes or a rate
+// limit tier. Middleware reads it with MatchedRoute or Context.RouteMeta, and it is
+// listed by Router.Routes.
+func Meta(key string, value any) RouteOption {
+	return func(cfg *routeConfig) {
+		if cfg.meta == nil {
+			cfg.meta = make(map[string]any)
+		}
+		cfg.meta[key] = value
+	}
+}
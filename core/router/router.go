@@ -13,19 +13,20 @@ type Router[C handler.Context] interface {
 	Routes
 
 	// HTTP method handlers
-	Get(pattern string, h handler.HandlerFunc[C])
-	Post(pattern string, h handler.HandlerFunc[C])
-	Put(pattern string, h handler.HandlerFunc[C])
-	Delete(pattern string, h handler.HandlerFunc[C])
-	Patch(pattern string, h handler.HandlerFunc[C])
-	Head(pattern string, h handler.HandlerFunc[C])
-	Options(pattern string, h handler.HandlerFunc[C])
-	Connect(pattern string, h handler.HandlerFunc[C])
-	Trace(pattern string, h handler.HandlerFunc[C])
+	Get(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
+	Post(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
+	Put(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
+	Delete(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
+	Patch(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
+	Head(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
+	Options(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
+	Connect(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
+	Trace(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
 
 	// Generic handlers
-	Handle(pattern string, h handler.HandlerFunc[C])
+	Handle(pattern string, h handler.HandlerFunc[C], opts ...RouteOption)
 	Method(pattern string, h handler.HandlerFunc[C], methods ...string)
+	MethodWith(pattern string, h handler.HandlerFunc[C], methods []string, opts ...RouteOption)
 
 	// Middleware
 	Use(middlewares ...handler.Middleware[C])
@@ -35,6 +36,13 @@ type Router[C handler.Context] interface {
 	Group(fn func(r Router[C])) Router[C]
 	Route(pattern string, fn func(r Router[C])) Router[C]
 	Mount(pattern string, sub Router[C])
+
+	// Host routing
+	Host(pattern string, fn func(r Router[C])) Router[C]
+	MountHost(pattern string, sub Router[C])
+
+	// Reverse routing
+	URL(name string, params ...string) (string, error)
 }
 
 // Routes provides route introspection capabilities for debugging and monitoring.
@@ -43,9 +51,15 @@ type Routes interface {
 }
 
 // Route describes a single route in the router with its HTTP method and pattern.
+// Name is set for routes registered with the Name option, Host for routes
+// served only on hosts matching a host pattern, and Meta for routes registered
+// with the Meta option. Meta is shared with the router and must not be modified.
 type Route struct {
 	Method  string
 	Pattern string
+	Name    string
+	Host    string
+	Meta    map[string]any
 }
 
 // New creates a new router with the given options.
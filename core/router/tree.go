@@ -114,6 +114,9 @@ type endpoint[C handler.Context] struct {
 
 	// parameter keys recorded on handler nodes
 	paramKeys []string
+
+	// route options set at registration
+	route routeConfig
 }
 
 func (s endpoints[C]) value(method methodTyp) *endpoint[C] {
@@ -125,7 +128,7 @@ func (s endpoints[C]) value(method methodTyp) *endpoint[C] {
 	return mh
 }
 
-func (n *node[C]) insertRoute(method methodTyp, pattern string, handler handler.HandlerFunc[C]) *node[C] {
+func (n *node[C]) insertRoute(method methodTyp, pattern string, handler handler.HandlerFunc[C], route routeConfig) *node[C] {
 	var parent *node[C]
 	search := pattern
 
@@ -133,7 +136,7 @@ func (n *node[C]) insertRoute(method methodTyp, pattern string, handler handler.
 		// Handle key exhaustion
 		if len(search) == 0 {
 			// Insert or update the node's leaf handler
-			n.setEndpoint(method, handler, pattern)
+			n.setEndpoint(method, handler, pattern, route)
 			return n
 		}
 
@@ -161,7 +164,7 @@ func (n *node[C]) insertRoute(method methodTyp, pattern string, handler handler.
 		if n == nil {
 			child := &node[C]{label: label, tail: segTail, prefix: search}
 			hn := parent.addChild(child, search)
-			hn.setEndpoint(method, handler, pattern)
+			hn.setEndpoint(method, handler, pattern, route)
 
 			return hn
 		}
@@ -201,7 +204,7 @@ func (n *node[C]) insertRoute(method methodTyp, pattern string, handler handler.
 		// If the new key is a subset, set the method/handler on this node and finish.
 		search = search[commonPrefix:]
 		if len(search) == 0 {
-			child.setEndpoint(method, handler, pattern)
+			child.setEndpoint(method, handler, pattern, route)
 			return child
 		}
 
@@ -212,7 +215,7 @@ func (n *node[C]) insertRoute(method methodTyp, pattern string, handler handler.
 			prefix: search,
 		}
 		hn := child.addChild(subchild, search)
-		hn.setEndpoint(method, handler, pattern)
+		hn.setEndpoint(method, handler, pattern, route)
 		return hn
 	}
 }
@@ -327,7 +330,7 @@ func (n *node[C]) getEdge(ntyp nodeTyp, label, tail byte, prefix string) *node[C
 	return nil
 }
 
-func (n *node[C]) setEndpoint(method methodTyp, handler handler.HandlerFunc[C], pattern string) {
+func (n *node[C]) setEndpoint(method methodTyp, handler handler.HandlerFunc[C], pattern string, route routeConfig) {
 	// Set the handler for the method type on the node
 	if n.endpoints == nil {
 		n.endpoints = make(endpoints[C])
@@ -340,23 +343,27 @@ func (n *node[C]) setEndpoint(method methodTyp, handler handler.HandlerFunc[C],
 		h.handler = handler
 		h.pattern = pattern
 		h.paramKeys = paramKeys
+		h.route = route
 	}
 	if method&mALL == mALL {
 		h := n.endpoints.value(mALL)
 		h.handler = handler
 		h.pattern = pattern
 		h.paramKeys = paramKeys
+		h.route = route
 		for _, m := range methodMap {
 			h := n.endpoints.value(m)
 			h.handler = handler
 			h.pattern = pattern
 			h.paramKeys = paramKeys
+			h.route = route
 		}
 	} else {
 		h := n.endpoints.value(method)
 		h.handler = handler
 		h.pattern = pattern
 		h.paramKeys = paramKeys
+		h.route = route
 	}
 }
 
@@ -529,7 +536,16 @@ func (n *node[C]) routes() []Route {
 	rts := []Route{}
 
 	n.walk(func(eps endpoints[C], subroutes Router[C]) bool {
-		if eps[mSTUB] != nil && eps[mSTUB].handler != nil && subroutes == nil {
+		if eps[mSTUB] != nil && eps[mSTUB].handler != nil {
+			// Mounted subrouters register three stub nodes; list their routes once,
+			// under the mount prefix, from the catch-all node
+			if subroutes != nil && strings.HasSuffix(eps[mSTUB].pattern, "/*") {
+				prefix := strings.TrimSuffix(eps[mSTUB].pattern, "/*")
+				for _, rt := range subroutes.Routes() {
+					rt.Pattern = joinPattern(prefix, rt.Pattern)
+					rts = append(rts, rt)
+				}
+			}
 			return false
 		}
 
@@ -557,7 +573,7 @@ func (n *node[C]) routes() []Route {
 				if m == "" {
 					continue
 				}
-				rt := Route{Method: m, Pattern: p}
+				rt := Route{Method: m, Pattern: p, Name: mh[mt].route.name, Meta: mh[mt].route.meta}
 				rts = append(rts, rt)
 			}
 		}
@@ -673,6 +689,15 @@ func patParamKeys(pattern string) []string {
 	}
 }
 
+// joinPattern prefixes a sub-router pattern with its mount path. The index route
+// of a sub-router is served at the mount path itself.
+func joinPattern(prefix, pattern string) string {
+	if pattern == "/" && prefix != "" {
+		return prefix
+	}
+	return prefix + pattern
+}
+
 // longestPrefix finds the length of the shared prefix
 // of two strings
 func longestPrefix(k1, k2 string) int {
@@ -0,0 +1,158 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// urlRegistry maps route names to patterns for reverse routing.
+// It is shared by a router and its inline groups.
+type urlRegistry struct {
+	names  map[string]namedRoute
+	mounts []urlMount
+}
+
+// namedRoute is a named route pattern split into parts.
+type namedRoute struct {
+	pattern string
+	parts   []urlPart
+}
+
+// urlMount links the registry of a mounted subrouter with its mount prefix.
+type urlMount struct {
+	prefix []urlPart
+	urls   *urlRegistry
+}
+
+// urlPart is either a static piece of a pattern or a parameter.
+type urlPart struct {
+	static   string
+	key      string
+	rex      *regexp.Regexp
+	catchAll bool
+}
+
+func newURLRegistry() *urlRegistry {
+	return &urlRegistry{names: make(map[string]namedRoute)}
+}
+
+// add registers a route name. Re-registering a name is only allowed for the same pattern,
+// so a name can cover several methods of one route.
+func (u *urlRegistry) add(name, pattern string) {
+	if nr, ok := u.names[name]; ok {
+		if nr.pattern != pattern {
+			panic(fmt.Errorf("%w: '%s' is used by '%s' and '%s'", ErrDuplicateRouteName, name, nr.pattern, pattern))
+		}
+		return
+	}
+	u.names[name] = namedRoute{pattern: pattern, parts: urlParts(pattern)}
+}
+
+// mount records a subrouter registry mounted at the given pattern.
+func (u *urlRegistry) mount(pattern string, sub *urlRegistry) {
+	u.mounts = append(u.mounts, urlMount{
+		prefix: urlParts(strings.TrimSuffix(pattern, "/")),
+		urls:   sub,
+	})
+}
+
+// lookup finds a named route in this registry or in the mounted ones.
+// Mounted registries are searched on each call, so routes added to a
+// subrouter after it was mounted are found too.
+func (u *urlRegistry) lookup(name string) ([]urlPart, bool) {
+	if nr, ok := u.names[name]; ok {
+		return nr.parts, true
+	}
+	for _, mnt := range u.mounts {
+		parts, ok := mnt.urls.lookup(name)
+		if !ok {
+			continue
+		}
+		// The index route of a subrouter is served at the mount path itself
+		if len(parts) == 1 && parts[0].static == "/" && len(mnt.prefix) > 0 {
+			return mnt.prefix, true
+		}
+		return append(append([]urlPart{}, mnt.prefix...), parts...), true
+	}
+	return nil, false
+}
+
+// build builds the path of a named route from key-value params.
+func (u *urlRegistry) build(name string, params []string) (string, error) {
+	parts, ok := u.lookup(name)
+	if !ok {
+		return "", fmt.Errorf("%w: '%s'", ErrUnknownRoute, name)
+	}
+
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("%w: params of route '%s' must be key-value pairs", ErrInvalidRouteParam, name)
+	}
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	var b strings.Builder
+	for _, part := range parts {
+		if part.key == "" {
+			b.WriteString(part.static)
+			continue
+		}
+
+		value, ok := values[part.key]
+		if !ok || (value == "" && !part.catchAll) {
+			return "", fmt.Errorf("%w: '%s' of route '%s'", ErrMissingRouteParam, part.key, name)
+		}
+		delete(values, part.key)
+
+		switch {
+		case part.catchAll:
+			segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+			for i := range segments {
+				segments[i] = url.PathEscape(segments[i])
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		case part.rex != nil && !part.rex.MatchString(value):
+			return "", fmt.Errorf("%w: '%s' of route '%s' does not match %s", ErrInvalidRouteParam, part.key, name, part.rex)
+		case part.rex == nil && strings.Contains(value, "/"):
+			return "", fmt.Errorf("%w: '%s' of route '%s' cannot contain '/'", ErrInvalidRouteParam, part.key, name)
+		default:
+			b.WriteString(url.PathEscape(value))
+		}
+	}
+
+	for key := range values {
+		return "", fmt.Errorf("%w: route '%s' has no parameter '%s'", ErrInvalidRouteParam, name, key)
+	}
+
+	return b.String(), nil
+}
+
+// urlParts splits a route pattern into static parts and parameters.
+func urlParts(pattern string) []urlPart {
+	var parts []urlPart
+	for pattern != "" {
+		typ, key, rexpat, _, ps, pe := patNextSegment(pattern)
+		if typ == ntStatic {
+			parts = append(parts, urlPart{static: pattern})
+			break
+		}
+		if ps > 0 {
+			parts = append(parts, urlPart{static: pattern[:ps]})
+		}
+
+		part := urlPart{key: key, catchAll: typ == ntCatchAll}
+		if typ == ntRegexp {
+			rex, err := regexp.Compile(rexpat)
+			if err != nil {
+				panic(fmt.Errorf("%w: '%s'", ErrInvalidRegexp, rexpat))
+			}
+			part.rex = rex
+		}
+		parts = append(parts, part)
+		pattern = pattern[pe:]
+	}
+	return parts
+}
@@ -0,0 +1,160 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/handler"
+	"github.com/dmitrymomot/foundation/core/router"
+)
+
+func TestRouterURL(t *testing.T) {
+	t.Parallel()
+
+	h := func(ctx *router.Context) handler.Response {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			_, err := w.Write([]byte(ctx.Param("id") + ctx.Param("*")))
+			return err
+		}
+	}
+
+	r := router.New[*router.Context]()
+	r.Get("/", h, router.Name("home"))
+	r.Get("/users/{id:[0-9]+}", h, router.Name("users.show"))
+	r.Put("/users/{id:[0-9]+}", h, router.Name("users.show"))
+	r.Get("/tags/{tag}", h, router.Name("tags.show"))
+	r.Get("/files/*", h, router.Name("files"))
+	r.Group(func(r router.Router[*router.Context]) {
+		r.Get("/about", h, router.Name("about"))
+	})
+	r.Route("/blog", func(r router.Router[*router.Context]) {
+		r.Get("/", h, router.Name("blog.index"))
+		r.Get("/{id}", h, router.Name("blog.post"))
+	})
+	r.Route("/orgs/{org}", func(r router.Router[*router.Context]) {
+		r.Get("/", h, router.Name("orgs.show"))
+		r.Route("/projects", func(r router.Router[*router.Context]) {
+			r.Get("/{id}", h, router.Name("projects.show"))
+		})
+	})
+
+	admin := router.New[*router.Context]()
+	r.Mount("/admin/", admin)
+	// Routes added after mounting are resolved too
+	admin.Get("/stats", h, router.Name("admin.stats"))
+
+	t.Run("builds paths", func(t *testing.T) {
+		t.Parallel()
+
+		tests := []struct {
+			name     string
+			params   []string
+			expected string
+		}{
+			{"home", nil, "/"},
+			{"users.show", []string{"id", "42"}, "/users/42"},
+			{"tags.show", []string{"tag", "go lang"}, "/tags/go%20lang"},
+			{"files", []string{"*", "docs/a b.pdf"}, "/files/docs/a%20b.pdf"},
+			{"files", []string{"*", ""}, "/files/"},
+			{"about", nil, "/about"},
+			{"orgs.show", []string{"org", "acme"}, "/orgs/acme"},
+			{"projects.show", []string{"org", "acme", "id", "7"}, "/orgs/acme/projects/7"},
+			{"admin.stats", nil, "/admin/stats"},
+			{"blog.index", nil, "/blog"},
+		}
+
+		for _, tt := range tests {
+			path, err := r.URL(tt.name, tt.params...)
+			require.NoError(t, err, tt.name)
+			assert.Equal(t, tt.expected, path)
+		}
+	})
+
+	t.Run("built paths match their routes", func(t *testing.T) {
+		t.Parallel()
+
+		for _, tc := range []struct {
+			name     string
+			params   []string
+			expected string
+		}{
+			{"users.show", []string{"id", "42"}, "42"},
+			{"files", []string{"*", "docs/report.pdf"}, "docs/report.pdf"},
+			{"blog.index", nil, ""},
+			{"blog.post", []string{"id", "hello-world"}, "hello-world"},
+			{"admin.stats", nil, ""},
+		} {
+			path, err := r.URL(tc.name, tc.params...)
+			require.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			assert.Equal(t, http.StatusOK, w.Code, path)
+			assert.Equal(t, tc.expected, w.Body.String())
+		}
+	})
+
+	t.Run("rejects invalid params", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := r.URL("missing")
+		assert.ErrorIs(t, err, router.ErrUnknownRoute)
+
+		_, err = r.URL("users.show")
+		assert.ErrorIs(t, err, router.ErrMissingRouteParam)
+
+		_, err = r.URL("users.show", "id", "")
+		assert.ErrorIs(t, err, router.ErrMissingRouteParam)
+
+		_, err = r.URL("users.show", "id", "abc")
+		assert.ErrorIs(t, err, router.ErrInvalidRouteParam)
+
+		_, err = r.URL("users.show", "id")
+		assert.ErrorIs(t, err, router.ErrInvalidRouteParam)
+
+		_, err = r.URL("users.show", "id", "42", "slug", "x")
+		assert.ErrorIs(t, err, router.ErrInvalidRouteParam)
+
+		_, err = r.URL("tags.show", "tag", "a/b")
+		assert.ErrorIs(t, err, router.ErrInvalidRouteParam)
+
+		_, err = r.URL("projects.show", "id", "7")
+		assert.ErrorIs(t, err, router.ErrMissingRouteParam)
+	})
+
+	t.Run("subrouters resolve their own names", func(t *testing.T) {
+		t.Parallel()
+
+		path, err := admin.URL("admin.stats")
+		require.NoError(t, err)
+		assert.Equal(t, "/stats", path)
+	})
+
+	t.Run("routes report their names", func(t *testing.T) {
+		t.Parallel()
+
+		names := make(map[string]string)
+		for _, route := range r.Routes() {
+			names[route.Method+" "+route.Pattern] = route.Name
+		}
+		assert.Equal(t, "users.show", names["GET /users/{id:[0-9]+}"])
+		assert.Equal(t, "users.show", names["PUT /users/{id:[0-9]+}"])
+		assert.Equal(t, "about", names["GET /about"])
+	})
+}
+
+func TestRouterDuplicateRouteNamePanics(t *testing.T) {
+	t.Parallel()
+
+	r := router.New[*router.Context]()
+	h := func(ctx *router.Context) handler.Response { return nil }
+
+	r.Get("/users", h, router.Name("users"))
+	assert.Panics(t, func() {
+		r.Get("/accounts", h, router.Name("users"))
+	})
+}
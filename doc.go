@@ -37,6 +37,7 @@
 //	github.com/dmitrymomot/foundation/core/handler       - Type-safe HTTP handler abstractions
 //	github.com/dmitrymomot/foundation/core/i18n          - Internationalization with CLDR plural rules
 //	github.com/dmitrymomot/foundation/core/logger        - Structured logging built on slog
+//	github.com/dmitrymomot/foundation/core/openapi       - OpenAPI 3.1 document generation from routes
 //	github.com/dmitrymomot/foundation/core/queue         - Job queue system with workers and scheduling
 //	github.com/dmitrymomot/foundation/core/response      - HTTP response utilities (JSON, HTML, SSE, WebSocket)
 //	github.com/dmitrymomot/foundation/core/router        - High-performance HTTP router with middleware
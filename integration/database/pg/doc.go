@@ -11,6 +11,7 @@
 //   - Connect: Creates a connection pool with retry logic and connection verification
 //   - Migrate: Applies database schema migrations using goose with pgx integration
 //   - Healthcheck: Returns a health check function for monitoring connectivity
+//   - QueueStorage: PostgreSQL backend for the core/queue enqueuer, worker and scheduler
 //   - Error classification functions for common PostgreSQL error patterns
 //
 // Connection establishment uses exponential backoff retry logic to handle transient network issues
@@ -175,6 +176,42 @@
 // concurrent request volume and database capacity. Monitor connection pool metrics
 // to optimize these values for your specific workload.
 //
+// # Queue Storage
+//
+// QueueStorage implements queue.EnqueuerRepository, queue.WorkerRepository and
+// queue.SchedulerRepository, so tasks survive restarts and can be shared by several
+// application instances. Tasks are claimed with FOR UPDATE SKIP LOCKED, and locks of
+// crashed workers are released back to pending before each claim.
+//
+// The required tables, all prefixed with "queue_", are created by the goose migrations
+// embedded as QueueMigrations. Apply them with MigrateQueue rather than Migrate: goose
+// refuses to apply a migration older than the latest one already applied, and the queue
+// migrations are versioned by the foundation release that added them, not by your schema
+// history. Copied into Config.MigrationsPath, a queue migration shipped by an upgrade would
+// sort before migrations you have already applied and fail. MigrateQueue reads them from
+// the embedded FS and records their versions in a separate table (QueueMigrationsTable),
+// so both histories advance independently:
+//
+//	if err := pg.Migrate(ctx, pool, cfg, logger); err != nil {
+//		log.Fatal(err)
+//	}
+//	if err := pg.MigrateQueue(ctx, pool, logger); err != nil {
+//		log.Fatal(err)
+//	}
+//
+//	storage := pg.NewQueueStorage(pool)
+//	enqueuer, _ := queue.NewEnqueuer(storage)
+//	worker, _ := queue.NewWorker(storage, queue.WithQueues("default", "emails"))
+//	scheduler, _ := queue.NewScheduler(storage)
+//
+// SchedulerLock elects a single scheduler among replicas with a session-level advisory
+// lock. If the leader dies, PostgreSQL drops the lock with its connection and another
+// replica takes over on its next check:
+//
+//	scheduler, _ := queue.NewScheduler(storage,
+//		queue.WithLeaderLock(pg.NewSchedulerLock(pool, "default")),
+//	)
+//
 // # Transaction Management
 //
 // The package works seamlessly with pgx transaction management, and provides
@@ -202,36 +239,25 @@
 //
 //		// 2) Enqueue task within the same transaction
 //		type OrderCreated struct { ID uuid.UUID `json:"id"` }
-//		if err := enq.Enqueue(ctx, OrderCreated{ID: orderID}, queue.WithQueue("orders")); err != nil {
+//		if _, err := enq.Enqueue(ctx, OrderCreated{ID: orderID}, queue.WithQueue("orders")); err != nil {
 //			return err
 //		}
 //
 //		return tx.Commit(ctx)
 //	}
 //
-// In your repository/storage implementation, check the context for a transaction:
+// QueueStorage checks the context for a transaction when creating tasks: CreateTask,
+// CreateUniqueTask and CreateBatch write inside the caller's pgx.Tx, so a rolled-back
+// transaction never produces a task and a committed one always does. Other queue
+// operations, such as claiming and completing tasks, always use the pool.
 //
-//	type Storage struct {
-//		pool *pgxpool.Pool
-//	}
+// Custom repositories can follow the same pattern:
 //
 //	func (s *Storage) CreateTask(ctx context.Context, task *queue.Task) error {
-//		const q = `INSERT INTO tasks (id, queue, task_type, task_name, payload, status, priority, retry_count, max_retries, scheduled_at, created_at)
-//			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)`
 //		if tx, ok := pg.TxFromContext(ctx); ok {
-//			_, err := tx.Exec(ctx, q,
-//				task.ID, task.Queue, task.TaskType, task.TaskName,
-//				task.Payload, task.Status, task.Priority, task.RetryCount,
-//				task.MaxRetries, task.ScheduledAt, task.CreatedAt,
-//			)
-//			return err
+//			return s.insertTask(ctx, tx, task)
 //		}
-//		_, err := s.pool.Exec(ctx, q,
-//			task.ID, task.Queue, task.TaskType, task.TaskName,
-//			task.Payload, task.Status, task.Priority, task.RetryCount,
-//			task.MaxRetries, task.ScheduledAt, task.CreatedAt,
-//		)
-//		return err
+//		return s.insertTask(ctx, s.pool, task)
 //	}
 //
 // Use the provided error classification functions to handle transaction-specific errors
@@ -5,14 +5,19 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
+	"github.com/pressly/goose/v3/database"
 )
 
+// QueueMigrationsTable is the goose version table used by MigrateQueue.
+const QueueMigrationsTable = "queue_schema_migrations"
+
 // Migrate applies database schema migrations using goose with pgx integration.
 // Handles the complex pgx->database/sql conversion required since goose doesn't natively support pgx.
 func Migrate(ctx context.Context, pool *pgxpool.Pool, cfg Config, log *slog.Logger) error {
@@ -53,6 +58,48 @@ func Migrate(ctx context.Context, pool *pgxpool.Pool, cfg Config, log *slog.Logg
 	return nil
 }
 
+// MigrateQueue applies the embedded QueueMigrations creating the tables used by QueueStorage.
+// Their versions are tracked in QueueMigrationsTable instead of Config.MigrationsTable, so
+// they never interleave with the application's migrations and cause out-of-order errors.
+// Safe to call on every start, before or after Migrate.
+func MigrateQueue(ctx context.Context, pool *pgxpool.Pool, log *slog.Logger) error {
+	migrations, err := fs.Sub(QueueMigrations, "migrations")
+	if err != nil {
+		return errors.Join(ErrFailedToApplyMigrations, err)
+	}
+
+	store, err := database.NewStore(database.DialectPostgres, QueueMigrationsTable)
+	if err != nil {
+		return errors.Join(ErrFailedToApplyMigrations, err)
+	}
+
+	db := stdlib.OpenDBFromPool(pool)
+	defer func(db *sql.DB) {
+		err := db.Close()
+		if err != nil {
+			log.ErrorContext(ctx, "Failed to close database connection", "error", err)
+		}
+	}(db)
+
+	// The provider keeps its own state, so it does not touch the goose globals used by
+	// Migrate, and ignores Go migrations the application registered with goose.
+	provider, err := goose.NewProvider(goose.DialectCustom, db, migrations,
+		goose.WithStore(store),
+		goose.WithDisableGlobalRegistry(true),
+		goose.WithLogger(newSlogAdapter(log)),
+		goose.WithVerbose(true),
+	)
+	if err != nil {
+		return errors.Join(ErrFailedToApplyMigrations, err)
+	}
+
+	if _, err := provider.Up(ctx); err != nil {
+		return errors.Join(ErrFailedToApplyMigrations, err)
+	}
+
+	return nil
+}
+
 // migrateSlogAdapter bridges goose's Printf-style logging to structured logging.
 type migrateSlogAdapter struct {
 	log *slog.Logger
@@ -0,0 +1,9 @@
+package pg
+
+import "embed"
+
+// QueueMigrations contains the goose migrations creating the tables used by QueueStorage,
+// all prefixed with "queue_" to stay clear of application tables. Apply them with MigrateQueue.
+//
+//go:embed migrations/*.sql
+var QueueMigrations embed.FS
@@ -0,0 +1,57 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS queue_tasks (
+    id UUID PRIMARY KEY,
+    queue VARCHAR(255) NOT NULL DEFAULT 'default',
+    task_type VARCHAR(50) NOT NULL,
+    task_name VARCHAR(255) NOT NULL,
+    payload BYTEA,
+    status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    priority SMALLINT NOT NULL DEFAULT 50,
+    retry_count SMALLINT NOT NULL DEFAULT 0,
+    max_retries SMALLINT NOT NULL DEFAULT 3,
+    scheduled_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ,
+    locked_by UUID,
+    processed_at TIMESTAMPTZ,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Serves ClaimTask: pending tasks ordered by priority, then by scheduled time.
+CREATE INDEX IF NOT EXISTS idx_queue_tasks_claim
+    ON queue_tasks (queue, priority DESC, scheduled_at)
+    WHERE status = 'pending';
+
+-- Serves lock expiration recovery for tasks abandoned by crashed workers.
+CREATE INDEX IF NOT EXISTS idx_queue_tasks_locked_until
+    ON queue_tasks (locked_until)
+    WHERE status = 'processing';
+
+-- Serves GetPendingTaskByName used by the scheduler.
+CREATE INDEX IF NOT EXISTS idx_queue_tasks_pending_name
+    ON queue_tasks (task_name)
+    WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS queue_tasks_dlq (
+    id UUID PRIMARY KEY,
+    task_id UUID NOT NULL,
+    queue VARCHAR(255) NOT NULL,
+    task_type VARCHAR(50) NOT NULL,
+    task_name VARCHAR(255) NOT NULL,
+    payload BYTEA,
+    priority SMALLINT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    retry_count SMALLINT NOT NULL DEFAULT 0,
+    failed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_queue_tasks_dlq_queue ON queue_tasks_dlq (queue, failed_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS queue_tasks_dlq;
+DROP TABLE IF EXISTS queue_tasks;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE queue_tasks ADD COLUMN IF NOT EXISTS backoff JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE queue_tasks DROP COLUMN IF EXISTS backoff;
+-- +goose StatementEnd
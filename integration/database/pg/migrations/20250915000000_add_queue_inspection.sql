@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keeps the retry budget of dead-lettered tasks so requeued tasks get the same attempts.
+ALTER TABLE queue_tasks_dlq ADD COLUMN IF NOT EXISTS max_retries SMALLINT NOT NULL DEFAULT 3;
+
+-- Queues listed here are skipped by ClaimTask.
+CREATE TABLE IF NOT EXISTS queue_paused_queues (
+    queue VARCHAR(255) PRIMARY KEY,
+    paused_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Serves task listing for the queue inspector, newest first.
+CREATE INDEX IF NOT EXISTS idx_queue_tasks_created_at ON queue_tasks (created_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_queue_tasks_created_at;
+DROP TABLE IF EXISTS queue_paused_queues;
+ALTER TABLE queue_tasks_dlq DROP COLUMN IF EXISTS max_retries;
+-- +goose StatementEnd
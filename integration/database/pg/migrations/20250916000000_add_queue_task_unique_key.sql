@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE queue_tasks ADD COLUMN IF NOT EXISTS unique_key VARCHAR(255);
+ALTER TABLE queue_tasks ADD COLUMN IF NOT EXISTS unique_until TIMESTAMPTZ;
+
+-- Serves CreateUniqueTask: lookup of the active holder of a unique key.
+CREATE INDEX IF NOT EXISTS idx_queue_tasks_unique_key
+    ON queue_tasks (unique_key, created_at DESC)
+    WHERE unique_key IS NOT NULL AND status IN ('pending', 'processing');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_queue_tasks_unique_key;
+ALTER TABLE queue_tasks DROP COLUMN IF EXISTS unique_until;
+ALTER TABLE queue_tasks DROP COLUMN IF EXISTS unique_key;
+-- +goose StatementEnd
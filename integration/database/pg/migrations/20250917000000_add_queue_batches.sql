@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Tracks groups of tasks enqueued together; callbacks are stored as serialized tasks.
+CREATE TABLE IF NOT EXISTS queue_task_batches (
+    id UUID PRIMARY KEY,
+    total INTEGER NOT NULL,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    on_complete JSONB,
+    on_failure JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+ALTER TABLE queue_tasks ADD COLUMN IF NOT EXISTS batch_id UUID;
+-- Remaining chain steps created by the worker after the task succeeds.
+ALTER TABLE queue_tasks ADD COLUMN IF NOT EXISTS next JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE queue_tasks DROP COLUMN IF EXISTS next;
+ALTER TABLE queue_tasks DROP COLUMN IF EXISTS batch_id;
+DROP TABLE IF EXISTS queue_task_batches;
+-- +goose StatementEnd
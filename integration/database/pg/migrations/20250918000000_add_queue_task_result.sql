@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Result stored by the task handler, returned by Enqueuer.GetResult. Kept byte for byte
+-- rather than as JSONB, which reorders object keys and would break the envelope of
+-- results encrypted by queue.PayloadCipher.
+ALTER TABLE queue_tasks ADD COLUMN IF NOT EXISTS result BYTEA;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE queue_tasks DROP COLUMN IF EXISTS result;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Keeps the backoff policy, batch and chain steps of dead-lettered tasks for RequeueDLQ.
+ALTER TABLE queue_tasks_dlq ADD COLUMN IF NOT EXISTS backoff JSONB;
+ALTER TABLE queue_tasks_dlq ADD COLUMN IF NOT EXISTS batch_id UUID;
+ALTER TABLE queue_tasks_dlq ADD COLUMN IF NOT EXISTS next JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE queue_tasks_dlq DROP COLUMN IF EXISTS next;
+ALTER TABLE queue_tasks_dlq DROP COLUMN IF EXISTS batch_id;
+ALTER TABLE queue_tasks_dlq DROP COLUMN IF EXISTS backoff;
+-- +goose StatementEnd
@@ -0,0 +1,96 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+var _ queue.LeaderLock = (*SchedulerLock)(nil)
+
+// SchedulerLock implements queue.LeaderLock with a session-level advisory lock.
+// The lock is held on a dedicated pool connection for as long as the instance leads.
+// When the leader process dies, PostgreSQL releases the lock together with the session
+// and another instance takes over on its next scheduler check.
+//
+// Example:
+//
+//	scheduler, err := queue.NewScheduler(storage,
+//		queue.WithLeaderLock(pg.NewSchedulerLock(pool, "default")),
+//	)
+type SchedulerLock struct {
+	pool *pgxpool.Pool
+	name string
+
+	mu   sync.Mutex
+	conn *pgxpool.Conn
+}
+
+// NewSchedulerLock creates a leader lock identified by name.
+// Schedulers sharing a database and lock name elect one leader among them.
+func NewSchedulerLock(pool *pgxpool.Pool, name string) *SchedulerLock {
+	return &SchedulerLock{pool: pool, name: "queue:leader:" + name}
+}
+
+// TryAcquire implements queue.LeaderLock.
+// A held lock is renewed by checking that its session is still alive.
+func (l *SchedulerLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.Ping(ctx); err != nil {
+			// The lock is gone with the session
+			l.conn.Release()
+			l.conn = nil
+			return false, fmt.Errorf("lost scheduler lock %q: %w", l.name, err)
+		}
+		return true, nil
+	}
+
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire connection for scheduler lock %q: %w", l.name, err)
+	}
+
+	var acquired bool
+	const q = `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`
+	if err := conn.QueryRow(ctx, q, l.name).Scan(&acquired); err != nil {
+		conn.Release()
+		return false, fmt.Errorf("failed to acquire scheduler lock %q: %w", l.name, err)
+	}
+	if !acquired {
+		conn.Release()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// Release implements queue.LeaderLock.
+func (l *SchedulerLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	conn := l.conn
+	l.conn = nil
+	defer conn.Release()
+
+	const q = `SELECT pg_advisory_unlock(hashtextextended($1, 0))`
+	if _, err := conn.Exec(ctx, q, l.name); err != nil {
+		// Close the session so PostgreSQL drops the lock anyway
+		_ = conn.Conn().Close(ctx)
+		return fmt.Errorf("failed to release scheduler lock %q: %w", l.name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,764 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+var (
+	_ queue.EnqueuerRepository     = (*QueueStorage)(nil)
+	_ queue.WorkerRepository       = (*QueueStorage)(nil)
+	_ queue.SchedulerRepository    = (*QueueStorage)(nil)
+	_ queue.QueueInspector         = (*QueueStorage)(nil)
+	_ queue.UniqueTaskRepository   = (*QueueStorage)(nil)
+	_ queue.WorkflowRepository     = (*QueueStorage)(nil)
+	_ queue.ResultRepository       = (*QueueStorage)(nil)
+	_ queue.ReleaseRepository      = (*QueueStorage)(nil)
+	_ queue.OrderedClaimRepository = (*QueueStorage)(nil)
+)
+
+// taskColumns lists queue_tasks table columns in the order expected by scanTask.
+const taskColumns = `id, queue, task_type, task_name, payload, status, priority, retry_count,
+	max_retries, backoff, unique_key, unique_until, batch_id, next, scheduled_at, locked_until,
+	locked_by, processed_at, error, result, created_at`
+
+// dlqColumns lists queue_tasks_dlq table columns in the order expected by scanDLQEntry.
+const dlqColumns = `id, task_id, queue, task_type, task_name, payload, priority, error,
+	retry_count, max_retries, backoff, batch_id, next, failed_at, created_at`
+
+// batchColumns lists queue_task_batches table columns in the order expected by scanBatch.
+const batchColumns = `id, total, succeeded, failed, on_complete, on_failure, created_at, finished_at`
+
+// QueueStorage implements the core/queue repository interfaces on top of PostgreSQL.
+// Tables are created by the goose migration shipped in the migrations directory.
+// Multiple application instances can share one database: tasks are claimed with
+// FOR UPDATE SKIP LOCKED so concurrent workers never receive the same task.
+//
+// Tasks are created inside the caller's transaction when the context carries one
+// (see WithTx), so enqueueing commits or rolls back together with business writes.
+type QueueStorage struct {
+	pool *pgxpool.Pool
+}
+
+// NewQueueStorage creates a PostgreSQL-backed queue storage using the given connection pool.
+func NewQueueStorage(pool *pgxpool.Pool) *QueueStorage {
+	return &QueueStorage{pool: pool}
+}
+
+// CreateTask implements queue.EnqueuerRepository and queue.SchedulerRepository.
+// Runs in the transaction stored in ctx with WithTx, if any.
+func (s *QueueStorage) CreateTask(ctx context.Context, task *queue.Task) error {
+	if task == nil {
+		return errors.New("task cannot be nil")
+	}
+
+	return insertTask(ctx, s.conn(ctx), task)
+}
+
+// CreateUniqueTask implements queue.UniqueTaskRepository.
+// A transaction-scoped advisory lock on the key serializes concurrent enqueuers,
+// so the holder lookup and the insert behave as one atomic step. Inside a caller's
+// transaction (see WithTx) the work runs in a savepoint and the lock is held until
+// the caller commits or rolls back.
+func (s *QueueStorage) CreateUniqueTask(ctx context.Context, task *queue.Task, onConflict queue.UniqueConflict) (uuid.UUID, error) {
+	if task == nil {
+		return uuid.Nil, errors.New("task cannot be nil")
+	}
+	if task.UniqueKey == nil {
+		return uuid.Nil, errors.New("task has no unique key")
+	}
+	key := *task.UniqueKey
+
+	const (
+		lockQuery   = `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`
+		holderQuery = `SELECT id, status FROM queue_tasks
+			WHERE unique_key = $1
+				AND status IN ('pending', 'processing')
+				AND (unique_until IS NULL OR unique_until > NOW())
+			ORDER BY created_at DESC
+			LIMIT 1`
+		cancelQuery = `UPDATE queue_tasks SET status = 'cancelled', processed_at = NOW()
+			WHERE id = $1 AND status = 'pending'`
+	)
+
+	resultID := task.ID
+	err := pgx.BeginFunc(ctx, s.conn(ctx), func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, lockQuery, key); err != nil {
+			return fmt.Errorf("failed to lock unique key %q: %w", key, err)
+		}
+
+		var (
+			holderID uuid.UUID
+			status   string
+		)
+		err := tx.QueryRow(ctx, holderQuery, key).Scan(&holderID, &status)
+		switch {
+		case IsNotFoundError(err):
+		case err != nil:
+			return fmt.Errorf("failed to look up unique key %q: %w", key, err)
+		case onConflict == queue.UniqueConflictSkip:
+			resultID = holderID
+			return nil
+		case onConflict == queue.UniqueConflictError:
+			return fmt.Errorf("%w: %s", queue.ErrDuplicateTask, key)
+		case status == string(queue.TaskStatusPending):
+			if _, err := tx.Exec(ctx, cancelQuery, holderID); err != nil {
+				return fmt.Errorf("failed to cancel task %s: %w", holderID, err)
+			}
+		}
+
+		return insertTask(ctx, tx, task)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return resultID, nil
+}
+
+// ClaimTask implements queue.WorkerRepository.
+// Picks the highest priority due task from the given queues, earliest scheduled first,
+// skipping rows already locked by concurrent transactions.
+func (s *QueueStorage) ClaimTask(ctx context.Context, workerID uuid.UUID, queues []string, lockDuration time.Duration) (*queue.Task, error) {
+	return s.claimTask(ctx, workerID, queues, lockDuration, `priority DESC, scheduled_at ASC`)
+}
+
+// ClaimTaskInOrder implements queue.OrderedClaimRepository.
+// Picks the due task of the first queue in the list that has one, by priority within the queue.
+func (s *QueueStorage) ClaimTaskInOrder(ctx context.Context, workerID uuid.UUID, queues []string, lockDuration time.Duration) (*queue.Task, error) {
+	return s.claimTask(ctx, workerID, queues, lockDuration, `array_position($2::text[], queue), priority DESC, scheduled_at ASC`)
+}
+
+// claimTask locks the first due task of the queues in the given order for the worker.
+func (s *QueueStorage) claimTask(ctx context.Context, workerID uuid.UUID, queues []string, lockDuration time.Duration, orderBy string) (*queue.Task, error) {
+	if err := s.releaseExpiredLocks(ctx); err != nil {
+		return nil, err
+	}
+
+	q := `UPDATE queue_tasks
+		SET status = 'processing',
+			locked_until = NOW() + make_interval(secs => $3),
+			locked_by = $1
+		WHERE id = (
+			SELECT id FROM queue_tasks
+			WHERE status = 'pending'
+				AND queue = ANY($2)
+				AND scheduled_at <= NOW()
+				AND NOT EXISTS (SELECT 1 FROM queue_paused_queues p WHERE p.queue = queue_tasks.queue)
+			ORDER BY ` + orderBy + `
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + taskColumns
+
+	task, err := scanTask(s.pool.QueryRow(ctx, q, workerID, queues, lockDuration.Seconds()))
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, queue.ErrNoTaskToClaim
+		}
+		return nil, fmt.Errorf("failed to claim task: %w", err)
+	}
+
+	return task, nil
+}
+
+// CompleteTask implements queue.WorkerRepository.
+func (s *QueueStorage) CompleteTask(ctx context.Context, taskID uuid.UUID) error {
+	const q = `UPDATE queue_tasks
+		SET status = 'completed', processed_at = NOW(), locked_until = NULL, locked_by = NULL
+		WHERE id = $1 AND status = 'processing'`
+
+	tag, err := s.pool.Exec(ctx, q, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to complete task %s: %w", taskID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	}
+
+	return nil
+}
+
+// CompleteTaskWithResult implements queue.ResultRepository.
+func (s *QueueStorage) CompleteTaskWithResult(ctx context.Context, taskID uuid.UUID, result []byte) error {
+	const q = `UPDATE queue_tasks
+		SET status = 'completed', processed_at = NOW(), locked_until = NULL, locked_by = NULL,
+			result = $2
+		WHERE id = $1 AND status = 'processing'`
+
+	tag, err := s.pool.Exec(ctx, q, taskID, result)
+	if err != nil {
+		return fmt.Errorf("failed to complete task %s: %w", taskID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	}
+
+	return nil
+}
+
+// FailTask implements queue.WorkerRepository.
+// Increments the retry count and either reschedules the task at retryAt
+// or marks it failed when retryAt is nil.
+func (s *QueueStorage) FailTask(ctx context.Context, taskID uuid.UUID, errorMsg string, retryAt *time.Time) error {
+	const q = `UPDATE queue_tasks
+		SET retry_count = retry_count + 1,
+			error = $2,
+			locked_until = NULL,
+			locked_by = NULL,
+			status = CASE WHEN $3::timestamptz IS NULL THEN 'failed' ELSE 'pending' END,
+			scheduled_at = COALESCE($3::timestamptz, scheduled_at)
+		WHERE id = $1 AND status = 'processing'`
+
+	tag, err := s.pool.Exec(ctx, q, taskID, errorMsg, retryAt)
+	if err != nil {
+		return fmt.Errorf("failed to fail task %s: %w", taskID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	}
+
+	return nil
+}
+
+// ReleaseTask implements queue.ReleaseRepository.
+// Returns the task to pending at scheduledAt without incrementing its retry count.
+func (s *QueueStorage) ReleaseTask(ctx context.Context, taskID uuid.UUID, scheduledAt time.Time) error {
+	const q = `UPDATE queue_tasks
+		SET status = 'pending', scheduled_at = $2, locked_until = NULL, locked_by = NULL
+		WHERE id = $1 AND status = 'processing'`
+
+	tag, err := s.pool.Exec(ctx, q, taskID, scheduledAt)
+	if err != nil {
+		return fmt.Errorf("failed to release task %s: %w", taskID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	}
+
+	return nil
+}
+
+// MoveToDLQ implements queue.WorkerRepository.
+// The task row is deleted and copied into queue_tasks_dlq in a single statement.
+func (s *QueueStorage) MoveToDLQ(ctx context.Context, taskID uuid.UUID) error {
+	const q = `WITH moved AS (
+			DELETE FROM queue_tasks WHERE id = $1
+			RETURNING id, queue, task_type, task_name, payload, priority, error, retry_count,
+				max_retries, backoff, batch_id, next
+		)
+		INSERT INTO queue_tasks_dlq (id, task_id, queue, task_type, task_name, payload, priority,
+			error, retry_count, max_retries, backoff, batch_id, next, failed_at, created_at)
+		SELECT $2, id, queue, task_type, task_name, payload, priority,
+			COALESCE(error, ''), retry_count, max_retries, backoff, batch_id, next, NOW(), NOW()
+		FROM moved`
+
+	tag, err := s.pool.Exec(ctx, q, taskID, uuid.New())
+	if err != nil {
+		return fmt.Errorf("failed to move task %s to DLQ: %w", taskID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	return nil
+}
+
+// ExtendLock implements queue.WorkerRepository.
+func (s *QueueStorage) ExtendLock(ctx context.Context, taskID uuid.UUID, duration time.Duration) error {
+	const q = `UPDATE queue_tasks
+		SET locked_until = NOW() + make_interval(secs => $2)
+		WHERE id = $1 AND status = 'processing'`
+
+	tag, err := s.pool.Exec(ctx, q, taskID, duration.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to extend lock for task %s: %w", taskID, err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	}
+
+	return nil
+}
+
+// GetPendingTaskByName implements queue.SchedulerRepository.
+// Returns nil without error when no pending task with the given name exists.
+func (s *QueueStorage) GetPendingTaskByName(ctx context.Context, taskName string) (*queue.Task, error) {
+	const q = `SELECT ` + taskColumns + ` FROM queue_tasks
+		WHERE task_name = $1 AND status = 'pending'
+		ORDER BY scheduled_at ASC
+		LIMIT 1`
+
+	task, err := scanTask(s.pool.QueryRow(ctx, q, taskName))
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pending task %q: %w", taskName, err)
+	}
+
+	return task, nil
+}
+
+// ListTasks implements queue.QueueInspector.
+func (s *QueueStorage) ListTasks(ctx context.Context, filter queue.TaskFilter) ([]*queue.Task, error) {
+	const q = `SELECT ` + taskColumns + ` FROM queue_tasks
+		WHERE ($1 = '' OR queue = $1) AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id
+		LIMIT $3 OFFSET $4`
+
+	rows, err := s.pool.Query(ctx, q, filter.Queue, string(filter.Status),
+		queue.NormalizeLimit(filter.Limit), max(filter.Offset, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	defer rows.Close()
+
+	tasks := make([]*queue.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// CountTasks implements queue.QueueInspector.
+func (s *QueueStorage) CountTasks(ctx context.Context, filter queue.TaskFilter) (int64, error) {
+	const q = `SELECT COUNT(*) FROM queue_tasks
+		WHERE ($1 = '' OR queue = $1) AND ($2 = '' OR status = $2)`
+
+	var count int64
+	if err := s.pool.QueryRow(ctx, q, filter.Queue, string(filter.Status)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetTask implements queue.QueueInspector.
+func (s *QueueStorage) GetTask(ctx context.Context, taskID uuid.UUID) (*queue.Task, error) {
+	const q = `SELECT ` + taskColumns + ` FROM queue_tasks WHERE id = $1`
+
+	task, err := scanTask(s.pool.QueryRow(ctx, q, taskID))
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, fmt.Errorf("%w: %s", queue.ErrTaskNotFound, taskID)
+		}
+		return nil, fmt.Errorf("failed to get task %s: %w", taskID, err)
+	}
+
+	return task, nil
+}
+
+// CancelTask implements queue.QueueInspector.
+func (s *QueueStorage) CancelTask(ctx context.Context, taskID uuid.UUID) error {
+	const q = `UPDATE queue_tasks
+		SET status = 'cancelled', processed_at = NOW()
+		WHERE id = $1 AND status = 'pending'`
+
+	tag, err := s.pool.Exec(ctx, q, taskID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel task %s: %w", taskID, err)
+	}
+	if tag.RowsAffected() > 0 {
+		return nil
+	}
+
+	// Distinguish a missing task from one that is no longer pending
+	task, err := s.GetTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: task %s is %s", queue.ErrTaskNotPending, taskID, task.Status)
+}
+
+// ListDLQ implements queue.QueueInspector.
+func (s *QueueStorage) ListDLQ(ctx context.Context, filter queue.DLQFilter) ([]*queue.TasksDlq, error) {
+	const q = `SELECT ` + dlqColumns + ` FROM queue_tasks_dlq
+		WHERE $1 = '' OR queue = $1
+		ORDER BY failed_at DESC, id
+		LIMIT $2 OFFSET $3`
+
+	rows, err := s.pool.Query(ctx, q, filter.Queue,
+		queue.NormalizeLimit(filter.Limit), max(filter.Offset, 0))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DLQ entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*queue.TasksDlq, 0)
+	for rows.Next() {
+		entry, err := scanDLQEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan DLQ entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list DLQ entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// RequeueDLQ implements queue.QueueInspector.
+// The DLQ entry is deleted and inserted back into queue_tasks as a pending task in a single statement.
+// An unfinished batch of the task gets its failure count decremented so the task is pending
+// in it again; tasks of finished or deleted batches are requeued without a batch.
+func (s *QueueStorage) RequeueDLQ(ctx context.Context, dlqID uuid.UUID) (*queue.Task, error) {
+	const q = `WITH entry AS (
+			DELETE FROM queue_tasks_dlq WHERE id = $1
+			RETURNING task_id, queue, task_type, task_name, payload, priority, max_retries,
+				backoff, batch_id, next
+		), reopened AS (
+			UPDATE queue_task_batches SET failed = failed - 1
+			WHERE id = (SELECT batch_id FROM entry) AND finished_at IS NULL AND failed > 0
+			RETURNING id
+		)
+		INSERT INTO queue_tasks (id, queue, task_type, task_name, payload, status, priority,
+			retry_count, max_retries, backoff, batch_id, next, scheduled_at, created_at)
+		SELECT task_id, queue, task_type, task_name, payload, 'pending', priority,
+			0, max_retries, backoff, (SELECT id FROM reopened), next, NOW(), NOW()
+		FROM entry
+		RETURNING ` + taskColumns
+
+	task, err := scanTask(s.pool.QueryRow(ctx, q, dlqID))
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, fmt.Errorf("%w: %s", queue.ErrDLQEntryNotFound, dlqID)
+		}
+		if IsDuplicateKeyError(err) {
+			return nil, fmt.Errorf("task for DLQ entry %s already exists", dlqID)
+		}
+		return nil, fmt.Errorf("failed to requeue DLQ entry %s: %w", dlqID, err)
+	}
+
+	return task, nil
+}
+
+// PurgeDLQ implements queue.QueueInspector.
+func (s *QueueStorage) PurgeDLQ(ctx context.Context, queueName string) (int64, error) {
+	const q = `DELETE FROM queue_tasks_dlq WHERE $1 = '' OR queue = $1`
+
+	tag, err := s.pool.Exec(ctx, q, queueName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge DLQ: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// PauseQueue implements queue.QueueInspector.
+func (s *QueueStorage) PauseQueue(ctx context.Context, queueName string) error {
+	const q = `INSERT INTO queue_paused_queues (queue) VALUES ($1) ON CONFLICT (queue) DO NOTHING`
+
+	if _, err := s.pool.Exec(ctx, q, queueName); err != nil {
+		return fmt.Errorf("failed to pause queue %q: %w", queueName, err)
+	}
+
+	return nil
+}
+
+// ResumeQueue implements queue.QueueInspector.
+func (s *QueueStorage) ResumeQueue(ctx context.Context, queueName string) error {
+	const q = `DELETE FROM queue_paused_queues WHERE queue = $1`
+
+	if _, err := s.pool.Exec(ctx, q, queueName); err != nil {
+		return fmt.Errorf("failed to resume queue %q: %w", queueName, err)
+	}
+
+	return nil
+}
+
+// PausedQueues implements queue.QueueInspector.
+func (s *QueueStorage) PausedQueues(ctx context.Context) ([]string, error) {
+	const q = `SELECT queue FROM queue_paused_queues ORDER BY queue`
+
+	rows, err := s.pool.Query(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list paused queues: %w", err)
+	}
+
+	queues, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("failed to list paused queues: %w", err)
+	}
+
+	return queues, nil
+}
+
+// CreateBatch implements queue.WorkflowRepository.
+// The batch row and all of its tasks are inserted in one transaction, or in a
+// savepoint of the transaction stored in ctx with WithTx.
+func (s *QueueStorage) CreateBatch(ctx context.Context, batch *queue.Batch, tasks []*queue.Task) error {
+	if batch == nil {
+		return errors.New("batch cannot be nil")
+	}
+
+	const q = `INSERT INTO queue_task_batches (id, total, succeeded, failed, on_complete, on_failure, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	return pgx.BeginFunc(ctx, s.conn(ctx), func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, q, batch.ID, batch.Total, batch.Succeeded, batch.Failed,
+			batch.OnComplete, batch.OnFailure, batch.CreatedAt)
+		if err != nil {
+			if IsDuplicateKeyError(err) {
+				return fmt.Errorf("batch with ID %s already exists", batch.ID)
+			}
+			return fmt.Errorf("failed to insert batch %s: %w", batch.ID, err)
+		}
+
+		for _, task := range tasks {
+			if task == nil {
+				return errors.New("task cannot be nil")
+			}
+			if err := insertTask(ctx, tx, task); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetBatch implements queue.WorkflowRepository.
+func (s *QueueStorage) GetBatch(ctx context.Context, batchID uuid.UUID) (*queue.Batch, error) {
+	const q = `SELECT ` + batchColumns + ` FROM queue_task_batches WHERE id = $1`
+
+	batch, err := scanBatch(s.pool.QueryRow(ctx, q, batchID))
+	if err != nil {
+		if IsNotFoundError(err) {
+			return nil, fmt.Errorf("%w: %s", queue.ErrBatchNotFound, batchID)
+		}
+		return nil, fmt.Errorf("failed to get batch %s: %w", batchID, err)
+	}
+
+	return batch, nil
+}
+
+// FinishBatchTask implements queue.WorkflowRepository.
+// The batch row is locked while its counters are updated, so exactly one call
+// observes the last task finishing and creates the callback task.
+func (s *QueueStorage) FinishBatchTask(ctx context.Context, batchID uuid.UUID, succeeded bool) (*queue.Batch, error) {
+	const (
+		selectQuery = `SELECT ` + batchColumns + ` FROM queue_task_batches WHERE id = $1 FOR UPDATE`
+		updateQuery = `UPDATE queue_task_batches
+			SET succeeded = $2, failed = $3, finished_at = $4
+			WHERE id = $1`
+	)
+
+	var batch *queue.Batch
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		var err error
+		batch, err = scanBatch(tx.QueryRow(ctx, selectQuery, batchID))
+		if err != nil {
+			if IsNotFoundError(err) {
+				return fmt.Errorf("%w: %s", queue.ErrBatchNotFound, batchID)
+			}
+			return fmt.Errorf("failed to get batch %s: %w", batchID, err)
+		}
+		if batch.Finished() {
+			return fmt.Errorf("batch %s is already finished", batchID)
+		}
+
+		if succeeded {
+			batch.Succeeded++
+		} else {
+			batch.Failed++
+		}
+
+		var callback *queue.Task
+		if batch.Pending() == 0 {
+			now := time.Now()
+			batch.FinishedAt = &now
+			callback = batch.CallbackTask(now)
+		}
+
+		if _, err := tx.Exec(ctx, updateQuery, batchID, batch.Succeeded, batch.Failed, batch.FinishedAt); err != nil {
+			return fmt.Errorf("failed to update batch %s: %w", batchID, err)
+		}
+		if callback != nil {
+			return insertTask(ctx, tx, callback)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// CompleteTaskWithNext implements queue.WorkflowRepository.
+// Completing the task and creating the next chain step share one transaction.
+func (s *QueueStorage) CompleteTaskWithNext(ctx context.Context, taskID uuid.UUID, next *queue.Task) error {
+	if next == nil {
+		return errors.New("next task cannot be nil")
+	}
+
+	const q = `UPDATE queue_tasks
+		SET status = 'completed', processed_at = NOW(), locked_until = NULL, locked_by = NULL
+		WHERE id = $1 AND status = 'processing'`
+
+	return pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, q, taskID)
+		if err != nil {
+			return fmt.Errorf("failed to complete task %s: %w", taskID, err)
+		}
+		if tag.RowsAffected() == 0 {
+			return fmt.Errorf("task %s not found or not in processing state", taskID)
+		}
+
+		return insertTask(ctx, tx, next)
+	})
+}
+
+// execer is the subset of pgxpool.Pool and pgx.Tx used to write tasks.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// dbConn is the subset of pgxpool.Pool and pgx.Tx used to create tasks.
+// Begin on a pgx.Tx starts a savepoint.
+type dbConn interface {
+	execer
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// conn returns the transaction stored in ctx with WithTx, or the pool.
+func (s *QueueStorage) conn(ctx context.Context) dbConn {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return s.pool
+}
+
+// insertTask inserts a task row using the pool or a transaction.
+func insertTask(ctx context.Context, db execer, task *queue.Task) error {
+	const q = `INSERT INTO queue_tasks (id, queue, task_type, task_name, payload, status, priority,
+		retry_count, max_retries, backoff, unique_key, unique_until, batch_id, next,
+		scheduled_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+
+	// Store tasks without chain steps as NULL rather than a JSON null
+	var next any
+	if len(task.Next) > 0 {
+		next = task.Next
+	}
+
+	_, err := db.Exec(ctx, q,
+		task.ID, task.Queue, string(task.TaskType), task.TaskName, task.Payload,
+		string(task.Status), int16(task.Priority), int16(task.RetryCount),
+		int16(task.MaxRetries), task.Backoff, task.UniqueKey, task.UniqueUntil,
+		task.BatchID, next, task.ScheduledAt, task.CreatedAt,
+	)
+	if err != nil {
+		if IsDuplicateKeyError(err) {
+			return fmt.Errorf("task with ID %s already exists", task.ID)
+		}
+		return fmt.Errorf("failed to insert task %s: %w", task.ID, err)
+	}
+
+	return nil
+}
+
+// releaseExpiredLocks resets tasks whose worker lock has expired back to pending.
+// Equivalent to MemoryStorage lock expiration: without it, tasks claimed by crashed
+// workers would stay in processing state forever. Runs before every claim so no
+// background goroutine is required; the retry count is preserved.
+func (s *QueueStorage) releaseExpiredLocks(ctx context.Context) error {
+	const q = `UPDATE queue_tasks
+		SET status = 'pending', locked_until = NULL, locked_by = NULL
+		WHERE status = 'processing' AND locked_until < NOW()`
+
+	if _, err := s.pool.Exec(ctx, q); err != nil {
+		return fmt.Errorf("failed to release expired task locks: %w", err)
+	}
+
+	return nil
+}
+
+// scanTask scans a row selected with taskColumns into a queue.Task.
+func scanTask(row pgx.Row) (*queue.Task, error) {
+	var (
+		task                             queue.Task
+		taskType, status                 string
+		priority, retryCount, maxRetries int16
+	)
+
+	err := row.Scan(
+		&task.ID, &task.Queue, &taskType, &task.TaskName, &task.Payload, &status,
+		&priority, &retryCount, &maxRetries, &task.Backoff, &task.UniqueKey, &task.UniqueUntil,
+		&task.BatchID, &task.Next, &task.ScheduledAt, &task.LockedUntil,
+		&task.LockedBy, &task.ProcessedAt, &task.Error, &task.Result, &task.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	task.TaskType = queue.TaskType(taskType)
+	task.Status = queue.TaskStatus(status)
+	task.Priority = queue.Priority(priority)
+	task.RetryCount = int8(retryCount)
+	task.MaxRetries = int8(maxRetries)
+
+	return &task, nil
+}
+
+// scanDLQEntry scans a row selected with dlqColumns into a queue.TasksDlq.
+func scanDLQEntry(row pgx.Row) (*queue.TasksDlq, error) {
+	var (
+		entry                            queue.TasksDlq
+		taskType                         string
+		priority, retryCount, maxRetries int16
+	)
+
+	err := row.Scan(
+		&entry.ID, &entry.TaskID, &entry.Queue, &taskType, &entry.TaskName, &entry.Payload,
+		&priority, &entry.Error, &retryCount, &maxRetries, &entry.Backoff, &entry.BatchID,
+		&entry.Next, &entry.FailedAt, &entry.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.TaskType = queue.TaskType(taskType)
+	entry.Priority = queue.Priority(priority)
+	entry.RetryCount = int8(retryCount)
+	entry.MaxRetries = int8(maxRetries)
+
+	return &entry, nil
+}
+
+// scanBatch scans a row selected with batchColumns into a queue.Batch.
+func scanBatch(row pgx.Row) (*queue.Batch, error) {
+	var batch queue.Batch
+
+	err := row.Scan(
+		&batch.ID, &batch.Total, &batch.Succeeded, &batch.Failed,
+		&batch.OnComplete, &batch.OnFailure, &batch.CreatedAt, &batch.FinishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &batch, nil
+}
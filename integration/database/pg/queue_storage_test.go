@@ -0,0 +1,472 @@
+package pg_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+	"github.com/dmitrymomot/foundation/integration/database/pg"
+)
+
+var (
+	migrateOnce sync.Once
+	migrateErr  error
+)
+
+// newTestPool connects to the database of TEST_PG_CONN_URL and applies the queue
+// migrations once. The test is skipped when the variable is unset.
+func newTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("TEST_PG_CONN_URL")
+	if url == "" {
+		t.Skip("TEST_PG_CONN_URL is not set")
+	}
+
+	ctx := context.Background()
+	pool, err := pgxpool.New(ctx, url)
+	require.NoError(t, err)
+	t.Cleanup(pool.Close)
+
+	migrateOnce.Do(func() {
+		migrateErr = pg.MigrateQueue(ctx, pool, slog.New(slog.DiscardHandler))
+	})
+	require.NoError(t, migrateErr)
+
+	return pool
+}
+
+// newTestTask returns a pending task due now. Tests use a queue name of their own,
+// so they can share the database and run in parallel.
+func newTestTask(queueName string) *queue.Task {
+	now := time.Now()
+	return &queue.Task{
+		ID:          uuid.New(),
+		Queue:       queueName,
+		TaskType:    queue.TaskTypeOneTime,
+		TaskName:    "test_task",
+		Payload:     []byte(`{"n":1}`),
+		Status:      queue.TaskStatusPending,
+		Priority:    queue.PriorityDefault,
+		MaxRetries:  3,
+		ScheduledAt: now,
+		CreatedAt:   now,
+	}
+}
+
+func TestQueueStorage_ClaimTask(t *testing.T) {
+	t.Parallel()
+
+	storage := pg.NewQueueStorage(newTestPool(t))
+	ctx := context.Background()
+	queueName := "claim-" + uuid.NewString()
+	workerID := uuid.New()
+
+	low := newTestTask(queueName)
+	low.Priority = queue.PriorityLow
+	high := newTestTask(queueName)
+	high.Priority = queue.PriorityHigh
+	delayed := newTestTask(queueName)
+	delayed.Priority = queue.PriorityMax
+	delayed.ScheduledAt = time.Now().Add(time.Hour)
+	for _, task := range []*queue.Task{low, high, delayed} {
+		require.NoError(t, storage.CreateTask(ctx, task))
+	}
+
+	require.NoError(t, storage.PauseQueue(ctx, queueName))
+	_, err := storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+	require.NoError(t, storage.ResumeQueue(ctx, queueName))
+
+	claimed, err := storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, high.ID, claimed.ID)
+	assert.Equal(t, queue.TaskStatusProcessing, claimed.Status)
+	require.NotNil(t, claimed.LockedBy)
+	assert.Equal(t, workerID, *claimed.LockedBy)
+
+	claimed, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, low.ID, claimed.ID)
+
+	_, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+
+	require.NoError(t, storage.CompleteTask(ctx, high.ID))
+	completed, err := storage.GetTask(ctx, high.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.TaskStatusCompleted, completed.Status)
+	assert.Nil(t, completed.LockedBy)
+}
+
+func TestQueueStorage_ExpiredLocks(t *testing.T) {
+	t.Parallel()
+
+	storage := pg.NewQueueStorage(newTestPool(t))
+	ctx := context.Background()
+	queueName := "locks-" + uuid.NewString()
+	crashed := uuid.New()
+
+	extended := newTestTask(queueName)
+	extended.Priority = queue.PriorityHigh
+	abandoned := newTestTask(queueName)
+	for _, task := range []*queue.Task{extended, abandoned} {
+		require.NoError(t, storage.CreateTask(ctx, task))
+	}
+
+	claimed, err := storage.ClaimTask(ctx, crashed, []string{queueName}, time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, extended.ID, claimed.ID)
+	require.NoError(t, storage.ExtendLock(ctx, extended.ID, time.Minute))
+
+	claimed, err = storage.ClaimTask(ctx, crashed, []string{queueName}, time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, abandoned.ID, claimed.ID)
+
+	time.Sleep(50 * time.Millisecond)
+
+	// The expired lock is released by the next claim, the extended one is kept
+	workerID := uuid.New()
+	claimed, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, abandoned.ID, claimed.ID)
+	require.NotNil(t, claimed.LockedBy)
+	assert.Equal(t, workerID, *claimed.LockedBy)
+	assert.Zero(t, claimed.RetryCount)
+
+	_, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+}
+
+func TestQueueStorage_FailTask(t *testing.T) {
+	t.Parallel()
+
+	storage := pg.NewQueueStorage(newTestPool(t))
+	ctx := context.Background()
+	queueName := "fail-" + uuid.NewString()
+	workerID := uuid.New()
+
+	task := newTestTask(queueName)
+	require.NoError(t, storage.CreateTask(ctx, task))
+
+	claimed, err := storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+
+	retryAt := time.Now().Add(-time.Second)
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "temporary", &retryAt))
+
+	retried, err := storage.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.TaskStatusPending, retried.Status)
+	assert.EqualValues(t, 1, retried.RetryCount)
+	require.NotNil(t, retried.Error)
+	assert.Equal(t, "temporary", *retried.Error)
+
+	claimed, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, claimed.ID)
+
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "permanent", nil))
+
+	failed, err := storage.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.TaskStatusFailed, failed.Status)
+	assert.EqualValues(t, 2, failed.RetryCount)
+
+	assert.Error(t, storage.FailTask(ctx, task.ID, "not processing", nil))
+}
+
+func TestQueueStorage_CompleteTaskWithResult(t *testing.T) {
+	t.Parallel()
+
+	storage := pg.NewQueueStorage(newTestPool(t))
+	ctx := context.Background()
+	queueName := "result-" + uuid.NewString()
+
+	task := newTestTask(queueName)
+	require.NoError(t, storage.CreateTask(ctx, task))
+	claimed, err := storage.ClaimTask(ctx, uuid.New(), []string{queueName}, time.Minute)
+	require.NoError(t, err)
+
+	// Encrypted results must keep their exact bytes, so the envelope is still recognized
+	result := []byte(`{"encrypted":"queue/v1","key_id":"k1","data":"c2VjcmV0"}`)
+	require.NoError(t, storage.CompleteTaskWithResult(ctx, claimed.ID, result))
+
+	completed, err := storage.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.TaskStatusCompleted, completed.Status)
+	assert.Equal(t, result, completed.Result)
+	assert.True(t, queue.IsEncryptedPayload(completed.Result))
+}
+
+func TestQueueStorage_DLQ(t *testing.T) {
+	t.Parallel()
+
+	storage := pg.NewQueueStorage(newTestPool(t))
+	ctx := context.Background()
+	queueName := "dlq-" + uuid.NewString()
+	workerID := uuid.New()
+
+	backoff := queue.FixedBackoff(time.Second)
+	task := newTestTask(queueName)
+	task.Backoff = &backoff
+	task.Next = []queue.WorkflowStep{{TaskName: "next_step", Queue: queueName, Priority: queue.PriorityDefault}}
+	require.NoError(t, storage.CreateTask(ctx, task))
+
+	claimed, err := storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "boom", nil))
+	require.NoError(t, storage.MoveToDLQ(ctx, claimed.ID))
+
+	_, err = storage.GetTask(ctx, task.ID)
+	assert.ErrorIs(t, err, queue.ErrTaskNotFound)
+
+	entries, err := storage.ListDLQ(ctx, queue.DLQFilter{Queue: queueName})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, task.ID, entry.TaskID)
+	assert.Equal(t, "boom", entry.Error)
+	assert.EqualValues(t, 1, entry.RetryCount)
+	assert.Equal(t, task.Payload, entry.Payload)
+	assert.Equal(t, task.Backoff, entry.Backoff)
+	assert.Equal(t, task.Next, entry.Next)
+
+	requeued, err := storage.RequeueDLQ(ctx, entry.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, requeued.ID)
+	assert.Equal(t, queue.TaskStatusPending, requeued.Status)
+	assert.Zero(t, requeued.RetryCount)
+	assert.Equal(t, task.Backoff, requeued.Backoff)
+	assert.Equal(t, task.Next, requeued.Next)
+
+	_, err = storage.RequeueDLQ(ctx, entry.ID)
+	assert.ErrorIs(t, err, queue.ErrDLQEntryNotFound)
+
+	claimed, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, claimed.ID)
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "boom again", nil))
+	require.NoError(t, storage.MoveToDLQ(ctx, claimed.ID))
+
+	purged, err := storage.PurgeDLQ(ctx, queueName)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, purged)
+
+	entries, err = storage.ListDLQ(ctx, queue.DLQFilter{Queue: queueName})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestQueueStorage_FinishBatchTask(t *testing.T) {
+	t.Parallel()
+
+	storage := pg.NewQueueStorage(newTestPool(t))
+	ctx := context.Background()
+	queueName := "batch-" + uuid.NewString()
+
+	onComplete := newTestTask(queueName)
+	onComplete.TaskName = "on_complete_" + uuid.NewString()
+	batch := &queue.Batch{ID: uuid.New(), Total: 2, OnComplete: onComplete, CreatedAt: time.Now()}
+	tasks := []*queue.Task{newTestTask(queueName), newTestTask(queueName)}
+	for _, task := range tasks {
+		task.BatchID = &batch.ID
+	}
+	require.NoError(t, storage.CreateBatch(ctx, batch, tasks))
+
+	current, err := storage.FinishBatchTask(ctx, batch.ID, true)
+	require.NoError(t, err)
+	assert.False(t, current.Finished())
+
+	callback, err := storage.GetPendingTaskByName(ctx, onComplete.TaskName)
+	require.NoError(t, err)
+	assert.Nil(t, callback, "callback must wait for the last task")
+
+	current, err = storage.FinishBatchTask(ctx, batch.ID, true)
+	require.NoError(t, err)
+	assert.True(t, current.Finished())
+	assert.Equal(t, 2, current.Succeeded)
+
+	_, err = storage.FinishBatchTask(ctx, batch.ID, true)
+	assert.Error(t, err)
+
+	callback, err = storage.GetPendingTaskByName(ctx, onComplete.TaskName)
+	require.NoError(t, err)
+	require.NotNil(t, callback)
+	assert.Equal(t, onComplete.ID, callback.ID)
+
+	_, err = storage.FinishBatchTask(ctx, uuid.New(), true)
+	assert.ErrorIs(t, err, queue.ErrBatchNotFound)
+}
+
+func TestQueueStorage_RequeueDLQ_Batch(t *testing.T) {
+	t.Parallel()
+
+	storage := pg.NewQueueStorage(newTestPool(t))
+	ctx := context.Background()
+	queueName := "dlq-batch-" + uuid.NewString()
+
+	batch := &queue.Batch{ID: uuid.New(), Total: 2, CreatedAt: time.Now()}
+	tasks := []*queue.Task{newTestTask(queueName), newTestTask(queueName)}
+	for _, task := range tasks {
+		task.BatchID = &batch.ID
+	}
+	require.NoError(t, storage.CreateBatch(ctx, batch, tasks))
+
+	claimed, err := storage.ClaimTask(ctx, uuid.New(), []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "boom", nil))
+	require.NoError(t, storage.MoveToDLQ(ctx, claimed.ID))
+	_, err = storage.FinishBatchTask(ctx, batch.ID, false)
+	require.NoError(t, err)
+
+	entries, err := storage.ListDLQ(ctx, queue.DLQFilter{Queue: queueName})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, &batch.ID, entries[0].BatchID)
+
+	requeued, err := storage.RequeueDLQ(ctx, entries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, &batch.ID, requeued.BatchID)
+
+	current, err := storage.GetBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.Zero(t, current.Failed)
+	assert.Equal(t, 2, current.Pending())
+
+	stored, err := storage.GetTask(ctx, requeued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &batch.ID, stored.BatchID)
+}
+
+func TestQueueStorage_CreateUniqueTask(t *testing.T) {
+	t.Parallel()
+
+	storage := pg.NewQueueStorage(newTestPool(t))
+	ctx := context.Background()
+	queueName := "unique-" + uuid.NewString()
+	key := "report:" + uuid.NewString()
+
+	uniqueTask := func() *queue.Task {
+		task := newTestTask(queueName)
+		task.UniqueKey = &key
+		return task
+	}
+
+	holder := uniqueTask()
+	id, err := storage.CreateUniqueTask(ctx, holder, queue.UniqueConflictSkip)
+	require.NoError(t, err)
+	assert.Equal(t, holder.ID, id)
+
+	t.Run("skip returns the holder", func(t *testing.T) {
+		id, err := storage.CreateUniqueTask(ctx, uniqueTask(), queue.UniqueConflictSkip)
+		require.NoError(t, err)
+		assert.Equal(t, holder.ID, id)
+	})
+
+	t.Run("error rejects the task", func(t *testing.T) {
+		_, err := storage.CreateUniqueTask(ctx, uniqueTask(), queue.UniqueConflictError)
+		assert.ErrorIs(t, err, queue.ErrDuplicateTask)
+	})
+
+	t.Run("replace cancels the pending holder", func(t *testing.T) {
+		replacement := uniqueTask()
+		id, err := storage.CreateUniqueTask(ctx, replacement, queue.UniqueConflictReplace)
+		require.NoError(t, err)
+		assert.Equal(t, replacement.ID, id)
+
+		cancelled, err := storage.GetTask(ctx, holder.ID)
+		require.NoError(t, err)
+		assert.Equal(t, queue.TaskStatusCancelled, cancelled.Status)
+
+		count, err := storage.CountTasks(ctx, queue.TaskFilter{Queue: queueName, Status: queue.TaskStatusPending})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+	})
+}
+
+func TestQueueStorage_WithTx(t *testing.T) {
+	t.Parallel()
+
+	pool := newTestPool(t)
+	storage := pg.NewQueueStorage(pool)
+	ctx := context.Background()
+	queueName := "tx-" + uuid.NewString()
+
+	t.Run("rolled back transaction drops the task", func(t *testing.T) {
+		tx, err := pool.Begin(ctx)
+		require.NoError(t, err)
+
+		task := newTestTask(queueName)
+		require.NoError(t, storage.CreateTask(pg.WithTx(ctx, tx), task))
+
+		key := "tx:" + uuid.NewString()
+		unique := newTestTask(queueName)
+		unique.UniqueKey = &key
+		_, err = storage.CreateUniqueTask(pg.WithTx(ctx, tx), unique, queue.UniqueConflictError)
+		require.NoError(t, err)
+
+		require.NoError(t, tx.Rollback(ctx))
+
+		_, err = storage.GetTask(ctx, task.ID)
+		assert.ErrorIs(t, err, queue.ErrTaskNotFound)
+		_, err = storage.GetTask(ctx, unique.ID)
+		assert.ErrorIs(t, err, queue.ErrTaskNotFound)
+	})
+
+	t.Run("committed transaction keeps the task", func(t *testing.T) {
+		tx, err := pool.Begin(ctx)
+		require.NoError(t, err)
+
+		task := newTestTask(queueName)
+		require.NoError(t, storage.CreateTask(pg.WithTx(ctx, tx), task))
+
+		_, err = storage.GetTask(ctx, task.ID)
+		assert.ErrorIs(t, err, queue.ErrTaskNotFound, "task must not be visible before commit")
+
+		require.NoError(t, tx.Commit(ctx))
+
+		created, err := storage.GetTask(ctx, task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, queue.TaskStatusPending, created.Status)
+	})
+}
+
+func TestSchedulerLock(t *testing.T) {
+	t.Parallel()
+
+	pool := newTestPool(t)
+	ctx := context.Background()
+	name := "test-" + uuid.NewString()
+
+	leader := pg.NewSchedulerLock(pool, name)
+	follower := pg.NewSchedulerLock(pool, name)
+
+	acquired, err := leader.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = leader.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired, "leader keeps its lock")
+
+	acquired, err = follower.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	require.NoError(t, leader.Release(ctx))
+
+	acquired, err = follower.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	require.NoError(t, follower.Release(ctx))
+}
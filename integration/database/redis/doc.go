@@ -37,6 +37,34 @@
 //		// Handle Redis health check failure
 //	}
 //
+// # Queue Storage
+//
+// QueueStorage implements the core/queue repository interfaces on Redis for services
+// without PostgreSQL. Pending and delayed tasks live in sorted sets, every state
+// transition runs as a Lua script, failed tasks are pushed to a DLQ list and locks of
+// crashed workers are released back to pending before each claim:
+//
+//	storage := redis.NewQueueStorage(client,
+//		redis.WithQueueKeyPrefix("{jobs}:"),
+//		redis.WithCompletedTaskRetention(24*time.Hour),
+//	)
+//
+//	enqueuer, _ := queue.NewEnqueuer(storage)
+//	worker, _ := queue.NewWorker(storage)
+//	scheduler, _ := queue.NewScheduler(storage)
+//
+// Keep a hash tag in the key prefix when using Redis Cluster so all queue keys map to one slot.
+//
+// SchedulerLock elects a single scheduler among replicas with SET NX. The leader renews
+// the key on every check; if it dies, the key expires and another replica takes over:
+//
+//	scheduler, _ := queue.NewScheduler(storage,
+//		queue.WithLeaderLock(redis.NewSchedulerLock(client, "default",
+//			redis.WithLockKeyPrefix("{jobs}:"),
+//			redis.WithLockTTL(time.Minute),
+//		)),
+//	)
+//
 // # Configuration
 //
 // Config struct supports environment variable mapping:
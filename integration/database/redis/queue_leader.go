@@ -0,0 +1,118 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+var _ queue.LeaderLock = (*SchedulerLock)(nil)
+
+// acquireLeaderScript sets the lock key when it is free and renews it when
+// it is already held by the caller.
+// KEYS: lock key.
+// ARGV: holder token, TTL in ms.
+var acquireLeaderScript = redis.NewScript(`
+local holder = redis.call('GET', KEYS[1])
+if holder == ARGV[1] then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+	return 1
+end
+if not holder then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+	return 1
+end
+return 0
+`)
+
+// releaseLeaderScript deletes the lock key only when it is held by the caller.
+// KEYS: lock key.
+// ARGV: holder token.
+var releaseLeaderScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// SchedulerLock implements queue.LeaderLock with SET NX and a renewed expiry.
+// The leader renews the key on every scheduler check; when it stops renewing,
+// the key expires after the TTL and another instance takes over.
+//
+// Example:
+//
+//	scheduler, err := queue.NewScheduler(storage,
+//		queue.WithCheckInterval(10*time.Second),
+//		queue.WithLeaderLock(redis.NewSchedulerLock(client, "default",
+//			redis.WithLockTTL(30*time.Second))),
+//	)
+type SchedulerLock struct {
+	client redis.UniversalClient
+	prefix string
+	key    string
+	token  string
+	ttl    time.Duration
+}
+
+// SchedulerLockOption configures a SchedulerLock.
+type SchedulerLockOption func(*SchedulerLock)
+
+// WithLockTTL sets how long the lock outlives the last renewal.
+// Must be longer than the scheduler check interval.
+func WithLockTTL(d time.Duration) SchedulerLockOption {
+	return func(l *SchedulerLock) {
+		if d > 0 {
+			l.ttl = d
+		}
+	}
+}
+
+// WithLockKeyPrefix sets the prefix of the lock key, usually the one of the queue storage.
+func WithLockKeyPrefix(prefix string) SchedulerLockOption {
+	return func(l *SchedulerLock) {
+		if prefix != "" {
+			l.prefix = prefix
+		}
+	}
+}
+
+// NewSchedulerLock creates a leader lock identified by name.
+// By default the key is "{queue}:leader:<name>" and expires one minute after the
+// last renewal, twice the default scheduler check interval.
+func NewSchedulerLock(client redis.UniversalClient, name string, opts ...SchedulerLockOption) *SchedulerLock {
+	l := &SchedulerLock{
+		client: client,
+		prefix: "{queue}:",
+		token:  uuid.NewString(),
+		ttl:    time.Minute,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	l.key = l.prefix + "leader:" + name
+	return l
+}
+
+// TryAcquire implements queue.LeaderLock.
+func (l *SchedulerLock) TryAcquire(ctx context.Context) (bool, error) {
+	acquired, err := acquireLeaderScript.Run(ctx, l.client, []string{l.key}, l.token, l.ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire scheduler lock %q: %w", l.key, err)
+	}
+	return acquired == 1, nil
+}
+
+// Release implements queue.LeaderLock.
+func (l *SchedulerLock) Release(ctx context.Context) error {
+	if err := releaseLeaderScript.Run(ctx, l.client, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("failed to release scheduler lock %q: %w", l.key, err)
+	}
+	return nil
+}
@@ -0,0 +1,419 @@
+package redis
+
+import "github.com/redis/go-redis/v9"
+
+// Lua scripts keep every task state transition atomic. Task hashes, sorted sets and
+// the DLQ list share the key prefix, so with a hash-tagged prefix (the default) all
+// keys live in the same cluster slot and scripts remain valid in Redis Cluster.
+//
+// Pending sets are scored by (PriorityMax - priority) * 1e13 + scheduled_at in unix
+// milliseconds, so the lowest score is the highest priority task scheduled first.
+
+// releaseUniqueKeyFunc defines a Lua function deleting the unique key held by a task
+// that is no longer pending or processing. A key taken over by a newer task is kept.
+const releaseUniqueKeyFunc = `
+local function releaseUniqueKey(prefix, taskKey, taskID)
+	local unique = redis.call('HGET', taskKey, 'unique_key')
+	if unique and redis.call('GET', prefix .. 'unique:' .. unique) == taskID then
+		redis.call('DEL', prefix .. 'unique:' .. unique)
+	end
+end
+`
+
+// createTaskScript stores the task hash and indexes it.
+// KEYS: task hash, target sorted set (pending or scheduled), task name set, tasks index.
+// ARGV: task ID, score, created at in unix ms, hash field/value pairs...
+var createTaskScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 4))
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+redis.call('SADD', KEYS[3], ARGV[1])
+redis.call('ZADD', KEYS[4], ARGV[3], ARGV[1])
+return 1
+`)
+
+// createUniqueTaskScript creates a task unless an active task holds the same unique key.
+// A holder is active while pending or processing and before its unique_until time.
+// Returns {1, new ID} when created, {0, holder ID} when skipped, {-1, holder ID} on
+// conflict in error mode, and {-2, ""} when the task ID already exists.
+// KEYS: task hash, target sorted set (pending or scheduled), task name set, tasks index, unique key.
+// ARGV: task ID, score, created at in unix ms, conflict behavior, key prefix, now in unix ms,
+// unique key TTL in ms (0 for none), retention in ms, hash field/value pairs...
+var createUniqueTaskScript = redis.NewScript(`
+local prefix = ARGV[5]
+local holder = redis.call('GET', KEYS[5])
+if holder then
+	local holderKey = prefix .. 'task:' .. holder
+	local f = redis.call('HMGET', holderKey, 'status', 'unique_until', 'queue', 'task_name')
+	local active = (f[1] == 'pending' or f[1] == 'processing')
+		and (not f[2] or tonumber(f[2]) > tonumber(ARGV[6]))
+	if active then
+		if ARGV[4] == 'skip' then
+			return {0, holder}
+		end
+		if ARGV[4] == 'error' then
+			return {-1, holder}
+		end
+		if f[1] == 'pending' then
+			redis.call('ZREM', prefix .. 'pending:' .. f[3], holder)
+			redis.call('ZREM', prefix .. 'scheduled:' .. f[3], holder)
+			redis.call('SREM', prefix .. 'name:' .. f[4], holder)
+			redis.call('HSET', holderKey, 'status', 'cancelled', 'processed_at', ARGV[6])
+			if tonumber(ARGV[8]) > 0 then
+				redis.call('PEXPIRE', holderKey, ARGV[8])
+			end
+		end
+	end
+end
+
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return {-2, ''}
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 9))
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+redis.call('SADD', KEYS[3], ARGV[1])
+redis.call('ZADD', KEYS[4], ARGV[3], ARGV[1])
+if tonumber(ARGV[7]) > 0 then
+	redis.call('SET', KEYS[5], ARGV[1], 'PX', ARGV[7])
+else
+	redis.call('SET', KEYS[5], ARGV[1])
+end
+return {1, ARGV[1]}
+`)
+
+// expireLocksScript moves processing tasks with expired locks back to pending.
+// KEYS: processing sorted set.
+// ARGV: key prefix, now in unix ms.
+var expireLocksScript = redis.NewScript(`
+local prefix = ARGV[1]
+local expired = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', '(' .. ARGV[2])
+for _, id in ipairs(expired) do
+	local key = prefix .. 'task:' .. id
+	local f = redis.call('HMGET', key, 'queue', 'task_name', 'priority', 'scheduled_at')
+	redis.call('ZREM', KEYS[1], id)
+	if f[1] then
+		redis.call('HSET', key, 'status', 'pending')
+		redis.call('HDEL', key, 'locked_until', 'locked_by')
+		local score = (100 - tonumber(f[3])) * 1e13 + tonumber(f[4])
+		redis.call('ZADD', prefix .. 'pending:' .. f[1], score, id)
+		redis.call('SADD', prefix .. 'name:' .. f[2], id)
+	end
+end
+return #expired
+`)
+
+// claimTaskScript promotes due scheduled tasks and claims the best pending task across
+// queues that are not paused. In ordered mode the first queue with a pending task wins.
+// KEYS: processing sorted set, paused queues set.
+// ARGV: key prefix, now in unix ms, locked until in unix ms, worker ID, ordered ('1' or '0'), queue names...
+var claimTaskScript = redis.NewScript(`
+local prefix = ARGV[1]
+local now = tonumber(ARGV[2])
+local ordered = ARGV[5] == '1'
+local bestID, bestScore, bestKey
+
+for i = 6, #ARGV do
+	if redis.call('SISMEMBER', KEYS[2], ARGV[i]) == 0 then
+		local pendingKey = prefix .. 'pending:' .. ARGV[i]
+		local scheduledKey = prefix .. 'scheduled:' .. ARGV[i]
+
+		local due = redis.call('ZRANGEBYSCORE', scheduledKey, '-inf', now, 'LIMIT', 0, 100)
+		for _, id in ipairs(due) do
+			local f = redis.call('HMGET', prefix .. 'task:' .. id, 'priority', 'scheduled_at')
+			redis.call('ZREM', scheduledKey, id)
+			if f[1] then
+				redis.call('ZADD', pendingKey, (100 - tonumber(f[1])) * 1e13 + tonumber(f[2]), id)
+			end
+		end
+
+		local top = redis.call('ZRANGE', pendingKey, 0, 0, 'WITHSCORES')
+		if top[1] and (bestScore == nil or tonumber(top[2]) < bestScore) then
+			bestID = top[1]
+			bestScore = tonumber(top[2])
+			bestKey = pendingKey
+		end
+	end
+	if ordered and bestID then
+		break
+	end
+end
+
+if not bestID then
+	return false
+end
+
+local key = prefix .. 'task:' .. bestID
+redis.call('ZREM', bestKey, bestID)
+redis.call('HSET', key, 'status', 'processing', 'locked_until', ARGV[3], 'locked_by', ARGV[4])
+redis.call('ZADD', KEYS[1], ARGV[3], bestID)
+redis.call('SREM', prefix .. 'name:' .. redis.call('HGET', key, 'task_name'), bestID)
+return redis.call('HGETALL', key)
+`)
+
+// completeTaskScript marks a processing task as completed and stores its result, if any.
+// KEYS: task hash, processing sorted set.
+// ARGV: task ID, now in unix ms, retention in ms (0 keeps the hash forever), key prefix,
+// result (optional).
+var completeTaskScript = redis.NewScript(releaseUniqueKeyFunc + `
+if redis.call('HGET', KEYS[1], 'status') ~= 'processing' then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'status', 'completed', 'processed_at', ARGV[2])
+if ARGV[5] and ARGV[5] ~= '' then
+	redis.call('HSET', KEYS[1], 'result', ARGV[5])
+end
+releaseUniqueKey(ARGV[4], KEYS[1], ARGV[1])
+redis.call('HDEL', KEYS[1], 'locked_until', 'locked_by')
+redis.call('ZREM', KEYS[2], ARGV[1])
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+return 1
+`)
+
+// failTaskScript records a failure and either reschedules the task at the retry time
+// decided by the worker or marks it failed when no retry time is given.
+// KEYS: task hash, processing sorted set.
+// ARGV: task ID, retry at in unix ms (empty for no retry), error message, key prefix.
+var failTaskScript = redis.NewScript(releaseUniqueKeyFunc + `
+local f = redis.call('HMGET', KEYS[1], 'status', 'retry_count', 'queue', 'task_name')
+if f[1] ~= 'processing' then
+	return 0
+end
+
+redis.call('HSET', KEYS[1], 'retry_count', tonumber(f[2]) + 1, 'error', ARGV[3])
+redis.call('HDEL', KEYS[1], 'locked_until', 'locked_by')
+redis.call('ZREM', KEYS[2], ARGV[1])
+
+if ARGV[2] == '' then
+	redis.call('HSET', KEYS[1], 'status', 'failed')
+	releaseUniqueKey(ARGV[4], KEYS[1], ARGV[1])
+else
+	redis.call('HSET', KEYS[1], 'status', 'pending', 'scheduled_at', ARGV[2])
+	redis.call('ZADD', ARGV[4] .. 'scheduled:' .. f[3], ARGV[2], ARGV[1])
+	redis.call('SADD', ARGV[4] .. 'name:' .. f[4], ARGV[1])
+end
+return 1
+`)
+
+// releaseTaskScript returns a processing task to the scheduled set without recording a failure.
+// KEYS: task hash, processing sorted set.
+// ARGV: task ID, scheduled at in unix ms, key prefix.
+var releaseTaskScript = redis.NewScript(`
+local f = redis.call('HMGET', KEYS[1], 'status', 'queue', 'task_name')
+if f[1] ~= 'processing' then
+	return 0
+end
+
+redis.call('HSET', KEYS[1], 'status', 'pending', 'scheduled_at', ARGV[2])
+redis.call('HDEL', KEYS[1], 'locked_until', 'locked_by')
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('ZADD', ARGV[3] .. 'scheduled:' .. f[2], ARGV[2], ARGV[1])
+redis.call('SADD', ARGV[3] .. 'name:' .. f[3], ARGV[1])
+return 1
+`)
+
+// moveToDLQScript removes the task from all indexes and pushes the prepared DLQ entry.
+// KEYS: task hash, processing sorted set, DLQ list, tasks index.
+// ARGV: task ID, key prefix, DLQ entry JSON.
+var moveToDLQScript = redis.NewScript(releaseUniqueKeyFunc + `
+local f = redis.call('HMGET', KEYS[1], 'queue', 'task_name')
+if not f[1] then
+	return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('ZREM', KEYS[4], ARGV[1])
+redis.call('ZREM', ARGV[2] .. 'pending:' .. f[1], ARGV[1])
+redis.call('ZREM', ARGV[2] .. 'scheduled:' .. f[1], ARGV[1])
+redis.call('SREM', ARGV[2] .. 'name:' .. f[2], ARGV[1])
+releaseUniqueKey(ARGV[2], KEYS[1], ARGV[1])
+redis.call('DEL', KEYS[1])
+redis.call('LPUSH', KEYS[3], ARGV[3])
+return 1
+`)
+
+// extendLockScript moves the lock deadline of a processing task.
+// KEYS: task hash, processing sorted set.
+// ARGV: task ID, locked until in unix ms.
+var extendLockScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'status') ~= 'processing' then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'locked_until', ARGV[2])
+redis.call('ZADD', KEYS[2], ARGV[2], ARGV[1])
+return 1
+`)
+
+// cancelTaskScript marks a pending task as cancelled and removes it from the claim indexes.
+// Returns -1 when the task does not exist and 0 when it is not pending.
+// KEYS: task hash.
+// ARGV: task ID, key prefix, now in unix ms, retention in ms (0 keeps the hash forever).
+var cancelTaskScript = redis.NewScript(releaseUniqueKeyFunc + `
+local f = redis.call('HMGET', KEYS[1], 'status', 'queue', 'task_name')
+if not f[1] then
+	return -1
+end
+if f[1] ~= 'pending' then
+	return 0
+end
+redis.call('ZREM', ARGV[2] .. 'pending:' .. f[2], ARGV[1])
+redis.call('ZREM', ARGV[2] .. 'scheduled:' .. f[2], ARGV[1])
+redis.call('SREM', ARGV[2] .. 'name:' .. f[3], ARGV[1])
+redis.call('HSET', KEYS[1], 'status', 'cancelled', 'processed_at', ARGV[3])
+releaseUniqueKey(ARGV[2], KEYS[1], ARGV[1])
+if tonumber(ARGV[4]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[4])
+end
+return 1
+`)
+
+// requeueDLQScript removes a DLQ entry and recreates its task as pending. A task of an
+// unfinished batch rejoins it: the failure counted for it is taken back.
+// Returns -1 when the task already exists, 0 when the entry is no longer in the DLQ,
+// 2 when the task rejoined its batch and 1 otherwise.
+// KEYS: task hash, DLQ list, pending sorted set, task name set, tasks index, batch hash (optional).
+// ARGV: DLQ entry JSON, task ID, score, created at in unix ms, batch ID (empty for none),
+// hash field/value pairs...
+var requeueDLQScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return -1
+end
+if redis.call('LREM', KEYS[2], 1, ARGV[1]) == 0 then
+	return 0
+end
+redis.call('HSET', KEYS[1], unpack(ARGV, 6))
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[2])
+redis.call('SADD', KEYS[4], ARGV[2])
+redis.call('ZADD', KEYS[5], ARGV[4], ARGV[2])
+
+if ARGV[5] ~= '' then
+	local b = redis.call('HMGET', KEYS[6], 'failed', 'finished_at')
+	if b[1] and not b[2] and tonumber(b[1]) > 0 then
+		redis.call('HINCRBY', KEYS[6], 'failed', -1)
+		redis.call('HSET', KEYS[1], 'batch_id', ARGV[5])
+		return 2
+	end
+end
+return 1
+`)
+
+// createBatchScript stores a batch hash and creates all of its tasks.
+// Returns 0 when the batch exists and -1 when one of the task IDs exists; nothing is written then.
+// KEYS: batch hash, tasks index, then per task: task hash, target sorted set, task name set.
+// ARGV: batch field count, batch hash field/value pairs..., then per task: task ID, score,
+// created at in unix ms, field count, hash field/value pairs...
+var createBatchScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+for i = 3, #KEYS, 3 do
+	if redis.call('EXISTS', KEYS[i]) == 1 then
+		return -1
+	end
+end
+
+local batchFields = tonumber(ARGV[1])
+redis.call('HSET', KEYS[1], unpack(ARGV, 2, 1 + batchFields))
+
+local pos = 2 + batchFields
+for i = 3, #KEYS, 3 do
+	local id = ARGV[pos]
+	local fields = tonumber(ARGV[pos + 3])
+	redis.call('HSET', KEYS[i], unpack(ARGV, pos + 4, pos + 3 + fields))
+	redis.call('ZADD', KEYS[i + 1], ARGV[pos + 1], id)
+	redis.call('SADD', KEYS[i + 2], id)
+	redis.call('ZADD', KEYS[2], ARGV[pos + 2], id)
+	pos = pos + 4 + fields
+end
+return 1
+`)
+
+// finishBatchTaskScript counts a finished batch task and marks the batch finished
+// when it was the last one, creating the on complete callback task when no task failed
+// and the on failure callback task otherwise. Returns -1 when the batch does not exist,
+// -2 when it is already finished, -3 when the callback task ID already exists (nothing
+// is written then), 1 when this call finished the batch and 0 otherwise.
+// KEYS: batch hash, tasks index, then per given callback: task hash, pending sorted set,
+// task name set.
+// ARGV: counter field (succeeded or failed), now in unix ms, retention in ms (0 keeps the
+// hash forever), then for the on complete and on failure callbacks: task ID (empty for
+// none), score, created at in unix ms, field count, hash field/value pairs...
+var finishBatchTaskScript = redis.NewScript(`
+local f = redis.call('HMGET', KEYS[1], 'total', 'succeeded', 'failed', 'finished_at')
+if not f[1] then
+	return -1
+end
+if f[4] then
+	return -2
+end
+
+local callbacks, pos, key = {}, 4, 3
+for i = 1, 2 do
+	if ARGV[pos] ~= '' then
+		callbacks[i] = {pos = pos, key = key}
+		key = key + 3
+	end
+	pos = pos + 4 + tonumber(ARGV[pos + 3])
+end
+
+local finished = tonumber(f[2]) + tonumber(f[3]) + 1 >= tonumber(f[1])
+local callback
+if finished then
+	if ARGV[1] == 'failed' or tonumber(f[3]) > 0 then
+		callback = callbacks[2]
+	else
+		callback = callbacks[1]
+	end
+	if callback and redis.call('EXISTS', KEYS[callback.key]) == 1 then
+		return -3
+	end
+end
+
+redis.call('HINCRBY', KEYS[1], ARGV[1], 1)
+if not finished then
+	return 0
+end
+redis.call('HSET', KEYS[1], 'finished_at', ARGV[2])
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+
+if callback then
+	local p, k = callback.pos, callback.key
+	redis.call('HSET', KEYS[k], unpack(ARGV, p + 4, p + 3 + tonumber(ARGV[p + 3])))
+	redis.call('ZADD', KEYS[k + 1], ARGV[p + 1], ARGV[p])
+	redis.call('SADD', KEYS[k + 2], ARGV[p])
+	redis.call('ZADD', KEYS[2], ARGV[p + 2], ARGV[p])
+end
+return 1
+`)
+
+// completeTaskWithNextScript marks a processing task as completed and creates the next chain task.
+// Returns 0 when the task is not processing and -1 when the next task ID already exists.
+// KEYS: task hash, processing sorted set, next task hash, next target sorted set,
+// next task name set, tasks index.
+// ARGV: task ID, now in unix ms, retention in ms (0 keeps the hash forever), key prefix,
+// next task ID, next score, next created at in unix ms, next hash field/value pairs...
+var completeTaskWithNextScript = redis.NewScript(releaseUniqueKeyFunc + `
+if redis.call('HGET', KEYS[1], 'status') ~= 'processing' then
+	return 0
+end
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	return -1
+end
+redis.call('HSET', KEYS[1], 'status', 'completed', 'processed_at', ARGV[2])
+redis.call('HDEL', KEYS[1], 'locked_until', 'locked_by')
+redis.call('ZREM', KEYS[2], ARGV[1])
+releaseUniqueKey(ARGV[4], KEYS[1], ARGV[1])
+if tonumber(ARGV[3]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[3])
+end
+
+redis.call('HSET', KEYS[3], unpack(ARGV, 8))
+redis.call('ZADD', KEYS[4], ARGV[6], ARGV[5])
+redis.call('SADD', KEYS[5], ARGV[5])
+redis.call('ZADD', KEYS[6], ARGV[7], ARGV[5])
+return 1
+`)
@@ -0,0 +1,1069 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+)
+
+var (
+	_ queue.EnqueuerRepository     = (*QueueStorage)(nil)
+	_ queue.WorkerRepository       = (*QueueStorage)(nil)
+	_ queue.SchedulerRepository    = (*QueueStorage)(nil)
+	_ queue.QueueInspector         = (*QueueStorage)(nil)
+	_ queue.UniqueTaskRepository   = (*QueueStorage)(nil)
+	_ queue.WorkflowRepository     = (*QueueStorage)(nil)
+	_ queue.ResultRepository       = (*QueueStorage)(nil)
+	_ queue.ReleaseRepository      = (*QueueStorage)(nil)
+	_ queue.OrderedClaimRepository = (*QueueStorage)(nil)
+)
+
+// QueueStorage implements the core/queue repository interfaces on top of Redis.
+//
+// Data layout (all keys share the configured prefix):
+//   - task:{id}         hash with task fields, timestamps stored as unix milliseconds
+//   - pending:{queue}   sorted set of due tasks ordered by priority, then scheduled time
+//   - scheduled:{queue} sorted set of delayed tasks scored by scheduled time
+//   - processing        sorted set of claimed tasks scored by lock deadline
+//   - name:{task_name}  set of pending task IDs, used by the scheduler
+//   - dlq               list of JSON encoded queue.TasksDlq entries, newest first
+//   - tasks             sorted set of all task IDs scored by creation time, used for inspection
+//   - paused            set of paused queue names skipped when claiming
+//   - unique:{key}      ID of the task holding the unique key, deleted once it is no longer pending or processing
+//   - batch:{id}        hash with batch counters and callbacks, expires after the retention once finished
+//
+// Timestamps come from the application clock, so instances sharing a Redis
+// should keep their clocks synchronized.
+type QueueStorage struct {
+	client    redis.UniversalClient
+	prefix    string
+	retention time.Duration
+}
+
+// QueueStorageOption configures a QueueStorage.
+type QueueStorageOption func(*QueueStorage)
+
+// WithQueueKeyPrefix sets the prefix for all queue keys.
+// Keep a hash tag (e.g. "{jobs}:") when running against Redis Cluster.
+func WithQueueKeyPrefix(prefix string) QueueStorageOption {
+	return func(s *QueueStorage) {
+		if prefix != "" {
+			s.prefix = prefix
+		}
+	}
+}
+
+// WithCompletedTaskRetention sets how long completed and cancelled tasks are kept before Redis expires them.
+// Zero keeps completed tasks forever.
+func WithCompletedTaskRetention(d time.Duration) QueueStorageOption {
+	return func(s *QueueStorage) {
+		if d >= 0 {
+			s.retention = d
+		}
+	}
+}
+
+// NewQueueStorage creates a Redis-backed queue storage using the given client.
+// By default keys are prefixed with "{queue}:" and completed tasks are kept for 24 hours.
+func NewQueueStorage(client redis.UniversalClient, opts ...QueueStorageOption) *QueueStorage {
+	s := &QueueStorage{
+		client:    client,
+		prefix:    "{queue}:",
+		retention: 24 * time.Hour,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// CreateTask implements queue.EnqueuerRepository and queue.SchedulerRepository.
+func (s *QueueStorage) CreateTask(ctx context.Context, task *queue.Task) error {
+	if task == nil {
+		return errors.New("task cannot be nil")
+	}
+
+	target, score := s.targetSet(task)
+	args := append([]any{task.ID.String(), score, task.CreatedAt.UnixMilli()}, encodeTask(task)...)
+	keys := []string{s.taskKey(task.ID), target, s.nameKey(task.TaskName), s.tasksKey()}
+
+	created, err := createTaskScript.Run(ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return fmt.Errorf("failed to create task %s: %w", task.ID, err)
+	}
+	if created == 0 {
+		return fmt.Errorf("task with ID %s already exists", task.ID)
+	}
+
+	return nil
+}
+
+// CreateUniqueTask implements queue.UniqueTaskRepository.
+// The holder check, optional replacement and creation run in a single script.
+func (s *QueueStorage) CreateUniqueTask(ctx context.Context, task *queue.Task, onConflict queue.UniqueConflict) (uuid.UUID, error) {
+	if task == nil {
+		return uuid.Nil, errors.New("task cannot be nil")
+	}
+	if task.UniqueKey == nil {
+		return uuid.Nil, errors.New("task has no unique key")
+	}
+	key := *task.UniqueKey
+
+	now := time.Now()
+	var ttl int64
+	if task.UniqueUntil != nil {
+		// Keep at least one millisecond so the key never lingers without expiry
+		ttl = max(task.UniqueUntil.Sub(now).Milliseconds(), 1)
+	}
+
+	target, score := s.targetSet(task)
+	args := append([]any{
+		task.ID.String(), score, task.CreatedAt.UnixMilli(), string(onConflict), s.prefix,
+		now.UnixMilli(), ttl, s.retention.Milliseconds(),
+	}, encodeTask(task)...)
+	keys := []string{
+		s.taskKey(task.ID), target, s.nameKey(task.TaskName), s.tasksKey(), s.uniqueKey(key),
+	}
+
+	res, err := createUniqueTaskScript.Run(ctx, s.client, keys, args...).Slice()
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create task %s: %w", task.ID, err)
+	}
+	if len(res) != 2 {
+		return uuid.Nil, fmt.Errorf("failed to create task %s: unexpected script reply", task.ID)
+	}
+
+	code, _ := res[0].(int64)
+	holder, _ := res[1].(string)
+	switch code {
+	case -2:
+		return uuid.Nil, fmt.Errorf("task with ID %s already exists", task.ID)
+	case -1:
+		return uuid.Nil, fmt.Errorf("%w: %s", queue.ErrDuplicateTask, key)
+	}
+
+	holderID, err := uuid.Parse(holder)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid task id %q for unique key %q: %w", holder, key, err)
+	}
+
+	return holderID, nil
+}
+
+// ClaimTask implements queue.WorkerRepository.
+// Due delayed tasks are promoted to pending before the highest priority task is claimed.
+func (s *QueueStorage) ClaimTask(ctx context.Context, workerID uuid.UUID, queues []string, lockDuration time.Duration) (*queue.Task, error) {
+	return s.claimTask(ctx, workerID, queues, lockDuration, false)
+}
+
+// ClaimTaskInOrder implements queue.OrderedClaimRepository.
+// Claims the highest priority task of the first queue in the list that has a due task.
+func (s *QueueStorage) ClaimTaskInOrder(ctx context.Context, workerID uuid.UUID, queues []string, lockDuration time.Duration) (*queue.Task, error) {
+	return s.claimTask(ctx, workerID, queues, lockDuration, true)
+}
+
+// claimTask runs claimTaskScript across the queues, or in queue order when ordered is set.
+func (s *QueueStorage) claimTask(ctx context.Context, workerID uuid.UUID, queues []string, lockDuration time.Duration, ordered bool) (*queue.Task, error) {
+	if err := s.expireLocks(ctx); err != nil {
+		return nil, err
+	}
+
+	mode := "0"
+	if ordered {
+		mode = "1"
+	}
+
+	now := time.Now()
+	args := make([]any, 0, len(queues)+5)
+	args = append(args, s.prefix, now.UnixMilli(), now.Add(lockDuration).UnixMilli(), workerID.String(), mode)
+	for _, q := range queues {
+		args = append(args, q)
+	}
+
+	keys := []string{s.processingKey(), s.pausedKey()}
+	res, err := claimTaskScript.Run(ctx, s.client, keys, args...).StringSlice()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, queue.ErrNoTaskToClaim
+		}
+		return nil, fmt.Errorf("failed to claim task: %w", err)
+	}
+
+	return decodeTask(pairsToMap(res))
+}
+
+// CompleteTask implements queue.WorkerRepository.
+func (s *QueueStorage) CompleteTask(ctx context.Context, taskID uuid.UUID) error {
+	return s.completeTask(ctx, taskID, nil)
+}
+
+// CompleteTaskWithResult implements queue.ResultRepository.
+// The result expires together with the task hash after the completed task retention.
+func (s *QueueStorage) CompleteTaskWithResult(ctx context.Context, taskID uuid.UUID, result []byte) error {
+	return s.completeTask(ctx, taskID, result)
+}
+
+// completeTask runs completeTaskScript; an empty result leaves the result field unset.
+func (s *QueueStorage) completeTask(ctx context.Context, taskID uuid.UUID, result []byte) error {
+	keys := []string{s.taskKey(taskID), s.processingKey()}
+	ok, err := completeTaskScript.Run(ctx, s.client, keys,
+		taskID.String(), time.Now().UnixMilli(), s.retention.Milliseconds(), s.prefix, result).Int()
+	if err != nil {
+		return fmt.Errorf("failed to complete task %s: %w", taskID, err)
+	}
+	if ok == 0 {
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	}
+
+	return nil
+}
+
+// FailTask implements queue.WorkerRepository.
+// Retried tasks wait in the scheduled set until retryAt; a nil retryAt marks the task failed.
+func (s *QueueStorage) FailTask(ctx context.Context, taskID uuid.UUID, errorMsg string, retryAt *time.Time) error {
+	var retryAtMs string
+	if retryAt != nil {
+		retryAtMs = strconv.FormatInt(retryAt.UnixMilli(), 10)
+	}
+
+	keys := []string{s.taskKey(taskID), s.processingKey()}
+	ok, err := failTaskScript.Run(ctx, s.client, keys,
+		taskID.String(), retryAtMs, errorMsg, s.prefix).Int()
+	if err != nil {
+		return fmt.Errorf("failed to fail task %s: %w", taskID, err)
+	}
+	if ok == 0 {
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	}
+
+	return nil
+}
+
+// ReleaseTask implements queue.ReleaseRepository.
+// The task waits in the scheduled set until scheduledAt; its retry count is unchanged.
+func (s *QueueStorage) ReleaseTask(ctx context.Context, taskID uuid.UUID, scheduledAt time.Time) error {
+	keys := []string{s.taskKey(taskID), s.processingKey()}
+	ok, err := releaseTaskScript.Run(ctx, s.client, keys,
+		taskID.String(), scheduledAt.UnixMilli(), s.prefix).Int()
+	if err != nil {
+		return fmt.Errorf("failed to release task %s: %w", taskID, err)
+	}
+	if ok == 0 {
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	}
+
+	return nil
+}
+
+// MoveToDLQ implements queue.WorkerRepository.
+func (s *QueueStorage) MoveToDLQ(ctx context.Context, taskID uuid.UUID) error {
+	task, err := s.getTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entry := queue.TasksDlq{
+		ID:         uuid.New(),
+		TaskID:     task.ID,
+		Queue:      task.Queue,
+		TaskType:   task.TaskType,
+		TaskName:   task.TaskName,
+		Payload:    task.Payload,
+		Priority:   task.Priority,
+		RetryCount: task.RetryCount,
+		MaxRetries: task.MaxRetries,
+		Backoff:    task.Backoff,
+		BatchID:    task.BatchID,
+		Next:       task.Next,
+		FailedAt:   now,
+		CreatedAt:  now,
+	}
+	if task.Error != nil {
+		entry.Error = *task.Error
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal DLQ entry for task %s: %w", taskID, err)
+	}
+
+	keys := []string{s.taskKey(taskID), s.processingKey(), s.dlqKey(), s.tasksKey()}
+	ok, err := moveToDLQScript.Run(ctx, s.client, keys, taskID.String(), s.prefix, data).Int()
+	if err != nil {
+		return fmt.Errorf("failed to move task %s to DLQ: %w", taskID, err)
+	}
+	if ok == 0 {
+		return fmt.Errorf("task %s not found", taskID)
+	}
+
+	return nil
+}
+
+// ExtendLock implements queue.WorkerRepository.
+func (s *QueueStorage) ExtendLock(ctx context.Context, taskID uuid.UUID, duration time.Duration) error {
+	keys := []string{s.taskKey(taskID), s.processingKey()}
+	ok, err := extendLockScript.Run(ctx, s.client, keys,
+		taskID.String(), time.Now().Add(duration).UnixMilli()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to extend lock for task %s: %w", taskID, err)
+	}
+	if ok == 0 {
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	}
+
+	return nil
+}
+
+// GetPendingTaskByName implements queue.SchedulerRepository.
+// Returns the earliest scheduled pending task, or nil without error when none exists.
+func (s *QueueStorage) GetPendingTaskByName(ctx context.Context, taskName string) (*queue.Task, error) {
+	ids, err := s.client.SMembers(ctx, s.nameKey(taskName)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending task %q: %w", taskName, err)
+	}
+
+	var found *queue.Task
+	for _, id := range ids {
+		taskID, err := uuid.Parse(id)
+		if err != nil {
+			continue
+		}
+		task, err := s.getTask(ctx, taskID)
+		if err != nil || task.Status != queue.TaskStatusPending {
+			continue
+		}
+		if found == nil || task.ScheduledAt.Before(found.ScheduledAt) {
+			found = task
+		}
+	}
+
+	return found, nil
+}
+
+// ListTasks implements queue.QueueInspector.
+// Walks the tasks index newest first and filters in the application, so filtered
+// listings of large queues cost proportionally to the number of scanned tasks.
+func (s *QueueStorage) ListTasks(ctx context.Context, filter queue.TaskFilter) ([]*queue.Task, error) {
+	limit := queue.NormalizeLimit(filter.Limit)
+	skip := max(filter.Offset, 0)
+	tasks := make([]*queue.Task, 0)
+
+	err := s.scanTasks(ctx, filter, func(task *queue.Task) bool {
+		if skip > 0 {
+			skip--
+			return true
+		}
+		tasks = append(tasks, task)
+		return len(tasks) < limit
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// CountTasks implements queue.QueueInspector.
+func (s *QueueStorage) CountTasks(ctx context.Context, filter queue.TaskFilter) (int64, error) {
+	var count int64
+	err := s.scanTasks(ctx, filter, func(*queue.Task) bool {
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tasks: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetTask implements queue.QueueInspector.
+func (s *QueueStorage) GetTask(ctx context.Context, taskID uuid.UUID) (*queue.Task, error) {
+	return s.getTask(ctx, taskID)
+}
+
+// CancelTask implements queue.QueueInspector.
+func (s *QueueStorage) CancelTask(ctx context.Context, taskID uuid.UUID) error {
+	res, err := cancelTaskScript.Run(ctx, s.client, []string{s.taskKey(taskID)},
+		taskID.String(), s.prefix, time.Now().UnixMilli(), s.retention.Milliseconds()).Int()
+	if err != nil {
+		return fmt.Errorf("failed to cancel task %s: %w", taskID, err)
+	}
+
+	switch res {
+	case -1:
+		return fmt.Errorf("%w: %s", queue.ErrTaskNotFound, taskID)
+	case 0:
+		return fmt.Errorf("%w: %s", queue.ErrTaskNotPending, taskID)
+	}
+
+	return nil
+}
+
+// ListDLQ implements queue.QueueInspector.
+func (s *QueueStorage) ListDLQ(ctx context.Context, filter queue.DLQFilter) ([]*queue.TasksDlq, error) {
+	raw, err := s.client.LRange(ctx, s.dlqKey(), 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list DLQ entries: %w", err)
+	}
+
+	limit := queue.NormalizeLimit(filter.Limit)
+	skip := max(filter.Offset, 0)
+	entries := make([]*queue.TasksDlq, 0)
+
+	for _, data := range raw {
+		var entry queue.TasksDlq
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			continue
+		}
+		if filter.Queue != "" && entry.Queue != filter.Queue {
+			continue
+		}
+		if skip > 0 {
+			skip--
+			continue
+		}
+		entries = append(entries, &entry)
+		if len(entries) == limit {
+			break
+		}
+	}
+
+	return entries, nil
+}
+
+// RequeueDLQ implements queue.QueueInspector.
+// The entry is removed from the DLQ and its task recreated as pending in one script.
+// The task rejoins its batch only while the batch is unfinished.
+func (s *QueueStorage) RequeueDLQ(ctx context.Context, dlqID uuid.UUID) (*queue.Task, error) {
+	entry, raw, err := s.findDLQEntry(ctx, dlqID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	task := &queue.Task{
+		ID:          entry.TaskID,
+		Queue:       entry.Queue,
+		TaskType:    entry.TaskType,
+		TaskName:    entry.TaskName,
+		Payload:     entry.Payload,
+		Status:      queue.TaskStatusPending,
+		Priority:    entry.Priority,
+		MaxRetries:  entry.MaxRetries,
+		Backoff:     entry.Backoff,
+		Next:        entry.Next,
+		ScheduledAt: now,
+		CreatedAt:   now,
+	}
+
+	batchID := ""
+	keys := []string{
+		s.taskKey(task.ID), s.dlqKey(), s.pendingKey(task.Queue), s.nameKey(task.TaskName), s.tasksKey(),
+	}
+	if entry.BatchID != nil {
+		batchID = entry.BatchID.String()
+		keys = append(keys, s.batchKey(*entry.BatchID))
+	}
+	args := append([]any{raw, task.ID.String(), pendingScore(task.Priority, now), now.UnixMilli(), batchID},
+		encodeTask(task)...)
+
+	res, err := requeueDLQScript.Run(ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue DLQ entry %s: %w", dlqID, err)
+	}
+
+	switch res {
+	case -1:
+		return nil, fmt.Errorf("task for DLQ entry %s already exists", dlqID)
+	case 0:
+		return nil, fmt.Errorf("%w: %s", queue.ErrDLQEntryNotFound, dlqID)
+	case 2:
+		task.BatchID = entry.BatchID
+	}
+
+	return task, nil
+}
+
+// PurgeDLQ implements queue.QueueInspector.
+func (s *QueueStorage) PurgeDLQ(ctx context.Context, queueName string) (int64, error) {
+	if queueName == "" {
+		var length *redis.IntCmd
+		_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			length = pipe.LLen(ctx, s.dlqKey())
+			pipe.Del(ctx, s.dlqKey())
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to purge DLQ: %w", err)
+		}
+		return length.Val(), nil
+	}
+
+	raw, err := s.client.LRange(ctx, s.dlqKey(), 0, -1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge DLQ: %w", err)
+	}
+
+	var purged int64
+	for _, data := range raw {
+		var entry queue.TasksDlq
+		if err := json.Unmarshal([]byte(data), &entry); err != nil || entry.Queue != queueName {
+			continue
+		}
+		n, err := s.client.LRem(ctx, s.dlqKey(), 1, data).Result()
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge DLQ: %w", err)
+		}
+		purged += n
+	}
+
+	return purged, nil
+}
+
+// PauseQueue implements queue.QueueInspector.
+func (s *QueueStorage) PauseQueue(ctx context.Context, queueName string) error {
+	if err := s.client.SAdd(ctx, s.pausedKey(), queueName).Err(); err != nil {
+		return fmt.Errorf("failed to pause queue %q: %w", queueName, err)
+	}
+	return nil
+}
+
+// ResumeQueue implements queue.QueueInspector.
+func (s *QueueStorage) ResumeQueue(ctx context.Context, queueName string) error {
+	if err := s.client.SRem(ctx, s.pausedKey(), queueName).Err(); err != nil {
+		return fmt.Errorf("failed to resume queue %q: %w", queueName, err)
+	}
+	return nil
+}
+
+// PausedQueues implements queue.QueueInspector.
+func (s *QueueStorage) PausedQueues(ctx context.Context) ([]string, error) {
+	queues, err := s.client.SMembers(ctx, s.pausedKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list paused queues: %w", err)
+	}
+	slices.Sort(queues)
+	return queues, nil
+}
+
+// CreateBatch implements queue.WorkflowRepository.
+// The batch hash and all of its tasks are created in a single script.
+func (s *QueueStorage) CreateBatch(ctx context.Context, batch *queue.Batch, tasks []*queue.Task) error {
+	if batch == nil {
+		return errors.New("batch cannot be nil")
+	}
+
+	batchFields, err := encodeBatch(batch)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, 2+len(tasks)*3)
+	keys = append(keys, s.batchKey(batch.ID), s.tasksKey())
+	args := append([]any{len(batchFields)}, batchFields...)
+	for _, task := range tasks {
+		if task == nil {
+			return errors.New("task cannot be nil")
+		}
+		target, score := s.targetSet(task)
+		fields := encodeTask(task)
+		keys = append(keys, s.taskKey(task.ID), target, s.nameKey(task.TaskName))
+		args = append(args, task.ID.String(), score, task.CreatedAt.UnixMilli(), len(fields))
+		args = append(args, fields...)
+	}
+
+	res, err := createBatchScript.Run(ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return fmt.Errorf("failed to create batch %s: %w", batch.ID, err)
+	}
+
+	switch res {
+	case 0:
+		return fmt.Errorf("batch with ID %s already exists", batch.ID)
+	case -1:
+		return fmt.Errorf("a task of batch %s already exists", batch.ID)
+	}
+
+	return nil
+}
+
+// GetBatch implements queue.WorkflowRepository.
+func (s *QueueStorage) GetBatch(ctx context.Context, batchID uuid.UUID) (*queue.Batch, error) {
+	fields, err := s.client.HGetAll(ctx, s.batchKey(batchID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch %s: %w", batchID, err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: %s", queue.ErrBatchNotFound, batchID)
+	}
+
+	return decodeBatch(fields)
+}
+
+// FinishBatchTask implements queue.WorkflowRepository.
+// Counters are updated atomically, so exactly one call observes the last task finishing.
+// Both callbacks are passed to the script, which creates the one matching the outcome
+// of the batch together with marking it finished.
+func (s *QueueStorage) FinishBatchTask(ctx context.Context, batchID uuid.UUID, succeeded bool) (*queue.Batch, error) {
+	counter := "failed"
+	if succeeded {
+		counter = "succeeded"
+	}
+
+	// Callbacks never change after the batch is created, so they can be read up front
+	batch, err := s.GetBatch(ctx, batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	keys := []string{s.batchKey(batchID), s.tasksKey()}
+	args := []any{counter, now.UnixMilli(), s.retention.Milliseconds()}
+	for _, callback := range []*queue.Task{batch.OnComplete, batch.OnFailure} {
+		if callback == nil {
+			args = append(args, "", 0, 0, 0)
+			continue
+		}
+		task := *callback
+		task.Status = queue.TaskStatusPending
+		task.ScheduledAt = now
+		task.CreatedAt = now
+		fields := encodeTask(&task)
+		keys = append(keys, s.taskKey(task.ID), s.pendingKey(task.Queue), s.nameKey(task.TaskName))
+		args = append(args, task.ID.String(), pendingScore(task.Priority, now), now.UnixMilli(), len(fields))
+		args = append(args, fields...)
+	}
+
+	res, err := finishBatchTaskScript.Run(ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return nil, fmt.Errorf("failed to finish task of batch %s: %w", batchID, err)
+	}
+
+	switch res {
+	case -1:
+		return nil, fmt.Errorf("%w: %s", queue.ErrBatchNotFound, batchID)
+	case -2:
+		return nil, fmt.Errorf("batch %s is already finished", batchID)
+	case -3:
+		return nil, fmt.Errorf("callback task of batch %s already exists", batchID)
+	}
+
+	return s.GetBatch(ctx, batchID)
+}
+
+// CompleteTaskWithNext implements queue.WorkflowRepository.
+// Completing the task and creating the next chain step run in a single script.
+func (s *QueueStorage) CompleteTaskWithNext(ctx context.Context, taskID uuid.UUID, next *queue.Task) error {
+	if next == nil {
+		return errors.New("next task cannot be nil")
+	}
+
+	target, score := s.targetSet(next)
+	args := append([]any{
+		taskID.String(), time.Now().UnixMilli(), s.retention.Milliseconds(), s.prefix,
+		next.ID.String(), score, next.CreatedAt.UnixMilli(),
+	}, encodeTask(next)...)
+	keys := []string{
+		s.taskKey(taskID), s.processingKey(),
+		s.taskKey(next.ID), target, s.nameKey(next.TaskName), s.tasksKey(),
+	}
+
+	res, err := completeTaskWithNextScript.Run(ctx, s.client, keys, args...).Int()
+	if err != nil {
+		return fmt.Errorf("failed to complete task %s: %w", taskID, err)
+	}
+
+	switch res {
+	case 0:
+		return fmt.Errorf("task %s not found or not in processing state", taskID)
+	case -1:
+		return fmt.Errorf("task with ID %s already exists", next.ID)
+	}
+
+	return nil
+}
+
+// expireLocks resets tasks whose worker lock has expired back to pending.
+// Equivalent to MemoryStorage lock expiration, but runs before every claim
+// instead of in a background goroutine. The retry count is preserved.
+func (s *QueueStorage) expireLocks(ctx context.Context) error {
+	err := expireLocksScript.Run(ctx, s.client, []string{s.processingKey()},
+		s.prefix, time.Now().UnixMilli()).Err()
+	if err != nil {
+		return fmt.Errorf("failed to release expired task locks: %w", err)
+	}
+	return nil
+}
+
+// getTask loads a task hash by ID.
+func (s *QueueStorage) getTask(ctx context.Context, taskID uuid.UUID) (*queue.Task, error) {
+	fields, err := s.client.HGetAll(ctx, s.taskKey(taskID)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get task %s: %w", taskID, err)
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: %s", queue.ErrTaskNotFound, taskID)
+	}
+	return decodeTask(fields)
+}
+
+// inspectBatchSize is the number of task IDs loaded per round trip when scanning the tasks index.
+const inspectBatchSize = 200
+
+// scanTasks calls fn for every task matching the filter, newest first, until fn returns false.
+// IDs of expired task hashes are removed from the tasks index along the way.
+func (s *QueueStorage) scanTasks(ctx context.Context, filter queue.TaskFilter, fn func(*queue.Task) bool) error {
+	for start := int64(0); ; start += inspectBatchSize {
+		ids, err := s.client.ZRevRange(ctx, s.tasksKey(), start, start+inspectBatchSize-1).Result()
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		cmds, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, id := range ids {
+				pipe.HGetAll(ctx, s.prefix+"task:"+id)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		var stale []any
+		for i, cmd := range cmds {
+			fields, err := cmd.(*redis.MapStringStringCmd).Result()
+			if err != nil {
+				return err
+			}
+			if len(fields) == 0 {
+				stale = append(stale, ids[i])
+				continue
+			}
+			task, err := decodeTask(fields)
+			if err != nil {
+				continue
+			}
+			if filter.Queue != "" && task.Queue != filter.Queue {
+				continue
+			}
+			if filter.Status != "" && task.Status != filter.Status {
+				continue
+			}
+			if !fn(task) {
+				return s.removeStale(ctx, stale)
+			}
+		}
+
+		if err := s.removeStale(ctx, stale); err != nil {
+			return err
+		}
+		// Removed IDs shift the following ranks down
+		start -= int64(len(stale))
+	}
+}
+
+// removeStale drops IDs of expired task hashes from the tasks index.
+func (s *QueueStorage) removeStale(ctx context.Context, ids []any) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return s.client.ZRem(ctx, s.tasksKey(), ids...).Err()
+}
+
+// findDLQEntry returns a DLQ entry by ID together with its raw list value.
+func (s *QueueStorage) findDLQEntry(ctx context.Context, dlqID uuid.UUID) (*queue.TasksDlq, string, error) {
+	raw, err := s.client.LRange(ctx, s.dlqKey(), 0, -1).Result()
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read DLQ: %w", err)
+	}
+
+	for _, data := range raw {
+		var entry queue.TasksDlq
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			continue
+		}
+		if entry.ID == dlqID {
+			return &entry, data, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("%w: %s", queue.ErrDLQEntryNotFound, dlqID)
+}
+
+// targetSet returns the set a new task is indexed in with its score.
+// Due tasks go straight to the pending set; delayed ones wait in the scheduled set.
+func (s *QueueStorage) targetSet(task *queue.Task) (string, float64) {
+	if !task.ScheduledAt.After(time.Now()) {
+		return s.pendingKey(task.Queue), pendingScore(task.Priority, task.ScheduledAt)
+	}
+	return s.scheduledKey(task.Queue), float64(task.ScheduledAt.UnixMilli())
+}
+
+// Key helpers
+
+func (s *QueueStorage) taskKey(id uuid.UUID) string {
+	return s.prefix + "task:" + id.String()
+}
+
+func (s *QueueStorage) pendingKey(queue string) string {
+	return s.prefix + "pending:" + queue
+}
+
+func (s *QueueStorage) scheduledKey(queue string) string {
+	return s.prefix + "scheduled:" + queue
+}
+
+func (s *QueueStorage) processingKey() string {
+	return s.prefix + "processing"
+}
+
+func (s *QueueStorage) nameKey(name string) string {
+	return s.prefix + "name:" + name
+}
+
+func (s *QueueStorage) dlqKey() string {
+	return s.prefix + "dlq"
+}
+
+func (s *QueueStorage) tasksKey() string {
+	return s.prefix + "tasks"
+}
+
+func (s *QueueStorage) pausedKey() string {
+	return s.prefix + "paused"
+}
+
+func (s *QueueStorage) uniqueKey(key string) string {
+	return s.prefix + "unique:" + key
+}
+
+func (s *QueueStorage) batchKey(id uuid.UUID) string {
+	return s.prefix + "batch:" + id.String()
+}
+
+// pendingScore must stay in sync with the score computed inside the Lua scripts.
+func pendingScore(priority queue.Priority, scheduledAt time.Time) float64 {
+	return float64(queue.PriorityMax-priority)*1e13 + float64(scheduledAt.UnixMilli())
+}
+
+// encodeTask flattens a task into hash field/value pairs; nil fields are omitted.
+func encodeTask(task *queue.Task) []any {
+	fields := []any{
+		"id", task.ID.String(),
+		"queue", task.Queue,
+		"task_type", string(task.TaskType),
+		"task_name", task.TaskName,
+		"payload", task.Payload,
+		"status", string(task.Status),
+		"priority", int(task.Priority),
+		"retry_count", int(task.RetryCount),
+		"max_retries", int(task.MaxRetries),
+		"scheduled_at", task.ScheduledAt.UnixMilli(),
+		"created_at", task.CreatedAt.UnixMilli(),
+	}
+	if task.LockedUntil != nil {
+		fields = append(fields, "locked_until", task.LockedUntil.UnixMilli())
+	}
+	if task.LockedBy != nil {
+		fields = append(fields, "locked_by", task.LockedBy.String())
+	}
+	if task.ProcessedAt != nil {
+		fields = append(fields, "processed_at", task.ProcessedAt.UnixMilli())
+	}
+	if task.Error != nil {
+		fields = append(fields, "error", *task.Error)
+	}
+	if task.Backoff != nil {
+		if data, err := json.Marshal(task.Backoff); err == nil {
+			fields = append(fields, "backoff", data)
+		}
+	}
+	if task.UniqueKey != nil {
+		fields = append(fields, "unique_key", *task.UniqueKey)
+	}
+	if task.UniqueUntil != nil {
+		fields = append(fields, "unique_until", task.UniqueUntil.UnixMilli())
+	}
+	if task.BatchID != nil {
+		fields = append(fields, "batch_id", task.BatchID.String())
+	}
+	if len(task.Next) > 0 {
+		if data, err := json.Marshal(task.Next); err == nil {
+			fields = append(fields, "next", data)
+		}
+	}
+	if len(task.Result) > 0 {
+		fields = append(fields, "result", task.Result)
+	}
+	return fields
+}
+
+// decodeTask builds a task from hash fields written by encodeTask and the Lua scripts.
+func decodeTask(fields map[string]string) (*queue.Task, error) {
+	id, err := uuid.Parse(fields["id"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid task id %q: %w", fields["id"], err)
+	}
+
+	task := &queue.Task{
+		ID:          id,
+		Queue:       fields["queue"],
+		TaskType:    queue.TaskType(fields["task_type"]),
+		TaskName:    fields["task_name"],
+		Status:      queue.TaskStatus(fields["status"]),
+		Priority:    queue.Priority(atoi(fields["priority"])),
+		RetryCount:  int8(atoi(fields["retry_count"])),
+		MaxRetries:  int8(atoi(fields["max_retries"])),
+		ScheduledAt: time.UnixMilli(atoi64(fields["scheduled_at"])),
+		CreatedAt:   time.UnixMilli(atoi64(fields["created_at"])),
+	}
+
+	if payload, ok := fields["payload"]; ok && payload != "" {
+		task.Payload = []byte(payload)
+	}
+	if result, ok := fields["result"]; ok && result != "" {
+		task.Result = []byte(result)
+	}
+	if v, ok := fields["locked_until"]; ok {
+		t := time.UnixMilli(atoi64(v))
+		task.LockedUntil = &t
+	}
+	if v, ok := fields["locked_by"]; ok {
+		if workerID, err := uuid.Parse(v); err == nil {
+			task.LockedBy = &workerID
+		}
+	}
+	if v, ok := fields["processed_at"]; ok {
+		t := time.UnixMilli(atoi64(v))
+		task.ProcessedAt = &t
+	}
+	if v, ok := fields["error"]; ok {
+		task.Error = &v
+	}
+	if v, ok := fields["unique_key"]; ok {
+		task.UniqueKey = &v
+	}
+	if v, ok := fields["unique_until"]; ok {
+		t := time.UnixMilli(atoi64(v))
+		task.UniqueUntil = &t
+	}
+	if v, ok := fields["backoff"]; ok {
+		var policy queue.BackoffPolicy
+		if err := json.Unmarshal([]byte(v), &policy); err != nil {
+			return nil, fmt.Errorf("invalid backoff policy of task %s: %w", id, err)
+		}
+		task.Backoff = &policy
+	}
+	if v, ok := fields["batch_id"]; ok {
+		if batchID, err := uuid.Parse(v); err == nil {
+			task.BatchID = &batchID
+		}
+	}
+	if v, ok := fields["next"]; ok {
+		if err := json.Unmarshal([]byte(v), &task.Next); err != nil {
+			return nil, fmt.Errorf("invalid chain steps of task %s: %w", id, err)
+		}
+	}
+
+	return task, nil
+}
+
+// encodeBatch flattens a batch into hash field/value pairs; callbacks are stored as JSON.
+func encodeBatch(batch *queue.Batch) ([]any, error) {
+	fields := []any{
+		"id", batch.ID.String(),
+		"total", batch.Total,
+		"succeeded", batch.Succeeded,
+		"failed", batch.Failed,
+		"created_at", batch.CreatedAt.UnixMilli(),
+	}
+	if batch.FinishedAt != nil {
+		fields = append(fields, "finished_at", batch.FinishedAt.UnixMilli())
+	}
+	if batch.OnComplete != nil {
+		data, err := json.Marshal(batch.OnComplete)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal on complete callback of batch %s: %w", batch.ID, err)
+		}
+		fields = append(fields, "on_complete", data)
+	}
+	if batch.OnFailure != nil {
+		data, err := json.Marshal(batch.OnFailure)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal on failure callback of batch %s: %w", batch.ID, err)
+		}
+		fields = append(fields, "on_failure", data)
+	}
+	return fields, nil
+}
+
+// decodeBatch builds a batch from hash fields written by encodeBatch and the Lua scripts.
+func decodeBatch(fields map[string]string) (*queue.Batch, error) {
+	id, err := uuid.Parse(fields["id"])
+	if err != nil {
+		return nil, fmt.Errorf("invalid batch id %q: %w", fields["id"], err)
+	}
+
+	batch := &queue.Batch{
+		ID:        id,
+		Total:     atoi(fields["total"]),
+		Succeeded: atoi(fields["succeeded"]),
+		Failed:    atoi(fields["failed"]),
+		CreatedAt: time.UnixMilli(atoi64(fields["created_at"])),
+	}
+
+	if v, ok := fields["finished_at"]; ok {
+		t := time.UnixMilli(atoi64(v))
+		batch.FinishedAt = &t
+	}
+	if v, ok := fields["on_complete"]; ok {
+		if err := json.Unmarshal([]byte(v), &batch.OnComplete); err != nil {
+			return nil, fmt.Errorf("invalid on complete callback of batch %s: %w", id, err)
+		}
+	}
+	if v, ok := fields["on_failure"]; ok {
+		if err := json.Unmarshal([]byte(v), &batch.OnFailure); err != nil {
+			return nil, fmt.Errorf("invalid on failure callback of batch %s: %w", id, err)
+		}
+	}
+
+	return batch, nil
+}
+
+// pairsToMap converts a flat HGETALL reply into a map.
+func pairsToMap(pairs []string) map[string]string {
+	m := make(map[string]string, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		m[pairs[i]] = pairs[i+1]
+	}
+	return m
+}
+
+// atoi64 parses integers written by Lua, which may use a float representation.
+func atoi64(s string) int64 {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n
+	}
+	f, _ := strconv.ParseFloat(s, 64)
+	return int64(f)
+}
+
+func atoi(s string) int {
+	return int(atoi64(s))
+}
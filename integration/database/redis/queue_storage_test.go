@@ -0,0 +1,531 @@
+package redis_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dmitrymomot/foundation/core/queue"
+	"github.com/dmitrymomot/foundation/integration/database/redis"
+)
+
+// newTestStorage returns a queue storage on the Redis server of TEST_REDIS_URL,
+// using a key prefix unique to the test. The test is skipped when the variable is unset.
+func newTestStorage(t *testing.T) (*redis.QueueStorage, goredis.UniversalClient) {
+	t.Helper()
+
+	url := os.Getenv("TEST_REDIS_URL")
+	if url == "" {
+		t.Skip("TEST_REDIS_URL is not set")
+	}
+
+	opts, err := goredis.ParseURL(url)
+	require.NoError(t, err)
+	client := goredis.NewClient(opts)
+	t.Cleanup(func() { _ = client.Close() })
+
+	prefix := "{test-" + uuid.NewString() + "}:"
+	t.Cleanup(func() {
+		ctx := context.Background()
+		keys, err := client.Keys(ctx, prefix+"*").Result()
+		if err == nil && len(keys) > 0 {
+			_ = client.Del(ctx, keys...).Err()
+		}
+	})
+
+	return redis.NewQueueStorage(client, redis.WithQueueKeyPrefix(prefix)), client
+}
+
+func newTestTask(queueName string) *queue.Task {
+	now := time.Now()
+	return &queue.Task{
+		ID:          uuid.New(),
+		Queue:       queueName,
+		TaskType:    queue.TaskTypeOneTime,
+		TaskName:    "test_task",
+		Payload:     []byte(`{"n":1}`),
+		Status:      queue.TaskStatusPending,
+		Priority:    queue.PriorityDefault,
+		MaxRetries:  3,
+		ScheduledAt: now,
+		CreatedAt:   now,
+	}
+}
+
+func TestQueueStorage_FinishBatchTask(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name      string
+		succeeded bool
+		callback  string
+	}{
+		{name: "creates on complete callback", succeeded: true, callback: "on_complete"},
+		{name: "creates on failure callback", succeeded: false, callback: "on_failure"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage, _ := newTestStorage(t)
+
+			onComplete := newTestTask("callbacks")
+			onComplete.TaskName = "on_complete"
+			onFailure := newTestTask("callbacks")
+			onFailure.TaskName = "on_failure"
+
+			batch := &queue.Batch{
+				ID:         uuid.New(),
+				Total:      2,
+				OnComplete: onComplete,
+				OnFailure:  onFailure,
+				CreatedAt:  time.Now(),
+			}
+			tasks := []*queue.Task{newTestTask("batch"), newTestTask("batch")}
+			for _, task := range tasks {
+				task.BatchID = &batch.ID
+			}
+			require.NoError(t, storage.CreateBatch(ctx, batch, tasks))
+
+			current, err := storage.FinishBatchTask(ctx, batch.ID, true)
+			require.NoError(t, err)
+			assert.False(t, current.Finished())
+
+			current, err = storage.FinishBatchTask(ctx, batch.ID, tt.succeeded)
+			require.NoError(t, err)
+			assert.True(t, current.Finished())
+			assert.Zero(t, current.Pending())
+
+			_, err = storage.FinishBatchTask(ctx, batch.ID, true)
+			assert.Error(t, err)
+
+			callback, err := storage.GetPendingTaskByName(ctx, tt.callback)
+			require.NoError(t, err)
+			require.NotNil(t, callback)
+			assert.Equal(t, queue.TaskStatusPending, callback.Status)
+
+			claimed, err := storage.ClaimTask(ctx, uuid.New(), []string{"callbacks"}, time.Minute)
+			require.NoError(t, err)
+			require.NotNil(t, claimed)
+			assert.Equal(t, callback.ID, claimed.ID)
+
+			_, err = storage.ClaimTask(ctx, uuid.New(), []string{"callbacks"}, time.Minute)
+			assert.ErrorIs(t, err, queue.ErrNoTaskToClaim, "only one callback must be created")
+		})
+	}
+
+	t.Run("unknown batch", func(t *testing.T) {
+		t.Parallel()
+
+		storage, _ := newTestStorage(t)
+
+		_, err := storage.FinishBatchTask(ctx, uuid.New(), true)
+		assert.ErrorIs(t, err, queue.ErrBatchNotFound)
+	})
+}
+
+func TestQueueStorage_ClaimTask(t *testing.T) {
+	t.Parallel()
+
+	storage, _ := newTestStorage(t)
+	ctx := context.Background()
+	queueName := "claim-" + uuid.NewString()
+	workerID := uuid.New()
+
+	low := newTestTask(queueName)
+	low.Priority = queue.PriorityLow
+	high := newTestTask(queueName)
+	high.Priority = queue.PriorityHigh
+	delayed := newTestTask(queueName)
+	delayed.Priority = queue.PriorityMax
+	delayed.ScheduledAt = time.Now().Add(time.Hour)
+	for _, task := range []*queue.Task{low, high, delayed} {
+		require.NoError(t, storage.CreateTask(ctx, task))
+	}
+
+	require.NoError(t, storage.PauseQueue(ctx, queueName))
+	_, err := storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+	require.NoError(t, storage.ResumeQueue(ctx, queueName))
+
+	claimed, err := storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, high.ID, claimed.ID)
+	assert.Equal(t, queue.TaskStatusProcessing, claimed.Status)
+	require.NotNil(t, claimed.LockedBy)
+	assert.Equal(t, workerID, *claimed.LockedBy)
+
+	claimed, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, low.ID, claimed.ID)
+
+	_, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+
+	require.NoError(t, storage.CompleteTask(ctx, high.ID))
+	completed, err := storage.GetTask(ctx, high.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.TaskStatusCompleted, completed.Status)
+	assert.Nil(t, completed.LockedBy)
+}
+
+func TestQueueStorage_ExpiredLocks(t *testing.T) {
+	t.Parallel()
+
+	storage, _ := newTestStorage(t)
+	ctx := context.Background()
+	queueName := "locks-" + uuid.NewString()
+	crashed := uuid.New()
+
+	extended := newTestTask(queueName)
+	extended.Priority = queue.PriorityHigh
+	abandoned := newTestTask(queueName)
+	for _, task := range []*queue.Task{extended, abandoned} {
+		require.NoError(t, storage.CreateTask(ctx, task))
+	}
+
+	claimed, err := storage.ClaimTask(ctx, crashed, []string{queueName}, time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, extended.ID, claimed.ID)
+	require.NoError(t, storage.ExtendLock(ctx, extended.ID, time.Minute))
+
+	claimed, err = storage.ClaimTask(ctx, crashed, []string{queueName}, time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, abandoned.ID, claimed.ID)
+
+	time.Sleep(50 * time.Millisecond)
+
+	// The expired lock is released by the next claim, the extended one is kept
+	workerID := uuid.New()
+	claimed, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, abandoned.ID, claimed.ID)
+	require.NotNil(t, claimed.LockedBy)
+	assert.Equal(t, workerID, *claimed.LockedBy)
+	assert.Zero(t, claimed.RetryCount)
+
+	_, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	assert.ErrorIs(t, err, queue.ErrNoTaskToClaim)
+}
+
+func TestQueueStorage_FailTask(t *testing.T) {
+	t.Parallel()
+
+	storage, _ := newTestStorage(t)
+	ctx := context.Background()
+	queueName := "fail-" + uuid.NewString()
+	workerID := uuid.New()
+
+	task := newTestTask(queueName)
+	require.NoError(t, storage.CreateTask(ctx, task))
+
+	claimed, err := storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+
+	retryAt := time.Now().Add(-time.Second)
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "temporary", &retryAt))
+
+	retried, err := storage.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.TaskStatusPending, retried.Status)
+	assert.EqualValues(t, 1, retried.RetryCount)
+	require.NotNil(t, retried.Error)
+	assert.Equal(t, "temporary", *retried.Error)
+
+	claimed, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, claimed.ID)
+
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "permanent", nil))
+
+	failed, err := storage.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.TaskStatusFailed, failed.Status)
+	assert.EqualValues(t, 2, failed.RetryCount)
+
+	assert.Error(t, storage.FailTask(ctx, task.ID, "not processing", nil))
+}
+
+func TestQueueStorage_CompleteTaskWithResult(t *testing.T) {
+	t.Parallel()
+
+	storage, _ := newTestStorage(t)
+	ctx := context.Background()
+	queueName := "result-" + uuid.NewString()
+
+	task := newTestTask(queueName)
+	require.NoError(t, storage.CreateTask(ctx, task))
+	claimed, err := storage.ClaimTask(ctx, uuid.New(), []string{queueName}, time.Minute)
+	require.NoError(t, err)
+
+	result := []byte(`{"report_id":42}`)
+	require.NoError(t, storage.CompleteTaskWithResult(ctx, claimed.ID, result))
+
+	completed, err := storage.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, queue.TaskStatusCompleted, completed.Status)
+	assert.JSONEq(t, string(result), string(completed.Result))
+}
+
+func TestQueueStorage_DLQ(t *testing.T) {
+	t.Parallel()
+
+	storage, _ := newTestStorage(t)
+	ctx := context.Background()
+	queueName := "dlq-" + uuid.NewString()
+	workerID := uuid.New()
+
+	backoff := queue.FixedBackoff(time.Second)
+	task := newTestTask(queueName)
+	task.Backoff = &backoff
+	task.Next = []queue.WorkflowStep{{TaskName: "next_step", Queue: queueName, Priority: queue.PriorityDefault}}
+	require.NoError(t, storage.CreateTask(ctx, task))
+
+	claimed, err := storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "boom", nil))
+	require.NoError(t, storage.MoveToDLQ(ctx, claimed.ID))
+
+	_, err = storage.GetTask(ctx, task.ID)
+	assert.ErrorIs(t, err, queue.ErrTaskNotFound)
+
+	entries, err := storage.ListDLQ(ctx, queue.DLQFilter{Queue: queueName})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	entry := entries[0]
+	assert.Equal(t, task.ID, entry.TaskID)
+	assert.Equal(t, "boom", entry.Error)
+	assert.EqualValues(t, 1, entry.RetryCount)
+	assert.Equal(t, task.Payload, entry.Payload)
+	assert.Equal(t, task.Backoff, entry.Backoff)
+	assert.Equal(t, task.Next, entry.Next)
+
+	requeued, err := storage.RequeueDLQ(ctx, entry.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, requeued.ID)
+	assert.Equal(t, queue.TaskStatusPending, requeued.Status)
+	assert.Zero(t, requeued.RetryCount)
+	assert.Equal(t, task.Backoff, requeued.Backoff)
+	assert.Equal(t, task.Next, requeued.Next)
+
+	_, err = storage.RequeueDLQ(ctx, entry.ID)
+	assert.ErrorIs(t, err, queue.ErrDLQEntryNotFound)
+
+	claimed, err = storage.ClaimTask(ctx, workerID, []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, task.ID, claimed.ID)
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "boom again", nil))
+	require.NoError(t, storage.MoveToDLQ(ctx, claimed.ID))
+
+	purged, err := storage.PurgeDLQ(ctx, queueName)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, purged)
+
+	entries, err = storage.ListDLQ(ctx, queue.DLQFilter{Queue: queueName})
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestQueueStorage_RequeueDLQ_Batch(t *testing.T) {
+	t.Parallel()
+
+	storage, _ := newTestStorage(t)
+	ctx := context.Background()
+	queueName := "dlq-batch-" + uuid.NewString()
+
+	batch := &queue.Batch{ID: uuid.New(), Total: 2, CreatedAt: time.Now()}
+	tasks := []*queue.Task{newTestTask(queueName), newTestTask(queueName)}
+	for _, task := range tasks {
+		task.BatchID = &batch.ID
+	}
+	require.NoError(t, storage.CreateBatch(ctx, batch, tasks))
+
+	claimed, err := storage.ClaimTask(ctx, uuid.New(), []string{queueName}, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, storage.FailTask(ctx, claimed.ID, "boom", nil))
+	require.NoError(t, storage.MoveToDLQ(ctx, claimed.ID))
+	_, err = storage.FinishBatchTask(ctx, batch.ID, false)
+	require.NoError(t, err)
+
+	entries, err := storage.ListDLQ(ctx, queue.DLQFilter{Queue: queueName})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, &batch.ID, entries[0].BatchID)
+
+	requeued, err := storage.RequeueDLQ(ctx, entries[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, &batch.ID, requeued.BatchID)
+
+	current, err := storage.GetBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.Zero(t, current.Failed)
+	assert.Equal(t, 2, current.Pending())
+
+	stored, err := storage.GetTask(ctx, requeued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &batch.ID, stored.BatchID)
+}
+
+func TestQueueStorage_CreateUniqueTask(t *testing.T) {
+	t.Parallel()
+
+	storage, _ := newTestStorage(t)
+	ctx := context.Background()
+	queueName := "unique-" + uuid.NewString()
+	key := "report:" + uuid.NewString()
+
+	uniqueTask := func() *queue.Task {
+		task := newTestTask(queueName)
+		task.UniqueKey = &key
+		return task
+	}
+
+	holder := uniqueTask()
+	id, err := storage.CreateUniqueTask(ctx, holder, queue.UniqueConflictSkip)
+	require.NoError(t, err)
+	assert.Equal(t, holder.ID, id)
+
+	t.Run("skip returns the holder", func(t *testing.T) {
+		id, err := storage.CreateUniqueTask(ctx, uniqueTask(), queue.UniqueConflictSkip)
+		require.NoError(t, err)
+		assert.Equal(t, holder.ID, id)
+	})
+
+	t.Run("error rejects the task", func(t *testing.T) {
+		_, err := storage.CreateUniqueTask(ctx, uniqueTask(), queue.UniqueConflictError)
+		assert.ErrorIs(t, err, queue.ErrDuplicateTask)
+	})
+
+	t.Run("replace cancels the pending holder", func(t *testing.T) {
+		replacement := uniqueTask()
+		id, err := storage.CreateUniqueTask(ctx, replacement, queue.UniqueConflictReplace)
+		require.NoError(t, err)
+		assert.Equal(t, replacement.ID, id)
+
+		cancelled, err := storage.GetTask(ctx, holder.ID)
+		require.NoError(t, err)
+		assert.Equal(t, queue.TaskStatusCancelled, cancelled.Status)
+
+		count, err := storage.CountTasks(ctx, queue.TaskFilter{Queue: queueName, Status: queue.TaskStatusPending})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, count)
+	})
+}
+
+func TestQueueStorage_UniqueKeyRelease(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	claim := func(t *testing.T, storage *redis.QueueStorage) *queue.Task {
+		t.Helper()
+		claimed, err := storage.ClaimTask(ctx, uuid.New(), []string{"unique"}, time.Minute)
+		require.NoError(t, err)
+		return claimed
+	}
+
+	for _, tt := range []struct {
+		name   string
+		finish func(t *testing.T, storage *redis.QueueStorage, task *queue.Task)
+	}{
+		{"completed", func(t *testing.T, storage *redis.QueueStorage, task *queue.Task) {
+			require.NoError(t, storage.CompleteTask(ctx, claim(t, storage).ID))
+		}},
+		{"failed", func(t *testing.T, storage *redis.QueueStorage, task *queue.Task) {
+			require.NoError(t, storage.FailTask(ctx, claim(t, storage).ID, "boom", nil))
+		}},
+		{"moved to the DLQ", func(t *testing.T, storage *redis.QueueStorage, task *queue.Task) {
+			claimed := claim(t, storage)
+			require.NoError(t, storage.FailTask(ctx, claimed.ID, "boom", nil))
+			require.NoError(t, storage.MoveToDLQ(ctx, claimed.ID))
+		}},
+		{"cancelled", func(t *testing.T, storage *redis.QueueStorage, task *queue.Task) {
+			require.NoError(t, storage.CancelTask(ctx, task.ID))
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			storage, client := newTestStorage(t)
+			key := "report:" + uuid.NewString()
+			task := newTestTask("unique")
+			task.UniqueKey = &key
+			_, err := storage.CreateUniqueTask(ctx, task, queue.UniqueConflictError)
+			require.NoError(t, err)
+
+			tt.finish(t, storage, task)
+
+			keys, err := client.Keys(ctx, "*unique:"+key).Result()
+			require.NoError(t, err)
+			assert.Empty(t, keys)
+
+			next := newTestTask("unique")
+			next.UniqueKey = &key
+			id, err := storage.CreateUniqueTask(ctx, next, queue.UniqueConflictError)
+			require.NoError(t, err)
+			assert.Equal(t, next.ID, id)
+		})
+	}
+
+	t.Run("key taken over by a newer task is kept", func(t *testing.T) {
+		t.Parallel()
+
+		storage, client := newTestStorage(t)
+		key := "report:" + uuid.NewString()
+		holder := newTestTask("unique")
+		holder.UniqueKey = &key
+		_, err := storage.CreateUniqueTask(ctx, holder, queue.UniqueConflictError)
+		require.NoError(t, err)
+		claimed := claim(t, storage)
+
+		replacement := newTestTask("unique")
+		replacement.UniqueKey = &key
+		_, err = storage.CreateUniqueTask(ctx, replacement, queue.UniqueConflictReplace)
+		require.NoError(t, err)
+
+		require.NoError(t, storage.CompleteTask(ctx, claimed.ID))
+
+		keys, err := client.Keys(ctx, "*unique:"+key).Result()
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		value, err := client.Get(ctx, keys[0]).Result()
+		require.NoError(t, err)
+		assert.Equal(t, replacement.ID.String(), value)
+	})
+}
+
+func TestSchedulerLock(t *testing.T) {
+	t.Parallel()
+
+	_, client := newTestStorage(t)
+	ctx := context.Background()
+	name := "test-" + uuid.NewString()
+
+	leader := redis.NewSchedulerLock(client, name)
+	follower := redis.NewSchedulerLock(client, name)
+
+	acquired, err := leader.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = leader.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired, "leader keeps its lock")
+
+	acquired, err = follower.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	require.NoError(t, leader.Release(ctx))
+
+	acquired, err = follower.TryAcquire(ctx)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	require.NoError(t, follower.Release(ctx))
+}